@@ -0,0 +1,27 @@
+package encryptedcol
+
+// SealWithAAD encrypts plaintext using the default key, binding it to
+// associated data such as a tenant ID or row primary key.
+//
+// The AAD is authenticated but not stored in the ciphertext; the same AAD
+// must be supplied to OpenWithAAD. This prevents an attacker with database
+// write access from copying a ciphertext into a different row.
+//
+// An empty aad is equivalent to Seal.
+// Returns nil if plaintext is nil (NULL preservation).
+func (c *Cipher) SealWithAAD(plaintext, aad []byte) []byte {
+	r := c.mustAcquireWritable()
+	defer r.release()
+	if plaintext == nil {
+		return nil // NULL preservation
+	}
+	return c.sealWithKeyID(r, nil, r.defaultID, plaintext, aad)
+}
+
+// OpenWithAAD decrypts ciphertext produced by SealWithAAD.
+// Returns ErrDecryptionFailed if aad does not match the value used to seal,
+// including when the ciphertext was sealed without AAD and aad is non-empty.
+// Returns nil, nil if ciphertext is nil (NULL preservation).
+func (c *Cipher) OpenWithAAD(ciphertext, aad []byte) ([]byte, error) {
+	return c.openWithAAD(ciphertext, aad)
+}
@@ -0,0 +1,109 @@
+package encryptedcol
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSealWithAAD_RoundTrip(t *testing.T) {
+	tests := []struct {
+		name      string
+		aead      AEAD
+		plaintext []byte
+	}{
+		{"secretbox", AEADSecretbox, []byte("tenant notes")},
+		{"aes-gcm", AEADAESGCM, []byte("tenant notes")},
+		{"secretbox compressed", AEADSecretbox, []byte(strings.Repeat("notes ", 500))},
+		{"aes-gcm compressed", AEADAESGCM, []byte(strings.Repeat("notes ", 500))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cipher, _ := New(WithKey("v1", testKey("v1")), WithAEAD(tt.aead))
+
+			ciphertext := cipher.SealWithAAD(tt.plaintext, []byte("tenant-a"))
+			require.True(t, hasAAD(ciphertext[0]))
+
+			decrypted, err := cipher.OpenWithAAD(ciphertext, []byte("tenant-a"))
+			require.NoError(t, err)
+			require.True(t, bytes.Equal(tt.plaintext, decrypted))
+		})
+	}
+}
+
+func TestOpenWithAAD_Mismatch(t *testing.T) {
+	for _, aead := range []AEAD{AEADSecretbox, AEADAESGCM} {
+		cipher, _ := New(WithKey("v1", testKey("v1")), WithAEAD(aead))
+
+		ciphertext := cipher.SealWithAAD([]byte("secret"), []byte("tenant-a"))
+
+		_, err := cipher.OpenWithAAD(ciphertext, []byte("tenant-b"))
+		require.ErrorIs(t, err, ErrDecryptionFailed)
+
+		// Open without AAD must also fail
+		_, err = cipher.Open(ciphertext)
+		require.ErrorIs(t, err, ErrDecryptionFailed)
+
+		// Clearing the AAD flag must not bypass the binding
+		stripped := bytes.Clone(ciphertext)
+		stripped[0] &^= flagAAD
+		_, err = cipher.Open(stripped)
+		require.ErrorIs(t, err, ErrDecryptionFailed)
+	}
+}
+
+func TestOpenWithAAD_UnboundCiphertext(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	// A ciphertext sealed without AAD must not be accepted where AAD is expected
+	ciphertext := cipher.Seal([]byte("secret"))
+	_, err := cipher.OpenWithAAD(ciphertext, []byte("tenant-a"))
+	require.ErrorIs(t, err, ErrDecryptionFailed)
+}
+
+func TestSealWithAAD_NotStored(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	aad := []byte("distinctive-tenant-identifier")
+	ciphertext := cipher.SealWithAAD([]byte("x"), aad)
+
+	require.False(t, bytes.Contains(ciphertext, aad))
+	require.Equal(t, len(cipher.Seal([]byte("x"))), len(ciphertext))
+}
+
+func TestSealWithAAD_EmptyIsSeal(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	ciphertext := cipher.SealWithAAD([]byte("secret"), nil)
+	require.False(t, hasAAD(ciphertext[0]))
+
+	decrypted, err := cipher.Open(ciphertext)
+	require.NoError(t, err)
+	require.Equal(t, []byte("secret"), decrypted)
+}
+
+func TestSealWithAAD_Null(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	require.Nil(t, cipher.SealWithAAD(nil, []byte("tenant-a")))
+
+	result, err := cipher.OpenWithAAD(nil, []byte("tenant-a"))
+	require.NoError(t, err)
+	require.Nil(t, result)
+}
+
+func TestSealWithAAD_Closed(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+	ciphertext := cipher.SealWithAAD([]byte("secret"), []byte("a"))
+	cipher.Close()
+
+	require.Panics(t, func() {
+		cipher.SealWithAAD([]byte("secret"), []byte("a"))
+	})
+
+	_, err := cipher.OpenWithAAD(ciphertext, []byte("a"))
+	require.ErrorIs(t, err, ErrCipherClosed)
+}
@@ -0,0 +1,174 @@
+package encryptedcol
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithAEAD_RoundTrip(t *testing.T) {
+	tests := []struct {
+		name      string
+		aead      AEAD
+		nonceSize int
+	}{
+		{"secretbox", AEADSecretbox, secretboxNonceSize},
+		{"aes-gcm", AEADAESGCM, aesGCMNonceSize},
+		{"aes-gcm-siv", AEADGCMSIV, gcmSIVNonceSize},
+		{"chacha20-poly1305", AEADChaCha20Poly1305, chachaNonceSize},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cipher, err := New(WithKey("v1", testKey("v1")), WithAEAD(tt.aead))
+			require.NoError(t, err)
+
+			plaintext := []byte("hello world")
+			ciphertext := cipher.Seal(plaintext)
+
+			flag, _, nonce, _, err := parseFormat(ciphertext)
+			require.NoError(t, err)
+			require.Equal(t, tt.aead, aeadFromFlag(flag))
+			require.Len(t, nonce, tt.nonceSize)
+
+			decrypted, err := cipher.Open(ciphertext)
+			require.NoError(t, err)
+			require.True(t, bytes.Equal(plaintext, decrypted))
+		})
+	}
+}
+
+func TestWithAEAD_AESGCMCompressed(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")), WithAEAD(AEADAESGCM))
+
+	plaintext := []byte(strings.Repeat("compressible data ", 200))
+	ciphertext := cipher.Seal(plaintext)
+
+	require.Equal(t, flagFor(AEADAESGCM, flagZstd), ciphertext[0])
+
+	decrypted, err := cipher.Open(ciphertext)
+	require.NoError(t, err)
+	require.True(t, bytes.Equal(plaintext, decrypted))
+}
+
+func TestWithAEAD_MixedRecords(t *testing.T) {
+	// Records sealed with either AEAD must open regardless of the configured AEAD
+	sb, _ := New(WithKey("v1", testKey("v1")))
+	gcm, _ := New(WithKey("v1", testKey("v1")), WithAEAD(AEADAESGCM))
+
+	sbCiphertext := sb.SealString("from secretbox")
+	gcmCiphertext := gcm.SealString("from aes-gcm")
+
+	for _, c := range []*Cipher{sb, gcm} {
+		s, err := c.OpenString(sbCiphertext)
+		require.NoError(t, err)
+		require.Equal(t, "from secretbox", s)
+
+		s, err = c.OpenString(gcmCiphertext)
+		require.NoError(t, err)
+		require.Equal(t, "from aes-gcm", s)
+	}
+}
+
+func TestWithAEAD_GCMSIVMixedRecords(t *testing.T) {
+	siv, _ := New(WithKey("v1", testKey("v1")), WithAEAD(AEADGCMSIV))
+	sb, _ := New(WithKey("v1", testKey("v1")))
+
+	compressible := []byte(strings.Repeat("compressible data ", 200))
+	sivCiphertext := siv.Seal(compressible)
+	require.Equal(t, flagFor(AEADGCMSIV, flagZstd), sivCiphertext[0])
+
+	// Existing records and GCM-SIV records open under either configuration
+	for _, c := range []*Cipher{sb, siv} {
+		decrypted, err := c.Open(sivCiphertext)
+		require.NoError(t, err)
+		require.True(t, bytes.Equal(compressible, decrypted))
+
+		s, err := c.OpenString(sb.SealString("from secretbox"))
+		require.NoError(t, err)
+		require.Equal(t, "from secretbox", s)
+	}
+
+	// AAD binding works as with the other AEADs
+	ct := siv.SealWithAAD([]byte("secret"), []byte("row-1"))
+	_, err := siv.OpenWithAAD(ct, []byte("row-2"))
+	require.ErrorIs(t, err, ErrDecryptionFailed)
+
+	// Relabeling a GCM-SIV record as AES-GCM must not decrypt
+	relabeled := bytes.Clone(siv.Seal([]byte("secret")))
+	relabeled[0] = flagFor(AEADAESGCM, compressionFromFlag(relabeled[0]))
+	_, err = siv.Open(relabeled)
+	require.ErrorIs(t, err, ErrDecryptionFailed)
+}
+
+func TestWithAEAD_ChaCha20Poly1305(t *testing.T) {
+	chacha, _ := New(WithKey("v1", testKey("v1")), WithAEAD(AEADChaCha20Poly1305))
+	sb, _ := New(WithKey("v1", testKey("v1")))
+
+	// 12 bytes shorter than secretbox for the same value
+	require.Equal(t, len(sb.SealString("abcd"))-12, len(chacha.SealString("abcd")))
+
+	// Existing records and ChaCha20-Poly1305 records open under either configuration
+	chachaCiphertext := chacha.SealString("from chacha")
+	for _, c := range []*Cipher{sb, chacha} {
+		s, err := c.OpenString(chachaCiphertext)
+		require.NoError(t, err)
+		require.Equal(t, "from chacha", s)
+	}
+
+	ct := chacha.SealWithAAD([]byte("secret"), []byte("row-1"))
+	_, err := chacha.OpenWithAAD(ct, []byte("row-2"))
+	require.ErrorIs(t, err, ErrDecryptionFailed)
+
+	// Same nonce size as AES-GCM, but relabeling must not decrypt either way
+	for _, aead := range []AEAD{AEADAESGCM, AEADGCMSIV} {
+		relabeled := bytes.Clone(chacha.Seal([]byte("secret")))
+		relabeled[0] = flagFor(aead, compressionFromFlag(relabeled[0]))
+		_, err = chacha.Open(relabeled)
+		require.ErrorIs(t, err, ErrDecryptionFailed)
+	}
+}
+
+func TestWithAEAD_BlindIndexUnchanged(t *testing.T) {
+	sb, _ := New(WithKey("v1", testKey("v1")))
+	gcm, _ := New(WithKey("v1", testKey("v1")), WithAEAD(AEADAESGCM))
+
+	require.Equal(t, sb.BlindIndexString("alice"), gcm.BlindIndexString("alice"))
+}
+
+func TestWithAEAD_Unsupported(t *testing.T) {
+	_, err := New(WithKey("v1", testKey("v1")), WithAEAD(AEAD(0x0F)))
+	require.ErrorIs(t, err, ErrUnsupportedAEAD)
+}
+
+func TestWithAEAD_AESGCMTampered(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")), WithAEAD(AEADAESGCM))
+
+	ciphertext := cipher.Seal([]byte("secret"))
+	ciphertext[len(ciphertext)-1] ^= 0xFF
+
+	_, err := cipher.Open(ciphertext)
+	require.ErrorIs(t, err, ErrDecryptionFailed)
+}
+
+func TestWithAEAD_FlagSwapFails(t *testing.T) {
+	// Relabeling a secretbox record as AES-GCM must not decrypt
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	ciphertext := cipher.Seal([]byte("secret"))
+	ciphertext[0] = flagFor(AEADAESGCM, compressionFromFlag(ciphertext[0]))
+
+	_, err := cipher.Open(ciphertext)
+	require.ErrorIs(t, err, ErrDecryptionFailed)
+}
+
+func TestParseFormat_UnknownAEAD(t *testing.T) {
+	data := make([]byte, 64)
+	data[0] = 0xF0
+	data[1] = 0x02
+
+	_, _, _, _, err := parseFormat(data)
+	require.ErrorIs(t, err, ErrInvalidFormat)
+}
@@ -0,0 +1,78 @@
+package encryptedcol
+
+// SealBatch encrypts multiple plaintexts with the default key.
+// The result is parallel to plaintexts; nil elements stay nil (NULL preservation).
+//
+// SealBatch amortizes per-call overhead for bulk imports: nonces for the whole
+// batch are read from crypto/rand in a single call and the inner plaintext
+// scratch buffer is reused across items.
+func (c *Cipher) SealBatch(plaintexts [][]byte) [][]byte {
+	r := c.mustAcquireWritable()
+	defer r.release()
+
+	results := make([][]byte, len(plaintexts))
+
+	count := 0
+	for _, p := range plaintexts {
+		if p != nil {
+			count++
+		}
+	}
+	if count == 0 {
+		return results
+	}
+
+	keyID := r.defaultID
+	nonceLen := c.config.aead.nonceSize()
+	var nonces []byte
+	if c.config.derivedNonce == nil {
+		nonces = c.newNonces(count)
+	}
+
+	var scratch []byte
+	for i, p := range plaintexts {
+		if p == nil {
+			continue // NULL preservation
+		}
+		scratch = c.appendInner(scratch[:0], keyID, p)
+		var nonce []byte
+		if nonces != nil {
+			nonce = nonces[:nonceLen:nonceLen]
+			nonces = nonces[nonceLen:]
+		} else {
+			nonce = c.sealNonce(keyID, p)
+		}
+		results[i] = c.sealInner(r, nil, keyID, scratch, nonce, nil)
+	}
+	return results
+}
+
+// OpenBatch decrypts multiple ciphertexts, auto-detecting each key from its embedded key_id.
+// The results and errors are parallel to ciphertexts; a failure on one item
+// does not affect the others. nil elements yield nil, nil (NULL preservation).
+//
+// The key snapshot is acquired once for the whole batch; each returned
+// plaintext is an independent copy.
+func (c *Cipher) OpenBatch(ciphertexts [][]byte) ([][]byte, []error) {
+	results := make([][]byte, len(ciphertexts))
+	errs := make([]error, len(ciphertexts))
+
+	r, err := c.acquire()
+	if err != nil {
+		for i, ct := range ciphertexts {
+			if ct != nil {
+				errs[i] = err
+			}
+		}
+		return results, errs
+	}
+	defer r.release()
+
+	for i, ct := range ciphertexts {
+		if ct == nil {
+			continue // NULL preservation
+		}
+		results[i], errs[i] = c.openWithRing(r, nil, ct, nil)
+	}
+	return results, errs
+}
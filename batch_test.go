@@ -0,0 +1,120 @@
+package encryptedcol
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSealBatch_OpenBatch(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	plaintexts := [][]byte{
+		[]byte("first"),
+		nil,
+		{},
+		[]byte(strings.Repeat("compressible ", 500)),
+		[]byte("last"),
+	}
+
+	ciphertexts := cipher.SealBatch(plaintexts)
+	require.Len(t, ciphertexts, len(plaintexts))
+	require.Nil(t, ciphertexts[1])
+
+	results, errs := cipher.OpenBatch(ciphertexts)
+	require.Len(t, results, len(plaintexts))
+	require.Len(t, errs, len(plaintexts))
+
+	for i, p := range plaintexts {
+		require.NoError(t, errs[i])
+		if p == nil {
+			require.Nil(t, results[i])
+			continue
+		}
+		require.True(t, bytes.Equal(p, results[i]), "item %d", i)
+	}
+}
+
+func TestSealBatch_UniqueNonces(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	plaintexts := make([][]byte, 100)
+	for i := range plaintexts {
+		plaintexts[i] = []byte("same")
+	}
+
+	seen := make(map[string]bool)
+	for _, ct := range cipher.SealBatch(plaintexts) {
+		_, _, nonce, _, err := parseFormat(ct)
+		require.NoError(t, err)
+		require.False(t, seen[string(nonce)], "nonce reused within batch")
+		seen[string(nonce)] = true
+	}
+}
+
+func TestSealBatch_IndependentResults(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	ciphertexts := cipher.SealBatch([][]byte{[]byte("a"), []byte("b")})
+
+	// Appending to one result must not clobber another
+	_ = append(ciphertexts[0], 0xFF)
+	result, err := cipher.Open(ciphertexts[1])
+	require.NoError(t, err)
+	require.Equal(t, []byte("b"), result)
+}
+
+func TestSealBatch_AESGCM(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")), WithAEAD(AEADAESGCM))
+
+	ciphertexts := cipher.SealBatch([][]byte{[]byte("a"), []byte("b")})
+	results, errs := cipher.OpenBatch(ciphertexts)
+	require.NoError(t, errs[0])
+	require.NoError(t, errs[1])
+	require.Equal(t, []byte("a"), results[0])
+	require.Equal(t, []byte("b"), results[1])
+}
+
+func TestSealBatch_Empty(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	require.Empty(t, cipher.SealBatch(nil))
+	require.Equal(t, [][]byte{nil, nil}, cipher.SealBatch([][]byte{nil, nil}))
+}
+
+func TestOpenBatch_PartialFailure(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+	other, _ := New(WithKey("v2", testKey("v2")))
+
+	ciphertexts := [][]byte{
+		cipher.Seal([]byte("good")),
+		{0x00},
+		other.Seal([]byte("unknown key")),
+		cipher.Seal([]byte("also good")),
+	}
+
+	results, errs := cipher.OpenBatch(ciphertexts)
+
+	require.NoError(t, errs[0])
+	require.Equal(t, []byte("good"), results[0])
+	require.ErrorIs(t, errs[1], ErrInvalidFormat)
+	require.Nil(t, results[1])
+	require.ErrorIs(t, errs[2], ErrKeyNotFound)
+	require.NoError(t, errs[3])
+	require.Equal(t, []byte("also good"), results[3])
+}
+
+func TestSealBatch_Closed(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+	ciphertexts := cipher.SealBatch([][]byte{[]byte("a")})
+	cipher.Close()
+
+	require.Panics(t, func() {
+		cipher.SealBatch([][]byte{[]byte("a")})
+	})
+
+	_, errs := cipher.OpenBatch(ciphertexts)
+	require.ErrorIs(t, errs[0], ErrCipherClosed)
+}
@@ -1,6 +1,7 @@
 package encryptedcol
 
 import (
+	"fmt"
 	"strings"
 	"testing"
 )
@@ -61,6 +62,16 @@ func BenchmarkSeal_1MB(b *testing.B) {
 	}
 }
 
+func BenchmarkSealAppend_100B(b *testing.B) {
+	data := []byte(strings.Repeat("x", 100))
+	buf := make([]byte, 0, 256)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buf = benchCipher.SealAppend(buf[:0], data)
+	}
+}
+
 // Open benchmarks at various payload sizes
 
 func BenchmarkOpen_100B(b *testing.B) {
@@ -72,6 +83,16 @@ func BenchmarkOpen_100B(b *testing.B) {
 	}
 }
 
+func BenchmarkOpenTo_100B(b *testing.B) {
+	ciphertext := benchCipher.Seal([]byte(strings.Repeat("x", 100)))
+	buf := make([]byte, 0, 256)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buf, _ = benchCipher.OpenTo(buf[:0], ciphertext)
+	}
+}
+
 func BenchmarkOpen_1KB(b *testing.B) {
 	data := []byte(strings.Repeat("x", 1024))
 	ciphertext := benchCipher.Seal(data)
@@ -81,6 +102,16 @@ func BenchmarkOpen_1KB(b *testing.B) {
 	}
 }
 
+func BenchmarkOpenTo_1KB(b *testing.B) {
+	ciphertext := benchCipher.Seal([]byte(strings.Repeat("x", 1024)))
+	buf := make([]byte, 0, 2048)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buf, _ = benchCipher.OpenTo(buf[:0], ciphertext)
+	}
+}
+
 func BenchmarkOpen_10KB(b *testing.B) {
 	data := []byte(strings.Repeat("x", 10*1024))
 	ciphertext := benchCipher.Seal(data)
@@ -220,6 +251,69 @@ func BenchmarkSeal_NoCompression_2KB(b *testing.B) {
 	}
 }
 
+// benchCompressionPayloads are representative column values for comparing algorithms.
+var benchCompressionPayloads = map[string][]byte{
+	"Text_2KB": []byte(strings.Repeat("hello world ", 200)),
+	"JSON_4KB": []byte(strings.Repeat(`{"id":12345,"name":"Alice","email":"alice@example.com","tags":["a","b"]},`, 55)),
+}
+
+func benchmarkSealAlgorithm(b *testing.B, algorithm string) {
+	cipher, _ := New(
+		WithKey("v1", testKey("v1")),
+		WithCompressionAlgorithm(algorithm),
+	)
+	for name, data := range benchCompressionPayloads {
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			var ct []byte
+			for i := 0; i < b.N; i++ {
+				ct = cipher.Seal(data)
+			}
+			b.ReportMetric(float64(len(ct)), "ct-bytes")
+		})
+	}
+}
+
+func BenchmarkSeal_CompressionZstd(b *testing.B) {
+	benchmarkSealAlgorithm(b, "zstd")
+}
+
+func BenchmarkSeal_CompressionAuto(b *testing.B) {
+	benchmarkSealAlgorithm(b, "auto")
+}
+
+// AEAD comparison on small values, reporting ciphertext size
+
+func benchmarkAEAD(b *testing.B, aead AEAD) {
+	cipher, _ := New(WithKey("v1", testKey("v1")), WithAEAD(aead))
+	for _, size := range []int{16, 64, 256} {
+		data := []byte(strings.Repeat("x", size))
+		b.Run(fmt.Sprintf("Seal_%dB", size), func(b *testing.B) {
+			b.ReportAllocs()
+			var ct []byte
+			for i := 0; i < b.N; i++ {
+				ct = cipher.Seal(data)
+			}
+			b.ReportMetric(float64(len(ct)), "ct-bytes")
+		})
+		b.Run(fmt.Sprintf("Open_%dB", size), func(b *testing.B) {
+			ct := cipher.Seal(data)
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_, _ = cipher.Open(ct)
+			}
+		})
+	}
+}
+
+func BenchmarkAEAD_Secretbox(b *testing.B) {
+	benchmarkAEAD(b, AEADSecretbox)
+}
+
+func BenchmarkAEAD_ChaCha20Poly1305(b *testing.B) {
+	benchmarkAEAD(b, AEADChaCha20Poly1305)
+}
+
 // Normalizer benchmarks
 
 func BenchmarkNormalizeEmail(b *testing.B) {
@@ -253,6 +347,44 @@ func BenchmarkRotateValue(b *testing.B) {
 	}
 }
 
+func benchRotationInput(cipher *Cipher) [][]byte {
+	ciphertexts := make([][]byte, 1000)
+	for i := range ciphertexts {
+		ciphertexts[i], _ = cipher.SealWithKey("v1", []byte("user-"+strings.Repeat("x", 50)))
+	}
+	return ciphertexts
+}
+
+func BenchmarkRotateStringIndexed_Loop1000(b *testing.B) {
+	cipher, _ := New(
+		WithKey("v1", testKey("v1")),
+		WithKey("v2", testKey("v2")),
+		WithDefaultKeyID("v2"),
+	)
+	ciphertexts := benchRotationInput(cipher)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, ct := range ciphertexts {
+			_, _ = cipher.RotateStringIndexed(ct)
+		}
+	}
+}
+
+func BenchmarkRotateBatch_1000(b *testing.B) {
+	cipher, _ := New(
+		WithKey("v1", testKey("v1")),
+		WithKey("v2", testKey("v2")),
+		WithDefaultKeyID("v2"),
+	)
+	ciphertexts := benchRotationInput(cipher)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = cipher.RotateBatch(ciphertexts)
+	}
+}
+
 func BenchmarkNeedsRotation(b *testing.B) {
 	cipher, _ := New(
 		WithKey("v1", testKey("v1")),
@@ -265,3 +397,53 @@ func BenchmarkNeedsRotation(b *testing.B) {
 		cipher.NeedsRotation(oldCiphertext)
 	}
 }
+
+// Batch benchmarks (compare against the naive loop)
+
+func benchBatchInput() [][]byte {
+	plaintexts := make([][]byte, 1000)
+	for i := range plaintexts {
+		plaintexts[i] = []byte("user-" + strings.Repeat("x", 50))
+	}
+	return plaintexts
+}
+
+func BenchmarkSeal_Loop1000(b *testing.B) {
+	plaintexts := benchBatchInput()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, p := range plaintexts {
+			benchCipher.Seal(p)
+		}
+	}
+}
+
+func BenchmarkSealBatch_1000(b *testing.B) {
+	plaintexts := benchBatchInput()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		benchCipher.SealBatch(plaintexts)
+	}
+}
+
+func BenchmarkOpen_Loop1000(b *testing.B) {
+	ciphertexts := benchCipher.SealBatch(benchBatchInput())
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, ct := range ciphertexts {
+			_, _ = benchCipher.Open(ct)
+		}
+	}
+}
+
+func BenchmarkOpenBatch_1000(b *testing.B) {
+	ciphertexts := benchCipher.SealBatch(benchBatchInput())
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		benchCipher.OpenBatch(ciphertexts)
+	}
+}
@@ -2,7 +2,27 @@ package encryptedcol
 
 import (
 	"crypto/hmac"
+	"crypto/pbkdf2"
 	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+)
+
+// Blind index size bounds (bytes). The default is the full HMAC-SHA256 output.
+const (
+	minBlindIndexBytes = 4
+	maxBlindIndexBytes = sha256.Size
+)
+
+const (
+	// defaultBlindIndexStretch is the default PBKDF2 iteration count for stretched blind indexes.
+	defaultBlindIndexStretch = 10000
+
+	// stretchedIndexPrefix domain-separates stretched blind indexes from regular ones.
+	stretchedIndexPrefix = "encryptedcol-stretched-index\x00"
+
+	// minBlindIndexPepperBytes is the shortest pepper WithBlindIndexPepper accepts.
+	minBlindIndexPepperBytes = 16
 )
 
 // BlindIndex computes an HMAC-SHA256 blind index using the default key.
@@ -12,29 +32,43 @@ import (
 // The blind index is deterministic: same plaintext + same key = same index.
 // This allows database lookups without exposing the plaintext.
 func (c *Cipher) BlindIndex(plaintext []byte) []byte {
-	if c.closed.Load() {
-		panic("encryptedcol: use of closed Cipher")
-	}
+	r := c.mustAcquire()
+	defer r.release()
 	if plaintext == nil {
 		return nil
 	}
-	return c.computeHMAC(c.defaultID, plaintext)
+	return c.computeHMAC(r, r.defaultID, plaintext)
+}
+
+// TryBlindIndex is BlindIndex that returns ErrCipherClosed after Close instead
+// of panicking. Returns nil, nil if plaintext is nil (NULL preservation).
+func (c *Cipher) TryBlindIndex(plaintext []byte) ([]byte, error) {
+	r, err := c.acquire()
+	if err != nil {
+		return nil, err
+	}
+	defer r.release()
+	if plaintext == nil {
+		return nil, nil
+	}
+	return c.computeHMAC(r, r.defaultID, plaintext), nil
 }
 
 // BlindIndexWithKey computes an HMAC-SHA256 blind index using a specific key.
 // Returns nil if plaintext is nil (NULL preservation).
 func (c *Cipher) BlindIndexWithKey(keyID string, plaintext []byte) ([]byte, error) {
-	if c.closed.Load() {
-		return nil, ErrCipherClosed
+	r, err := c.acquire()
+	if err != nil {
+		return nil, err
 	}
+	defer r.release()
 	if plaintext == nil {
 		return nil, nil
 	}
-	keys, ok := c.keys[keyID]
-	if !ok {
+	if _, ok := r.keys[keyID]; !ok {
 		return nil, ErrKeyNotFound
 	}
-	return computeHMACWithKey(&keys.hmac, plaintext), nil
+	return c.computeHMAC(r, keyID, plaintext), nil
 }
 
 // BlindIndexes computes HMAC blind indexes for all active key versions.
@@ -42,30 +76,186 @@ func (c *Cipher) BlindIndexWithKey(keyID string, plaintext []byte) ([]byte, erro
 // Returns a map of keyID -> blind index.
 // Returns nil if plaintext is nil (NULL preservation).
 func (c *Cipher) BlindIndexes(plaintext []byte) map[string][]byte {
-	if c.closed.Load() {
-		panic("encryptedcol: use of closed Cipher")
-	}
+	r := c.mustAcquire()
+	defer r.release()
 	if plaintext == nil {
 		return nil
 	}
 
-	indexes := make(map[string][]byte, len(c.keys))
-	for keyID := range c.keys {
-		indexes[keyID] = c.computeHMAC(keyID, plaintext)
+	indexes := make(map[string][]byte, len(r.keys))
+	for keyID := range r.keys {
+		indexes[keyID] = c.computeHMAC(r, keyID, plaintext)
 	}
 	return indexes
 }
 
+// BlindIndexesNormalized is BlindIndexes over norm(plaintext): the normalized
+// blind index under every active key version, e.g. to rewrite a normalized
+// searchable column during a multi-key rotation.
+// Returns nil if plaintext is nil (NULL preservation).
+func (c *Cipher) BlindIndexesNormalized(plaintext []byte, norm Normalizer) map[string][]byte {
+	if plaintext == nil {
+		return c.BlindIndexes(nil)
+	}
+	return c.BlindIndexes([]byte(norm(string(plaintext))))
+}
+
 // BlindIndexString computes a blind index for a string value.
 // Convenience method that converts string to bytes.
 func (c *Cipher) BlindIndexString(s string) []byte {
 	return c.BlindIndex([]byte(s))
 }
 
-// computeHMAC computes HMAC-SHA256 using the specified key's HMAC key.
-func (c *Cipher) computeHMAC(keyID string, data []byte) []byte {
-	keys := c.keys[keyID]
-	return computeHMACWithKey(&keys.hmac, data)
+// BlindIndexHex is BlindIndex encoded as lowercase hex, for {column}_idx
+// columns of type TEXT rather than BYTEA. Search them with SearchConditionHex.
+// Returns "" if plaintext is nil; store NULL in that case.
+func (c *Cipher) BlindIndexHex(plaintext []byte) string {
+	return hex.EncodeToString(c.BlindIndex(plaintext))
+}
+
+// BlindIndexTagged computes a blind index using the default key, prefixed with
+// that key ID as [keyIDLen:1][keyID][index], for tables without a key_id
+// column: a single {column}_idx holds both, and SearchConditionTagged matches
+// it with no key_id predicate. Each index grows by 1+len(keyID) bytes.
+// Returns nil if plaintext is nil (NULL preservation).
+//
+// Tagged indexes are not interchangeable with BlindIndex; split one with
+// ParseTaggedIndex.
+func (c *Cipher) BlindIndexTagged(plaintext []byte) []byte {
+	r := c.mustAcquire()
+	defer r.release()
+	if plaintext == nil {
+		return nil
+	}
+	return appendTaggedIndex(nil, r.defaultID, c.computeHMAC(r, r.defaultID, plaintext))
+}
+
+// appendTaggedIndex appends [keyIDLen:1][keyID][index] to dst.
+func appendTaggedIndex(dst []byte, keyID string, index []byte) []byte {
+	dst = append(dst, byte(len(keyID)))
+	dst = append(dst, keyID...)
+	return append(dst, index...)
+}
+
+// ParseTaggedIndex splits an index from BlindIndexTagged into its key ID and
+// the blind index itself, for tooling such as counting indexes per key
+// version. Returns ErrInvalidFormat unless the key ID is non-empty and the
+// remaining index is 4-32 bytes.
+func ParseTaggedIndex(tagged []byte) (keyID string, index []byte, err error) {
+	if len(tagged) < 1 {
+		return "", nil, ErrInvalidFormat
+	}
+	keyIDLen := int(tagged[0])
+	if keyIDLen == 0 || len(tagged) < 1+keyIDLen {
+		return "", nil, ErrInvalidFormat
+	}
+	index = tagged[1+keyIDLen:]
+	if len(index) < minBlindIndexBytes || len(index) > maxBlindIndexBytes {
+		return "", nil, ErrInvalidFormat
+	}
+	return string(tagged[1 : 1+keyIDLen]), index, nil
+}
+
+// BlindIndexForColumn computes a blind index bound to a column name using the default key.
+// The same plaintext in different columns (e.g. email and recovery_email)
+// produces unrelated indexes, so the database cannot correlate values across columns.
+//
+// The HMAC input is column || 0x00 || plaintext. Column names are validated
+// like SearchCondition columns, so they never contain 0x00.
+// Returns nil if plaintext is nil (NULL preservation).
+//
+// Column-bound indexes are not interchangeable with BlindIndex; search them
+// with SearchConditionForColumn.
+func (c *Cipher) BlindIndexForColumn(column string, plaintext []byte) []byte {
+	if !isValidColumnName(column) {
+		panic("encryptedcol: invalid column name (must start with letter/underscore, contain only alphanumeric/underscore)")
+	}
+	r := c.mustAcquire()
+	defer r.release()
+	if plaintext == nil {
+		return nil
+	}
+	return c.computeHMAC(r, r.defaultID, columnIndexInput(column, plaintext))
+}
+
+// blindIndexForColumnWithKey computes a column-bound blind index with a key from r.
+func (c *Cipher) blindIndexForColumnWithKey(r *keyring, keyID string, column string, plaintext []byte) []byte {
+	return c.computeHMAC(r, keyID, columnIndexInput(column, plaintext))
+}
+
+// BlindIndexStretched computes a key-stretched blind index using the default key:
+// PBKDF2-HMAC-SHA256 keyed with the blind index HMAC key, run for the
+// WithBlindIndexStretch iteration count (default 10000).
+// Returns nil if plaintext is nil (NULL preservation).
+//
+// Use it for low-entropy columns (PINs, short codes, small enums) where a
+// leaked HMAC key would let an attacker recover every value by trying all
+// candidates. Stretching makes each guess cost as much as computing the index,
+// which slows such a search but cannot prevent it for tiny domains.
+//
+// Stretched indexes are not interchangeable with BlindIndex; search them with
+// SearchConditionStretched. They cost the full iteration count on every write
+// and for every key version in a search.
+func (c *Cipher) BlindIndexStretched(plaintext []byte) []byte {
+	r := c.mustAcquire()
+	defer r.release()
+	if plaintext == nil {
+		return nil
+	}
+	return c.computeStretchedIndex(r, r.defaultID, plaintext)
+}
+
+// computeStretchedIndex computes a stretched blind index with a key from r,
+// truncated to the configured blind index size.
+func (c *Cipher) computeStretchedIndex(r *keyring, keyID string, plaintext []byte) []byte {
+	keys := r.keys[keyID]
+	pepper := c.config.blindIndexPepper
+	salt := make([]byte, 0, len(stretchedIndexPrefix)+len(pepper)+len(plaintext))
+	salt = append(salt, stretchedIndexPrefix...)
+	salt = append(salt, pepper...)
+	salt = append(salt, plaintext...)
+
+	mac, err := pbkdf2.Key(sha256.New, string(keys.hmac[:]), salt, c.config.blindIndexStretch, sha256.Size)
+	if err != nil {
+		// Only possible under FIPS 140-only mode restrictions, which the
+		// fixed key and salt sizes satisfy
+		panic("encryptedcol: internal error: " + err.Error())
+	}
+	n := c.config.blindIndexBytes
+	return mac[:n:n]
+}
+
+// BlindIndexEqual reports whether two blind indexes are equal in constant time.
+// Use it instead of bytes.Equal when re-checking a fetched row's index in
+// application code, so the comparison does not leak how many bytes matched.
+//
+// Indexes of different lengths are unequal. Empty or nil (NULL) indexes never
+// match anything, mirroring SQL NULL semantics.
+func BlindIndexEqual(a, b []byte) bool {
+	if len(a) == 0 || len(a) != len(b) {
+		return false
+	}
+	return subtle.ConstantTimeCompare(a, b) == 1
+}
+
+// columnIndexInput builds the HMAC input column || 0x00 || plaintext.
+func columnIndexInput(column string, plaintext []byte) []byte {
+	input := make([]byte, 0, len(column)+1+len(plaintext))
+	input = append(input, column...)
+	input = append(input, 0x00)
+	return append(input, plaintext...)
+}
+
+// computeHMAC computes HMAC-SHA256 over pepper || data (no pepper unless
+// WithBlindIndexPepper is set) using the specified key's HMAC key from r,
+// truncated to the configured blind index size.
+func (c *Cipher) computeHMAC(r *keyring, keyID string, data []byte) []byte {
+	h := hmac.New(sha256.New, r.keys[keyID].hmac[:])
+	h.Write(c.config.blindIndexPepper)
+	h.Write(data)
+	mac := h.Sum(nil)
+	n := c.config.blindIndexBytes
+	return mac[:n:n]
 }
 
 // computeHMACWithKey computes HMAC-SHA256 with the given key.
@@ -2,6 +2,11 @@ package encryptedcol
 
 import (
 	"bytes"
+	"crypto/hmac"
+	"crypto/pbkdf2"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -126,6 +131,23 @@ func TestBlindIndexes_NullPreservation(t *testing.T) {
 	require.Nil(t, indexes)
 }
 
+func TestBlindIndexesNormalized(t *testing.T) {
+	cipher, _ := New(
+		WithKey("v1", testKey("v1")),
+		WithKey("v2", testKey("v2")),
+	)
+
+	indexes := cipher.BlindIndexesNormalized([]byte("  Test@Example.COM "), NormalizeEmail)
+	require.Equal(t, cipher.BlindIndexes([]byte("test@example.com")), indexes)
+
+	// Each key's index matches that key's search argument
+	cond := cipher.SearchConditionStringNormalized("email", "TEST@example.com", 1, NormalizeEmail)
+	require.Equal(t, indexes["v1"], cond.Args[1])
+	require.Equal(t, indexes["v2"], cond.Args[3])
+
+	require.Nil(t, cipher.BlindIndexesNormalized(nil, NormalizeEmail))
+}
+
 func TestBlindIndexString(t *testing.T) {
 	cipher, _ := New(WithKey("v1", testKey("v1")))
 
@@ -186,3 +208,246 @@ func TestBlindIndex_UseAfterClose(t *testing.T) {
 	_, err := cipher.BlindIndexWithKey("v1", []byte("test"))
 	require.ErrorIs(t, err, ErrCipherClosed, "BlindIndexWithKey should return ErrCipherClosed")
 }
+
+func TestBlindIndexForColumn(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	plaintext := []byte("alice@example.com")
+	email := cipher.BlindIndexForColumn("email", plaintext)
+	recovery := cipher.BlindIndexForColumn("recovery_email", plaintext)
+
+	require.Len(t, email, 32)
+	require.NotEqual(t, email, recovery, "columns must be domain-separated")
+	require.NotEqual(t, cipher.BlindIndex(plaintext), email, "must differ from column-agnostic index")
+	require.Equal(t, email, cipher.BlindIndexForColumn("email", plaintext), "must be deterministic")
+}
+
+func TestBlindIndexForColumn_NoBoundaryAmbiguity(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	// "ab" + "c" must not collide with "a" + "bc"
+	require.NotEqual(t,
+		cipher.BlindIndexForColumn("ab", []byte("c")),
+		cipher.BlindIndexForColumn("a", []byte("bc")),
+	)
+}
+
+func TestBlindIndexForColumn_Null(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	require.Nil(t, cipher.BlindIndexForColumn("email", nil))
+	require.Panics(t, func() {
+		cipher.BlindIndexForColumn("bad column", []byte("x"))
+	})
+}
+
+func TestWithBlindIndexBytes(t *testing.T) {
+	full, _ := New(WithKey("v1", testKey("v1")))
+	truncated, err := New(WithKey("v1", testKey("v1")), WithBlindIndexBytes(8))
+	require.NoError(t, err)
+
+	plaintext := []byte("alice@example.com")
+	idx := truncated.BlindIndex(plaintext)
+	require.Len(t, idx, 8)
+	require.Equal(t, full.BlindIndex(plaintext)[:8], idx)
+
+	withKey, err := truncated.BlindIndexWithKey("v1", plaintext)
+	require.NoError(t, err)
+	require.Equal(t, idx, withKey)
+
+	for _, v := range truncated.BlindIndexes(plaintext) {
+		require.Len(t, v, 8)
+	}
+	require.Len(t, truncated.BlindIndexForColumn("email", plaintext), 8)
+	for _, tok := range truncated.BlindIndexTokens(plaintext, TokenizePrefix) {
+		require.Len(t, tok, 8)
+	}
+
+	// Appending to a truncated index must not expose the remaining HMAC bytes
+	require.Equal(t, 8, cap(idx))
+}
+
+func TestWithBlindIndexBytes_SearchMatches(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")), WithBlindIndexBytes(4))
+
+	sealed := cipher.SealStringIndexed("alice")
+	cond := cipher.SearchConditionString("email", "alice", 1)
+	require.Equal(t, sealed.BlindIndex, cond.Args[1])
+}
+
+func TestWithBlindIndexBytes_Invalid(t *testing.T) {
+	for _, n := range []int{0, 3, 33, -1} {
+		_, err := New(WithKey("v1", testKey("v1")), WithBlindIndexBytes(n))
+		require.ErrorIs(t, err, ErrInvalidBlindIndexSize, "n=%d", n)
+	}
+}
+
+func TestBlindIndexEqual(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+	idx := cipher.BlindIndexString("alice@example.com")
+
+	tests := []struct {
+		name string
+		a, b []byte
+		want bool
+	}{
+		{"same index", idx, cipher.BlindIndexString("alice@example.com"), true},
+		{"different plaintext", idx, cipher.BlindIndexString("bob@example.com"), false},
+		{"truncated", idx, idx[:8], false},
+		{"nil vs index", nil, idx, false},
+		{"both nil", nil, nil, false},
+		{"both empty", []byte{}, []byte{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, BlindIndexEqual(tt.a, tt.b))
+		})
+	}
+}
+
+func TestBlindIndexStretched(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")), WithBlindIndexStretch(100))
+
+	pin := []byte("1234")
+	idx := cipher.BlindIndexStretched(pin)
+	require.Len(t, idx, 32)
+	require.Equal(t, idx, cipher.BlindIndexStretched(pin), "stretched index must be deterministic")
+	require.NotEqual(t, idx, cipher.BlindIndexStretched([]byte("1235")))
+	require.NotEqual(t, idx, cipher.BlindIndex(pin), "stretched index must differ from the plain index")
+	require.Nil(t, cipher.BlindIndexStretched(nil))
+
+	// PBKDF2-HMAC-SHA256 keyed with the derived HMAC key
+	r := cipher.mustAcquire()
+	want, err := pbkdf2.Key(sha256.New, string(r.keys["v1"].hmac[:]), []byte("encryptedcol-stretched-index\x001234"), 100, 32)
+	r.release()
+	require.NoError(t, err)
+	require.Equal(t, want, idx)
+
+	// The iteration count changes the index (reindex required)
+	other, _ := New(WithKey("v1", testKey("v1")), WithBlindIndexStretch(101))
+	require.NotEqual(t, idx, other.BlindIndexStretched(pin))
+
+	// Truncation applies as for other indexes
+	truncated, _ := New(WithKey("v1", testKey("v1")), WithBlindIndexStretch(100), WithBlindIndexBytes(8))
+	require.Equal(t, idx[:8], truncated.BlindIndexStretched(pin))
+}
+
+func TestBlindIndexStretched_Search(t *testing.T) {
+	cipher, _ := New(
+		WithKey("v1", testKey("v1")),
+		WithKey("v2", testKey("v2")),
+		WithDefaultKeyID("v2"),
+		WithBlindIndexStretch(50),
+	)
+
+	cond := cipher.SearchConditionStretched("pin", []byte("1234"), 1)
+	require.Equal(t, cipher.SearchCondition("pin", []byte("1234"), 1).SQL, cond.SQL)
+	require.Equal(t, "v2", cond.Args[2])
+	require.Equal(t, cipher.BlindIndexStretched([]byte("1234")), cond.Args[3])
+
+	require.Equal(t, "FALSE", cipher.SearchConditionStretched("pin", nil, 1).SQL)
+}
+
+func TestWithBlindIndexStretch_Invalid(t *testing.T) {
+	for _, n := range []int{0, -1} {
+		_, err := New(WithKey("v1", testKey("v1")), WithBlindIndexStretch(n))
+		require.ErrorIs(t, err, ErrInvalidBlindIndexStretch, "n=%d", n)
+	}
+
+	// Default applies without the option
+	cipher, err := New(WithKey("v1", testKey("v1")))
+	require.NoError(t, err)
+	require.Len(t, cipher.BlindIndexStretched([]byte("1234")), 32)
+}
+
+func TestWithBlindIndexPepper(t *testing.T) {
+	pepper := []byte("0123456789abcdef-pepper")
+	plain, _ := New(WithKey("v1", testKey("v1")))
+	peppered, err := New(WithKey("v1", testKey("v1")), WithBlindIndexPepper(pepper))
+	require.NoError(t, err)
+	other, _ := New(WithKey("v1", testKey("v1")), WithBlindIndexPepper([]byte("another-pepper-of-16+")))
+
+	idx := peppered.BlindIndex([]byte("female"))
+	require.NotEqual(t, plain.BlindIndex([]byte("female")), idx)
+	require.NotEqual(t, other.BlindIndex([]byte("female")), idx)
+
+	// The derived HMAC key alone no longer reproduces the index
+	h := hmac.New(sha256.New, peppered.ring.Load().keys["v1"].hmac[:])
+	h.Write(pepper)
+	h.Write([]byte("female"))
+	require.Equal(t, h.Sum(nil), idx)
+
+	// Every index path uses it: sealing, search and stretched indexes
+	sealed := peppered.SealStringIndexed("female")
+	require.Equal(t, idx, sealed.BlindIndex)
+	require.Contains(t, peppered.SearchCondition("gender", []byte("female"), 1).Args, idx)
+	require.NotEqual(t, plain.BlindIndexStretched([]byte("1234")), peppered.BlindIndexStretched([]byte("1234")))
+	require.Contains(t, peppered.SearchConditionStretched("pin", []byte("1234"), 1).Args, peppered.BlindIndexStretched([]byte("1234")))
+
+	// Ciphertext is unaffected
+	pt, err := plain.OpenString(sealed.Ciphertext)
+	require.NoError(t, err)
+	require.Equal(t, "female", pt)
+}
+
+func TestWithBlindIndexPepper_Invalid(t *testing.T) {
+	for _, pepper := range [][]byte{{}, []byte("too-short")} {
+		_, err := New(WithKey("v1", testKey("v1")), WithBlindIndexPepper(pepper))
+		require.ErrorIs(t, err, ErrInvalidBlindIndexPepper)
+	}
+
+	// nil means no pepper
+	_, err := New(WithKey("v1", testKey("v1")), WithBlindIndexPepper(nil))
+	require.NoError(t, err)
+}
+
+func TestBlindIndexTagged(t *testing.T) {
+	cipher, _ := New(
+		WithKey("v1", testKey("v1")),
+		WithKey("v2", testKey("v2")),
+		WithDefaultKeyID("v2"),
+	)
+
+	tagged := cipher.BlindIndexTagged([]byte("alice@example.com"))
+	require.Len(t, tagged, 1+len("v2")+32)
+	require.Equal(t, []byte{2, 'v', '2'}, tagged[:3])
+
+	keyID, index, err := ParseTaggedIndex(tagged)
+	require.NoError(t, err)
+	require.Equal(t, "v2", keyID)
+	require.Equal(t, cipher.BlindIndex([]byte("alice@example.com")), index)
+
+	require.Nil(t, cipher.BlindIndexTagged(nil))
+
+	// Truncated indexes are tagged too
+	short, _ := New(WithKey("v1", testKey("v1")), WithBlindIndexBytes(8))
+	_, index, err = ParseTaggedIndex(short.BlindIndexTagged([]byte("x")))
+	require.NoError(t, err)
+	require.Len(t, index, 8)
+}
+
+func TestParseTaggedIndex_Invalid(t *testing.T) {
+	for _, tagged := range [][]byte{
+		nil,
+		{},
+		{0, 1, 2, 3, 4},        // empty key ID
+		{5, 'v', '1'},          // key ID longer than the input
+		{2, 'v', '1', 1, 2, 3}, // index too short
+		append([]byte{2, 'v', '1'}, make([]byte, 33)...), // index too long
+	} {
+		_, _, err := ParseTaggedIndex(tagged)
+		require.ErrorIs(t, err, ErrInvalidFormat, "%x", tagged)
+	}
+}
+
+func TestBlindIndexHex(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	index := cipher.BlindIndexHex([]byte("alice@example.com"))
+	require.Len(t, index, 64)
+	require.Equal(t, strings.ToLower(index), index)
+	require.Equal(t, hex.EncodeToString(cipher.BlindIndex([]byte("alice@example.com"))), index)
+
+	require.Empty(t, cipher.BlindIndexHex(nil))
+}
@@ -0,0 +1,150 @@
+package encryptedcol
+
+import (
+	"bytes"
+	"sync"
+	"time"
+)
+
+// CachingKeyProvider wraps a KeyProvider and memoizes its results for a TTL.
+// It is safe for concurrent use.
+//
+// Long-lived services can call NewWithProvider again (or Refresh) to pick up
+// new key versions from the inner provider without hitting it on every call.
+type CachingKeyProvider struct {
+	inner KeyProvider
+	ttl   time.Duration
+	now   func() time.Time // overridable for tests
+
+	mu          sync.Mutex
+	keys        map[string]cachedKey
+	defaultID   string
+	activeIDs   []string
+	metaFetched time.Time // zero until DefaultKeyID/ActiveKeyIDs are cached
+}
+
+// cachedKey is a memoized GetKey result.
+type cachedKey struct {
+	key     []byte
+	fetched time.Time
+}
+
+// Compile-time interface check
+var _ KeyProvider = (*CachingKeyProvider)(nil)
+
+// NewCachingKeyProvider creates a CachingKeyProvider that re-fetches keys and
+// key metadata from inner once they are older than ttl.
+// A ttl <= 0 caches results until Refresh is called.
+func NewCachingKeyProvider(inner KeyProvider, ttl time.Duration) *CachingKeyProvider {
+	return &CachingKeyProvider{
+		inner: inner,
+		ttl:   ttl,
+		now:   time.Now,
+		keys:  make(map[string]cachedKey),
+	}
+}
+
+// expired reports whether a value fetched at t must be re-fetched.
+func (p *CachingKeyProvider) expired(t time.Time) bool {
+	return p.ttl > 0 && p.now().Sub(t) >= p.ttl
+}
+
+// GetKey implements KeyProvider. Errors from the inner provider are not cached.
+func (p *CachingKeyProvider) GetKey(keyID string) ([]byte, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	entry, ok := p.keys[keyID]
+	if !ok || p.expired(entry.fetched) {
+		key, err := p.inner.GetKey(keyID)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			zeroBytes(entry.key)
+		}
+		entry = cachedKey{key: bytes.Clone(key), fetched: p.now()}
+		p.keys[keyID] = entry
+	}
+
+	// Return a copy to prevent external modification
+	return bytes.Clone(entry.key), nil
+}
+
+// DefaultKeyID implements KeyProvider.
+func (p *CachingKeyProvider) DefaultKeyID() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.loadMetaLocked()
+	return p.defaultID
+}
+
+// ActiveKeyIDs implements KeyProvider.
+func (p *CachingKeyProvider) ActiveKeyIDs() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.loadMetaLocked()
+	return append([]string(nil), p.activeIDs...)
+}
+
+// loadMetaLocked re-fetches the default and active key IDs if they are
+// missing or expired. p.mu must be held.
+func (p *CachingKeyProvider) loadMetaLocked() {
+	if !p.metaFetched.IsZero() && !p.expired(p.metaFetched) {
+		return
+	}
+	p.defaultID = p.inner.DefaultKeyID()
+	p.activeIDs = p.inner.ActiveKeyIDs()
+	p.metaFetched = p.now()
+}
+
+// Refresh forces a reload of the default key ID, active key IDs, and every
+// active key from the inner provider. If any key cannot be fetched, the
+// existing cache is left unchanged and the error is returned.
+func (p *CachingKeyProvider) Refresh() error {
+	defaultID := p.inner.DefaultKeyID()
+	activeIDs := p.inner.ActiveKeyIDs()
+
+	now := p.now()
+	keys := make(map[string]cachedKey, len(activeIDs))
+	for _, keyID := range activeIDs {
+		key, err := p.inner.GetKey(keyID)
+		if err != nil {
+			for _, entry := range keys {
+				zeroBytes(entry.key)
+			}
+			return err
+		}
+		keys[keyID] = cachedKey{key: bytes.Clone(key), fetched: now}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, entry := range p.keys {
+		zeroBytes(entry.key)
+	}
+	p.keys = keys
+	p.defaultID = defaultID
+	p.activeIDs = activeIDs
+	p.metaFetched = now
+	return nil
+}
+
+// Close zeros out all cached key material from memory.
+// It does not close the inner provider.
+func (p *CachingKeyProvider) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, entry := range p.keys {
+		zeroBytes(entry.key)
+	}
+	p.keys = make(map[string]cachedKey)
+	p.metaFetched = time.Time{}
+}
+
+// zeroBytes overwrites b with zeros.
+func zeroBytes(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
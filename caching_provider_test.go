@@ -0,0 +1,185 @@
+package encryptedcol
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// countingProvider wraps a StaticKeyProvider and counts GetKey calls.
+type countingProvider struct {
+	mu       sync.Mutex
+	inner    *StaticKeyProvider
+	getCalls int
+	failKey  string
+}
+
+func (p *countingProvider) GetKey(keyID string) ([]byte, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.getCalls++
+	if keyID == p.failKey {
+		return nil, errors.New("backend unavailable")
+	}
+	return p.inner.GetKey(keyID)
+}
+
+func (p *countingProvider) DefaultKeyID() string   { return p.inner.DefaultKeyID() }
+func (p *countingProvider) ActiveKeyIDs() []string { return p.inner.ActiveKeyIDs() }
+
+func (p *countingProvider) set(inner *StaticKeyProvider) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.inner = inner
+}
+
+func (p *countingProvider) calls() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.getCalls
+}
+
+func TestCachingKeyProvider_MemoizesUntilTTL(t *testing.T) {
+	inner := &countingProvider{inner: NewStaticKeyProvider("v1", map[string][]byte{"v1": testKey("v1")})}
+	p := NewCachingKeyProvider(inner, time.Minute)
+
+	now := time.Unix(1_700_000_000, 0)
+	p.now = func() time.Time { return now }
+
+	key, err := p.GetKey("v1")
+	require.NoError(t, err)
+	require.Equal(t, testKey("v1"), key)
+
+	_, _ = p.GetKey("v1")
+	require.Equal(t, 1, inner.calls())
+
+	// Rotate the key behind the cache; stale value is served until TTL
+	inner.set(NewStaticKeyProvider("v1", map[string][]byte{"v1": testKey("v2")}))
+	key, _ = p.GetKey("v1")
+	require.Equal(t, testKey("v1"), key)
+
+	now = now.Add(time.Minute)
+	key, _ = p.GetKey("v1")
+	require.Equal(t, testKey("v2"), key)
+	require.Equal(t, 2, inner.calls())
+}
+
+func TestCachingKeyProvider_MetadataTTL(t *testing.T) {
+	inner := &countingProvider{inner: NewStaticKeyProvider("v1", map[string][]byte{"v1": testKey("v1")})}
+	p := NewCachingKeyProvider(inner, time.Minute)
+
+	now := time.Unix(1_700_000_000, 0)
+	p.now = func() time.Time { return now }
+
+	require.Equal(t, []string{"v1"}, p.ActiveKeyIDs())
+
+	inner.set(NewStaticKeyProvider("v2", map[string][]byte{"v1": testKey("v1"), "v2": testKey("v2")}))
+	require.Equal(t, "v1", p.DefaultKeyID())
+
+	now = now.Add(2 * time.Minute)
+	require.Equal(t, "v2", p.DefaultKeyID())
+	require.Equal(t, []string{"v1", "v2"}, p.ActiveKeyIDs())
+}
+
+func TestCachingKeyProvider_Refresh(t *testing.T) {
+	inner := &countingProvider{inner: NewStaticKeyProvider("v1", map[string][]byte{"v1": testKey("v1")})}
+	p := NewCachingKeyProvider(inner, 0) // never expires
+
+	cipher, err := NewWithProvider(p)
+	require.NoError(t, err)
+	require.Equal(t, []string{"v1"}, cipher.ActiveKeyIDs())
+
+	inner.set(NewStaticKeyProvider("v2", map[string][]byte{"v1": testKey("v1"), "v2": testKey("v2")}))
+	require.Equal(t, "v1", p.DefaultKeyID())
+
+	require.NoError(t, p.Refresh())
+	require.Equal(t, "v2", p.DefaultKeyID())
+
+	calls := inner.calls()
+	cipher2, err := NewWithProvider(p)
+	require.NoError(t, err)
+	require.Equal(t, []string{"v1", "v2"}, cipher2.ActiveKeyIDs())
+	require.Equal(t, calls, inner.calls(), "keys should be served from cache after Refresh")
+
+	// Old and new ciphers interoperate on v1
+	pt, err := cipher2.Open(cipher.Seal([]byte("hello")))
+	require.NoError(t, err)
+	require.Equal(t, []byte("hello"), pt)
+}
+
+func TestCachingKeyProvider_RefreshErrorKeepsCache(t *testing.T) {
+	inner := &countingProvider{inner: NewStaticKeyProvider("v1", map[string][]byte{"v1": testKey("v1")})}
+	p := NewCachingKeyProvider(inner, 0)
+	require.NoError(t, p.Refresh())
+
+	inner.set(NewStaticKeyProvider("v2", map[string][]byte{"v1": testKey("v1"), "v2": testKey("v2")}))
+	inner.failKey = "v2"
+
+	require.Error(t, p.Refresh())
+	require.Equal(t, "v1", p.DefaultKeyID())
+	key, err := p.GetKey("v1")
+	require.NoError(t, err)
+	require.Equal(t, testKey("v1"), key)
+}
+
+func TestCachingKeyProvider_ErrorsNotCached(t *testing.T) {
+	inner := &countingProvider{inner: NewStaticKeyProvider("v1", map[string][]byte{"v1": testKey("v1")})}
+	p := NewCachingKeyProvider(inner, time.Hour)
+
+	_, err := p.GetKey("missing")
+	require.ErrorIs(t, err, ErrKeyNotFound)
+	_, err = p.GetKey("missing")
+	require.ErrorIs(t, err, ErrKeyNotFound)
+	require.Equal(t, 2, inner.calls())
+}
+
+func TestCachingKeyProvider_ReturnsCopies(t *testing.T) {
+	inner := &countingProvider{inner: NewStaticKeyProvider("v1", map[string][]byte{"v1": testKey("v1")})}
+	p := NewCachingKeyProvider(inner, time.Hour)
+
+	key, _ := p.GetKey("v1")
+	key[0] ^= 0xff
+
+	again, _ := p.GetKey("v1")
+	require.Equal(t, testKey("v1"), again)
+}
+
+func TestCachingKeyProvider_Close(t *testing.T) {
+	inner := &countingProvider{inner: NewStaticKeyProvider("v1", map[string][]byte{"v1": testKey("v1")})}
+	p := NewCachingKeyProvider(inner, time.Hour)
+	_, _ = p.GetKey("v1")
+
+	internal := p.keys["v1"].key
+	p.Close()
+	require.Equal(t, make([]byte, 32), internal)
+
+	// Usable again: re-fetches from inner
+	key, err := p.GetKey("v1")
+	require.NoError(t, err)
+	require.Equal(t, testKey("v1"), key)
+	require.Equal(t, 2, inner.calls())
+}
+
+func TestCachingKeyProvider_Concurrent(t *testing.T) {
+	inner := &countingProvider{inner: NewStaticKeyProvider("v1", map[string][]byte{"v1": testKey("v1")})}
+	p := NewCachingKeyProvider(inner, time.Millisecond)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				_, _ = p.GetKey("v1")
+				_ = p.ActiveKeyIDs()
+				if j%25 == 0 {
+					_ = p.Refresh()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
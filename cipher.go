@@ -2,39 +2,223 @@ package encryptedcol
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
 	"crypto/subtle"
+	"encoding/hex"
+	"slices"
 	"sort"
+	"sync"
 	"sync/atomic"
-
-	"golang.org/x/crypto/nacl/secretbox"
+	"time"
 )
 
 // Cipher provides encryption, decryption, and blind indexing for database columns.
 // It is safe for concurrent use.
 type Cipher struct {
+	ring   atomic.Pointer[keyring] // current key snapshot (swapped by ReloadKeys)
+	config *config                 // configuration options
+	closed atomic.Bool             // true after Close() called
+	mu     sync.Mutex              // serializes ReloadKeys and Close
+}
+
+// keyring is an immutable snapshot of derived keys and the default key ID.
+// Operations hold mu for reading while they use the keys, so retire cannot
+// zero key material out from under an in-flight Seal or Open.
+type keyring struct {
 	keys      map[string]*derivedKeys // keyID -> derived keys (cached)
 	defaultID string                  // default key ID for new encryptions
-	config    *config                 // configuration options
-	closed    atomic.Bool             // true after Close() called
+	mu        sync.RWMutex
+	retired   bool // true once keys have been zeroed
+}
+
+// newKeyring derives keys for each master key with the given HKDF salt.
+func newKeyring(masterKeys map[string][]byte, defaultID string, salt []byte) (*keyring, error) {
+	r := &keyring{
+		keys:      make(map[string]*derivedKeys, len(masterKeys)),
+		defaultID: defaultID,
+	}
+	for keyID, masterKey := range masterKeys {
+		dk, err := deriveKeys(masterKey, salt)
+		if err != nil {
+			r.retire()
+			return nil, err
+		}
+		r.keys[keyID] = dk
+	}
+	return r, nil
+}
+
+// release ends an operation started by Cipher.acquire.
+func (r *keyring) release() {
+	r.mu.RUnlock()
+}
+
+// retire waits for in-flight operations to finish, then zeros all derived keys.
+func (r *keyring) retire() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.retired = true
+	for _, dk := range r.keys {
+		zeroKey(&dk.encryption)
+		zeroKey(&dk.aesgcm)
+		dk.gcm = nil
+		zeroKey(&dk.aesgcmsiv)
+		dk.gcmsiv = nil
+		zeroKey(&dk.chachaKey)
+		dk.chacha = nil
+		zeroKey(&dk.hmac)
+		zeroKey(&dk.siv)
+		zeroKey(&dk.tenant)
+		zeroKey(&dk.check)
+	}
+	r.keys = nil
+}
+
+// acquire returns the current keyring, read-locked until release is called.
+// Returns ErrCipherClosed after Close.
+//
+// Callers must not acquire again before releasing: a pending retire blocks
+// new readers, so nested acquisition can deadlock.
+func (c *Cipher) acquire() (*keyring, error) {
+	for {
+		if c.closed.Load() {
+			return nil, ErrCipherClosed
+		}
+		r := c.ring.Load()
+		r.mu.RLock()
+		if !r.retired {
+			return r, nil
+		}
+		// Swapped out by ReloadKeys (or closed) since Load; try again
+		r.mu.RUnlock()
+	}
+}
+
+// mustAcquire is acquire for methods that panic on a closed Cipher.
+func (c *Cipher) mustAcquire() *keyring {
+	r, err := c.acquire()
+	if err != nil {
+		panic("encryptedcol: use of closed Cipher")
+	}
+	return r
+}
+
+// acquireWritable is acquire for methods that create ciphertext: it returns
+// ErrReadOnly for a Cipher built WithReadOnly.
+func (c *Cipher) acquireWritable() (*keyring, error) {
+	if err := c.checkWritable(); err != nil {
+		return nil, err
+	}
+	return c.acquire()
+}
+
+// mustAcquireWritable is mustAcquire for methods that create ciphertext; it
+// also panics on a Cipher built WithReadOnly.
+func (c *Cipher) mustAcquireWritable() *keyring {
+	if c.config.readOnly {
+		panic("encryptedcol: seal with read-only Cipher")
+	}
+	return c.mustAcquire()
+}
+
+// checkWritable returns ErrReadOnly if the Cipher was built WithReadOnly.
+func (c *Cipher) checkWritable() error {
+	if c.config.readOnly {
+		return ErrReadOnly
+	}
+	return nil
 }
 
 // config holds cipher configuration options.
 type config struct {
-	keys                 map[string][]byte // keyID -> master key (32 bytes)
-	defaultKeyID         string
-	compressionThreshold int
-	compressionAlgorithm string
-	compressionDisabled  bool
-	emptyStringAsNull    bool
+	keys                  map[string][]byte  // keyID -> master key (32 bytes)
+	keyMeta               map[string]KeyMeta // keyID -> informational metadata (WithKeyEx)
+	duplicateKeyID        string             // first key ID registered twice, reported by New
+	defaultKeyID          string
+	defaultKeyIDSet       bool // WithDefaultKeyID was given
+	strictDefault         bool // require WithDefaultKeyID (WithStrictDefault)
+	compressionThreshold  int
+	compressionAlgorithm  string
+	compressionLevel      int
+	compressionDisabled   bool
+	maxDecompressedSize   int
+	minCompressionSavings float64
+	emptyStringAsNull     bool
+	aead                  AEAD
+	placeholderStyle      PlaceholderStyle
+	omitKeyIDPredicate    bool
+	blindIndexBytes       int
+	blindIndexStretch     int
+	blindIndexPepper      []byte // secret mixed into blind index input (nil = none)
+	observer              Observer
+	nonceSource           func() [24]byte                               // TEST ONLY (WithNonceSource)
+	derivedNonce          func(keyID string, plaintext []byte) [24]byte // WithDerivedNonce
+	hkdfSalt              []byte                                        // HKDF salt for key derivation (nil = none)
+	strictKeyIDs          bool
+	readOnly              bool
+	timestampBinding      bool             // WithTimestampBinding
+	paddingBlockSize      int              // WithPadding (0 = no padding)
+	clock                 func() time.Time // TEST ONLY: seal time for WithTimestampBinding (nil = time.Now)
+	rejectWeakKeys        bool
 }
 
 // defaultConfig returns the default configuration.
 func defaultConfig() *config {
 	return &config{
-		keys:                 make(map[string][]byte),
-		compressionThreshold: defaultCompressionThreshold,
-		compressionAlgorithm: compressionAlgorithmZstd,
+		keys:                  make(map[string][]byte),
+		compressionThreshold:  defaultCompressionThreshold,
+		compressionAlgorithm:  compressionAlgorithmZstd,
+		compressionLevel:      defaultCompressionLevel,
+		maxDecompressedSize:   maxDecompressedSize,
+		minCompressionSavings: defaultMinCompressionSavings,
+		blindIndexBytes:       maxBlindIndexBytes,
+		blindIndexStretch:     defaultBlindIndexStretch,
+	}
+}
+
+// validateKeyID checks that keyID fits the single-byte length prefix and,
+// with WithStrictKeyIDs, uses only [A-Za-z0-9._-].
+func (cfg *config) validateKeyID(keyID string) error {
+	if len(keyID) == 0 || len(keyID) > maxKeyIDLen {
+		return ErrInvalidKeyID
+	}
+	if !cfg.strictKeyIDs {
+		return nil
+	}
+	for i := 0; i < len(keyID); i++ {
+		b := keyID[i]
+		if (b < 'a' || b > 'z') && (b < 'A' || b > 'Z') && (b < '0' || b > '9') &&
+			b != '.' && b != '_' && b != '-' {
+			return ErrInvalidKeyIDChars
+		}
+	}
+	return nil
+}
+
+// checkWeakKeys returns ErrWeakKey if WithRejectWeakKeys is set and any
+// master key consists of a single repeated byte (e.g. all zeros).
+// This is a cheap guard against misconfigured secrets, not an entropy estimate.
+func (cfg *config) checkWeakKeys(keys map[string][]byte) error {
+	if !cfg.rejectWeakKeys {
+		return nil
+	}
+	for _, key := range keys {
+		// Wrong-sized keys are left to key derivation (ErrInvalidKeySize)
+		if len(key) == masterKeySize && isRepeatedByte(key) {
+			return ErrWeakKey
+		}
+	}
+	return nil
+}
+
+// isRepeatedByte reports whether every byte of b equals the first.
+func isRepeatedByte(b []byte) bool {
+	for _, v := range b {
+		if v != b[0] {
+			return false
+		}
 	}
+	return true
 }
 
 // sortedMapKeys returns map keys sorted alphabetically.
@@ -70,6 +254,16 @@ func New(opts ...Option) (*Cipher, error) {
 	// Note: defaultKeyID is always set by the first WithKey() call.
 	// If using NewWithProvider(), it's set explicitly via WithDefaultKeyID().
 
+	// Reject a key ID registered twice (the later key would silently win)
+	if cfg.duplicateKeyID != "" {
+		return nil, duplicateKeyIDError(cfg.duplicateKeyID)
+	}
+
+	// WithStrictDefault: the first WithKey must not become the default implicitly
+	if cfg.strictDefault && !cfg.defaultKeyIDSet {
+		return nil, ErrNoDefaultKey
+	}
+
 	// Verify default key exists
 	if _, ok := cfg.keys[cfg.defaultKeyID]; !ok {
 		return nil, ErrDefaultKeyNotFound
@@ -77,17 +271,75 @@ func New(opts ...Option) (*Cipher, error) {
 
 	// Validate key IDs (must fit in single byte length field)
 	for keyID := range cfg.keys {
-		if len(keyID) == 0 || len(keyID) > 255 {
-			return nil, ErrInvalidKeyID
+		if err := cfg.validateKeyID(keyID); err != nil {
+			return nil, err
 		}
 	}
 
+	// Sanity-check key material (WithRejectWeakKeys)
+	if err := cfg.checkWeakKeys(cfg.keys); err != nil {
+		return nil, err
+	}
+
 	// Validate compression algorithm
 	if cfg.compressionAlgorithm != "" &&
-		cfg.compressionAlgorithm != compressionAlgorithmZstd {
+		cfg.compressionAlgorithm != compressionAlgorithmZstd &&
+		cfg.compressionAlgorithm != compressionAlgorithmSnappy &&
+		cfg.compressionAlgorithm != compressionAlgorithmAuto {
 		return nil, ErrUnsupportedCompression
 	}
 
+	// Validate compression level
+	if cfg.compressionLevel < minCompressionLevel || cfg.compressionLevel > maxCompressionLevel {
+		return nil, ErrInvalidCompressionLevel
+	}
+
+	// Validate decompression limit
+	if cfg.maxDecompressedSize <= 0 {
+		return nil, ErrInvalidMaxDecompressedSize
+	}
+
+	// Validate compression savings floor (also rejects NaN)
+	if !(cfg.minCompressionSavings > 0 && cfg.minCompressionSavings < 1) {
+		return nil, ErrInvalidMinCompressionSavings
+	}
+
+	// Validate AEAD
+	if !cfg.aead.valid() {
+		return nil, ErrUnsupportedAEAD
+	}
+
+	// Validate blind index size
+	if cfg.blindIndexBytes < minBlindIndexBytes || cfg.blindIndexBytes > maxBlindIndexBytes {
+		return nil, ErrInvalidBlindIndexSize
+	}
+
+	// Validate blind index stretching
+	if cfg.blindIndexStretch < 1 {
+		return nil, ErrInvalidBlindIndexStretch
+	}
+
+	// Validate blind index pepper
+	if cfg.blindIndexPepper != nil && len(cfg.blindIndexPepper) < minBlindIndexPepperBytes {
+		return nil, ErrInvalidBlindIndexPepper
+	}
+
+	// Validate placeholder style
+	if !cfg.placeholderStyle.valid() {
+		return nil, ErrUnsupportedPlaceholderStyle
+	}
+
+	// Validate padding block size (PKCS#7 pads at most 255 bytes)
+	if cfg.paddingBlockSize != 0 && (cfg.paddingBlockSize < minPaddingBlockSize || cfg.paddingBlockSize > maxPaddingBlockSize) {
+		return nil, ErrInvalidPaddingBlockSize
+	}
+
+	// A derived nonce would repeat for the same value sealed on another day,
+	// under a different seal date
+	if cfg.timestampBinding && cfg.derivedNonce != nil {
+		return nil, ErrIncompatibleOptions
+	}
+
 	// Zero out master keys from config (they're no longer needed)
 	// Defer ensures this happens even if key derivation fails
 	defer func() {
@@ -101,20 +353,13 @@ func New(opts ...Option) (*Cipher, error) {
 	}()
 
 	// Derive keys for each master key (cache at initialization)
-	derivedKeysMap := make(map[string]*derivedKeys)
-	for keyID, masterKey := range cfg.keys {
-		dk, err := deriveKeys(masterKey)
-		if err != nil {
-			return nil, err
-		}
-		derivedKeysMap[keyID] = dk
+	ring, err := newKeyring(cfg.keys, cfg.defaultKeyID, cfg.hkdfSalt)
+	if err != nil {
+		return nil, err
 	}
 
-	c := &Cipher{
-		keys:      derivedKeysMap,
-		defaultID: cfg.defaultKeyID,
-		config:    cfg,
-	}
+	c := &Cipher{config: cfg}
+	c.ring.Store(ring)
 
 	return c, nil
 }
@@ -123,121 +368,428 @@ func New(opts ...Option) (*Cipher, error) {
 // Returns ciphertext with embedded key_id, or nil if plaintext is nil (NULL preservation).
 //
 // The ciphertext format is:
-// [flag:1][keyIDLen:1][keyID:n][nonce:24][secretbox(innerKeyID + plaintext)]
+// [flag:1][keyIDLen:1][keyID:n][nonce:N][aead(innerKeyID + plaintext)]
 func (c *Cipher) Seal(plaintext []byte) []byte {
-	if c.closed.Load() {
-		panic("encryptedcol: use of closed Cipher")
+	return c.SealAppend(nil, plaintext)
+}
+
+// SealAppend encrypts plaintext using the default key and appends the
+// ciphertext to dst, returning the extended slice (like the hash.Hash Sum API).
+// If dst has enough spare capacity, no output buffer is allocated.
+//
+// If plaintext is nil (NULL), dst is returned unchanged; callers that reuse a
+// buffer must check for nil plaintext themselves to record NULL.
+func (c *Cipher) SealAppend(dst, plaintext []byte) []byte {
+	r := c.mustAcquireWritable()
+	defer r.release()
+	if plaintext == nil {
+		return dst // NULL preservation
+	}
+	return c.sealWithKeyID(r, dst, r.defaultID, plaintext, nil)
+}
+
+// TrySeal is Seal that returns ErrCipherClosed after Close instead of panicking.
+// Use it where a Seal may race with shutdown, e.g. in request handlers.
+// Returns nil, nil if plaintext is nil (NULL preservation).
+func (c *Cipher) TrySeal(plaintext []byte) ([]byte, error) {
+	r, err := c.acquireWritable()
+	if err != nil {
+		return nil, err
 	}
+	defer r.release()
 	if plaintext == nil {
-		return nil // NULL preservation
+		return nil, nil // NULL preservation
 	}
-	return c.sealWithKeyID(c.defaultID, plaintext)
+	return c.sealWithKeyID(r, nil, r.defaultID, plaintext, nil), nil
 }
 
 // SealWithKey encrypts plaintext using a specific key version.
 func (c *Cipher) SealWithKey(keyID string, plaintext []byte) ([]byte, error) {
-	if c.closed.Load() {
-		return nil, ErrCipherClosed
+	r, err := c.acquireWritable()
+	if err != nil {
+		return nil, err
 	}
-	if _, ok := c.keys[keyID]; !ok {
+	defer r.release()
+	if _, ok := r.keys[keyID]; !ok {
 		return nil, ErrKeyNotFound
 	}
 	if plaintext == nil {
 		return nil, nil // NULL preservation
 	}
-	return c.sealWithKeyID(keyID, plaintext), nil
+	return c.sealWithKeyID(r, nil, keyID, plaintext, nil), nil
+}
+
+// SealNoCompress is Seal that never compresses, regardless of the Cipher's
+// compression settings. Use it for columns holding already-compressed data
+// (JPEGs, gzipped blobs), where trying zstd or snappy only costs CPU.
+// Open needs nothing special: the stored flag records that the value is uncompressed.
+// Returns nil if plaintext is nil (NULL preservation).
+func (c *Cipher) SealNoCompress(plaintext []byte) []byte {
+	r := c.mustAcquireWritable()
+	defer r.release()
+	if plaintext == nil {
+		return nil // NULL preservation
+	}
+
+	innerPlaintext := c.appendInner(nil, r.defaultID, plaintext)
+	nonce := c.sealNonce(r.defaultID, plaintext)
+	return c.sealPayload(r, nil, r.defaultID, innerPlaintext, innerPlaintext, flagNoCompression, nonce, nil)
 }
 
-// sealWithKeyID performs the actual encryption.
-func (c *Cipher) sealWithKeyID(keyID string, plaintext []byte) []byte {
-	keys := c.keys[keyID]
+// SealOpts overrides Cipher settings for one SealWithOptions call. Zero
+// fields keep the Cipher's setting.
+type SealOpts struct {
+	// KeyID is the key version to seal with ("" = default key).
+	KeyID string
+
+	// CompressionAlgorithm is "zstd", "snappy", "auto" or "none". Any value
+	// but "none" compresses even if the Cipher was built
+	// WithCompressionDisabled.
+	CompressionAlgorithm string
+
+	// CompressionThreshold is the minimum size in bytes before compression
+	// is attempted (0 = Cipher setting).
+	CompressionThreshold int
+}
+
+// SealWithOptions is Seal with per-call overrides, for columns whose data
+// compresses very differently from the Cipher's defaults (large JSON vs
+// random tokens) without a Cipher per column:
+//
+//	ct, err := cipher.SealWithOptions(doc, encryptedcol.SealOpts{CompressionAlgorithm: "zstd", CompressionThreshold: 256})
+//	ct, err = cipher.SealWithOptions(token, encryptedcol.SealOpts{CompressionAlgorithm: "none"})
+//
+// Open needs nothing special: the stored flag records the compression used.
+// Compression level, savings floor and size limit remain Cipher-wide.
+// Returns nil, nil if plaintext is nil (NULL preservation), ErrKeyNotFound for
+// an unknown KeyID and ErrUnsupportedCompression for an unknown algorithm.
+func (c *Cipher) SealWithOptions(plaintext []byte, opts SealOpts) ([]byte, error) {
+	algorithm, disabled := c.config.compressionAlgorithm, c.config.compressionDisabled
+	switch opts.CompressionAlgorithm {
+	case "":
+	case compressionAlgorithmNone:
+		disabled = true
+	case compressionAlgorithmZstd, compressionAlgorithmSnappy, compressionAlgorithmAuto:
+		algorithm, disabled = opts.CompressionAlgorithm, false
+	default:
+		return nil, ErrUnsupportedCompression
+	}
+	threshold := c.config.compressionThreshold
+	if opts.CompressionThreshold > 0 {
+		threshold = opts.CompressionThreshold
+	}
+
+	r, err := c.acquireWritable()
+	if err != nil {
+		return nil, err
+	}
+	defer r.release()
+	keyID := opts.KeyID
+	if keyID == "" {
+		keyID = r.defaultID
+	}
+	if _, ok := r.keys[keyID]; !ok {
+		return nil, ErrKeyNotFound
+	}
+	if plaintext == nil {
+		return nil, nil // NULL preservation
+	}
+
+	innerPlaintext := c.appendInner(nil, keyID, plaintext)
+	nonce := c.sealNonce(keyID, plaintext)
+	toEncrypt, compression := maybeCompress(
+		innerPlaintext,
+		threshold,
+		algorithm,
+		c.config.compressionLevel,
+		disabled,
+		c.config.maxDecompressedSize,
+		c.config.minCompressionSavings,
+	)
+	return c.sealPayload(r, nil, keyID, innerPlaintext, toEncrypt, compression, nonce, nil), nil
+}
 
+// sealWithKeyID performs the actual encryption, appending the ciphertext to dst.
+// A non-empty aad is bound to the ciphertext and recorded via flagAAD.
+func (c *Cipher) sealWithKeyID(r *keyring, dst []byte, keyID string, plaintext, aad []byte) []byte {
 	// Format inner plaintext with key_id for authentication
-	innerPlaintext := formatInnerPlaintext(keyID, plaintext)
+	innerPlaintext := c.appendInner(nil, keyID, plaintext)
+
+	// Pick a nonce sized for the configured AEAD
+	nonce := c.sealNonce(keyID, plaintext)
 
+	return c.sealInner(r, dst, keyID, innerPlaintext, nonce, aad)
+}
+
+// appendInner appends the inner plaintext for keyID to dst, with the seal
+// date when WithTimestampBinding is set and padding under WithPadding.
+func (c *Cipher) appendInner(dst []byte, keyID string, plaintext []byte) []byte {
+	var flags byte
+	if c.config.timestampBinding {
+		flags |= innerFlagSealDate
+	}
+	return c.appendInnerWith(dst, flags, keyID, plaintext)
+}
+
+// appendInnerWith is appendInner with explicit inner header flags;
+// innerFlagPadded is added under WithPadding.
+func (c *Cipher) appendInnerWith(dst []byte, flags byte, keyID string, plaintext []byte) []byte {
+	blockSize := c.config.paddingBlockSize
+	if blockSize > 0 {
+		flags |= innerFlagPadded
+	}
+	if dst == nil {
+		// Upper bound; the header and padding are usually smaller
+		size := 1 + len(keyID) + len(plaintext)
+		if flags != 0 {
+			size += innerHeaderSize + innerSealDateSize + blockSize
+		}
+		dst = make([]byte, 0, size)
+	}
+	if flags == 0 {
+		return appendInnerPlaintext(dst, keyID, plaintext)
+	}
+
+	start := len(dst)
+	var sealedAt time.Time
+	if flags&innerFlagSealDate != 0 {
+		sealedAt = c.now()
+	}
+	dst = appendInnerHeader(dst, flags, sealedAt)
+	dst = appendInnerPlaintext(dst, keyID, plaintext)
+	if flags&innerFlagPadded != 0 {
+		dst = appendPadding(dst, len(dst)-start, blockSize)
+	}
+	return dst
+}
+
+// now returns the seal time recorded by WithTimestampBinding.
+func (c *Cipher) now() time.Time {
+	if c.config.clock != nil {
+		return c.config.clock()
+	}
+	return time.Now()
+}
+
+// sealInner compresses and encrypts a formatted inner plaintext, appending
+// the complete outer ciphertext to dst.
+func (c *Cipher) sealInner(r *keyring, dst []byte, keyID string, innerPlaintext, nonce, aad []byte) []byte {
 	// Maybe compress
-	toEncrypt, flag := maybeCompress(
+	toEncrypt, compression := maybeCompress(
 		innerPlaintext,
 		c.config.compressionThreshold,
 		c.config.compressionAlgorithm,
+		c.config.compressionLevel,
 		c.config.compressionDisabled,
+		c.config.maxDecompressedSize,
+		c.config.minCompressionSavings,
 	)
+	return c.sealPayload(r, dst, keyID, innerPlaintext, toEncrypt, compression, nonce, aad)
+}
+
+// sealPayload encrypts toEncrypt, the inner plaintext after compression (as
+// recorded by the compression flag), appending the complete outer ciphertext to dst.
+func (c *Cipher) sealPayload(r *keyring, dst []byte, keyID string, innerPlaintext, toEncrypt []byte, compression byte, nonce, aad []byte) []byte {
+	keys := r.keys[keyID]
+	aead := c.config.aead
+	flag := flagFor(aead, compression)
+	if len(aad) > 0 {
+		flag |= flagAAD
+	}
+
+	// Size the output once: header + encrypted payload + tag
+	dst = slices.Grow(dst, headerSize(keyID, len(nonce))+len(toEncrypt)+aeadOverhead)
+
+	// Format outer header, then encrypt with the configured AEAD directly after it
+	start := len(dst)
+	dst = appendCiphertextHeader(dst, flag, keyID, nonce)
+	dst = keys.seal(dst, aead, nonce, toEncrypt, aad)
+
+	if c.config.observer != nil {
+		c.observeSeal(keyID, innerPlaintextLen(innerPlaintext, keyID), len(dst)-start)
+	}
+	return dst
+}
+
+// maxPooledDecryptBuffer caps the scratch buffers kept in decryptPool so a
+// single large value does not pin memory.
+const maxPooledDecryptBuffer = 64 * 1024
 
-	// Generate nonce
-	nonce := generateNonce()
+// decryptPool holds scratch buffers for decrypted inner plaintext.
+var decryptPool = sync.Pool{
+	New: func() any {
+		buf := make([]byte, 0, 1024)
+		return &buf
+	},
+}
 
-	// Encrypt with secretbox
-	encrypted := secretbox.Seal(nil, toEncrypt, &nonce, &keys.encryption)
+// testHookWiped, if set, is called with each intermediate plaintext buffer
+// right after it has been zeroed. Tests use it to verify wiping.
+var testHookWiped func([]byte)
 
-	// Format outer ciphertext
-	return formatCiphertext(flag, keyID, nonce, encrypted)
+// wipe zeros an intermediate buffer that held decrypted data.
+func wipe(buf []byte) {
+	clear(buf)
+	if testHookWiped != nil {
+		testHookWiped(buf)
+	}
 }
 
 // decryptAndVerify decrypts ciphertext with the given key and verifies the inner key ID.
 // This is the shared decryption logic used by Open() and OpenWithKey().
-func (c *Cipher) decryptAndVerify(keys *derivedKeys, encrypted []byte, nonce *[24]byte, flag byte, expectedKeyID string) ([]byte, error) {
-	// Decrypt
-	decrypted, ok := secretbox.Open(nil, encrypted, nonce, &keys.encryption)
+// The AEAD is selected per record from the flag byte. aad must be non-empty
+// exactly when the ciphertext was sealed with associated data.
+//
+// The plaintext is copied out and appended to dst (a new exactly-sized slice
+// if dst is nil); the intermediate decrypted and decompressed buffers, which
+// also hold the inner key ID, are zeroed before returning.
+func (c *Cipher) decryptAndVerify(dst []byte, keys *derivedKeys, encrypted []byte, nonce []byte, flag byte, expectedKeyID []byte, aad []byte) ([]byte, error) {
+	n := len(dst)
+	hdr, plaintext, err := c.decryptInner(dst, keys, encrypted, nonce, flag, aad)
+	if err != nil {
+		return nil, err
+	}
+
+	// Verify inner key_id matches expected (constant-time for defense-in-depth)
+	if subtle.ConstantTimeCompare([]byte(hdr.keyID), expectedKeyID) != 1 {
+		clear(plaintext[n:])
+		return nil, ErrKeyIDMismatch
+	}
+	return plaintext, nil
+}
+
+// decryptInner is decryptAndVerify without the inner key ID check.
+// Returns the inner header alongside the plaintext appended to dst.
+func (c *Cipher) decryptInner(dst []byte, keys *derivedKeys, encrypted []byte, nonce []byte, flag byte, aad []byte) (innerHeader, []byte, error) {
+	// AAD presence must match how the value was sealed
+	if hasAAD(flag) != (len(aad) > 0) {
+		return innerHeader{}, nil, ErrDecryptionFailed
+	}
+
+	// Decrypt into a pooled scratch buffer
+	bufp := decryptPool.Get().(*[]byte)
+	decrypted, ok := keys.open((*bufp)[:0], aeadFromFlag(flag), nonce, encrypted, aad)
 	if !ok {
-		return nil, ErrDecryptionFailed
+		decryptPool.Put(bufp)
+		return innerHeader{}, nil, ErrDecryptionFailed
 	}
+	defer func() {
+		wipe(decrypted)
+		if cap(decrypted) <= maxPooledDecryptBuffer {
+			*bufp = decrypted[:0]
+			decryptPool.Put(bufp)
+		}
+	}()
 
 	// Decompress if needed
-	decompressed, err := decompress(decrypted, flag)
+	compression := compressionFromFlag(flag)
+	decompressed, err := decompress(decrypted, compression, c.config.maxDecompressedSize)
 	if err != nil {
-		return nil, err
+		return innerHeader{}, nil, err
+	}
+	if compression != flagNoCompression {
+		defer wipe(decompressed)
 	}
 
-	// Parse inner plaintext and verify key_id
-	innerKeyID, actualPlaintext, err := parseInnerPlaintext(decompressed)
+	// Parse inner plaintext
+	hdr, actualPlaintext, err := parseInnerPlaintext(decompressed)
 	if err != nil {
-		return nil, err
+		return innerHeader{}, nil, err
 	}
 
-	// Verify inner key_id matches expected (constant-time for defense-in-depth)
-	if subtle.ConstantTimeCompare([]byte(innerKeyID), []byte(expectedKeyID)) != 1 {
-		return nil, ErrKeyIDMismatch
+	if dst == nil {
+		dst = make([]byte, 0, len(actualPlaintext))
 	}
-
-	return actualPlaintext, nil
+	return hdr, append(dst, actualPlaintext...), nil
 }
 
 // Open decrypts ciphertext, auto-detecting the key from embedded key_id.
 // Returns nil, nil if ciphertext is nil (NULL preservation).
 func (c *Cipher) Open(ciphertext []byte) ([]byte, error) {
-	if c.closed.Load() {
-		return nil, ErrCipherClosed
+	return c.openWithAAD(ciphertext, nil)
+}
+
+// OpenTo decrypts ciphertext and appends the plaintext to dst, returning the
+// extended slice. If dst has enough spare capacity, no output buffer is
+// allocated, which helps when scanning large result sets into a reused buffer.
+//
+// If ciphertext is nil (NULL), dst is returned unchanged with a nil error;
+// callers that reuse a buffer must check for nil ciphertext themselves to
+// record NULL. On error, the returned slice is nil.
+func (c *Cipher) OpenTo(dst, ciphertext []byte) ([]byte, error) {
+	if ciphertext == nil {
+		return dst, nil // NULL preservation
+	}
+	return c.openInto(dst, ciphertext, nil)
+}
+
+// openWithAAD is the shared implementation of Open and OpenWithAAD.
+func (c *Cipher) openWithAAD(ciphertext, aad []byte) ([]byte, error) {
+	return c.openInto(nil, ciphertext, aad)
+}
+
+// openInto decrypts ciphertext, appending the plaintext to dst.
+func (c *Cipher) openInto(dst, ciphertext, aad []byte) ([]byte, error) {
+	r, err := c.acquire()
+	if err != nil {
+		return nil, err
 	}
+	defer r.release()
 	if ciphertext == nil {
 		return nil, nil // NULL preservation
 	}
+	return c.openWithRing(r, dst, ciphertext, aad)
+}
+
+// openWithRing is openInto for callers that already hold a keyring.
+// ciphertext must not be nil.
+func (c *Cipher) openWithRing(r *keyring, dst, ciphertext, aad []byte) ([]byte, error) {
+	outerKeyID, plaintext, err := c.openOuter(r, dst, ciphertext, aad)
+	c.observeOpen(outerKeyID, err)
+	return plaintext, err
+}
 
+// openOuter parses and decrypts ciphertext, returning the embedded key ID
+// (nil if the format is invalid) alongside the plaintext.
+func (c *Cipher) openOuter(r *keyring, dst, ciphertext, aad []byte) (keyID []byte, plaintext []byte, err error) {
 	// Parse outer format
-	flag, outerKeyID, nonce, encrypted, err := parseFormat(ciphertext)
+	flag, outerKeyID, nonce, encrypted, err := parseFormatBytes(ciphertext)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	// Get the encryption key
-	keys, ok := c.keys[outerKeyID]
+	// Get the encryption key (string conversion in map index does not allocate)
+	keys, ok := r.keys[string(outerKeyID)]
 	if !ok {
-		return nil, ErrKeyNotFound
+		return outerKeyID, nil, keyNotFoundError(string(outerKeyID))
 	}
 
-	return c.decryptAndVerify(keys, encrypted, &nonce, flag, outerKeyID)
+	plaintext, err = c.decryptAndVerify(dst, keys, encrypted, nonce, flag, outerKeyID, aad)
+	return outerKeyID, plaintext, err
 }
 
 // OpenWithKey decrypts ciphertext using a specific key.
 // This can be used when the key_id is stored separately.
 func (c *Cipher) OpenWithKey(keyID string, ciphertext []byte) ([]byte, error) {
-	if c.closed.Load() {
-		return nil, ErrCipherClosed
+	r, err := c.acquire()
+	if err != nil {
+		return nil, err
 	}
+	defer r.release()
 	if ciphertext == nil {
 		return nil, nil
 	}
 
-	keys, ok := c.keys[keyID]
+	plaintext, err := c.openWithKey(r, keyID, ciphertext)
+	if obs := c.config.observer; obs != nil {
+		obs.OnOpen(keyID, err)
+	}
+	return plaintext, err
+}
+
+// openWithKey is the body of OpenWithKey. ciphertext must not be nil.
+func (c *Cipher) openWithKey(r *keyring, keyID string, ciphertext []byte) ([]byte, error) {
+	keys, ok := r.keys[keyID]
 	if !ok {
 		return nil, ErrKeyNotFound
 	}
@@ -253,40 +805,199 @@ func (c *Cipher) OpenWithKey(keyID string, ciphertext []byte) ([]byte, error) {
 		return nil, ErrKeyIDMismatch
 	}
 
-	return c.decryptAndVerify(keys, encrypted, &nonce, flag, keyID)
+	return c.decryptAndVerify(nil, keys, encrypted, nonce, flag, []byte(keyID), nil)
+}
+
+// OpenRaw decrypts ciphertext with the named key, ignoring the key_id in the
+// outer header, and returns the inner key_id alongside the plaintext without
+// checking it. The AEAD tag must still verify, so only data genuinely sealed
+// under keyID is returned.
+//
+// OpenRaw is a recovery-only escape hatch for ciphertexts whose header key_id
+// was corrupted in storage. It skips the key_id binding that Open and
+// OpenWithKey enforce, so never use it on a normal read path; compare the
+// returned innerKeyID with keyID to report the discrepancy, then re-seal.
+//
+// Returns nil, "", nil if ciphertext is nil (NULL preservation).
+func (c *Cipher) OpenRaw(keyID string, ciphertext []byte) (plaintext []byte, innerKeyID string, err error) {
+	r, err := c.acquire()
+	if err != nil {
+		return nil, "", err
+	}
+	defer r.release()
+	if ciphertext == nil {
+		return nil, "", nil
+	}
+
+	innerKeyID, plaintext, err = c.openRaw(r, keyID, ciphertext)
+	if obs := c.config.observer; obs != nil {
+		obs.OnOpen(keyID, err)
+	}
+	return plaintext, innerKeyID, err
+}
+
+// openRaw is the body of OpenRaw. ciphertext must not be nil.
+func (c *Cipher) openRaw(r *keyring, keyID string, ciphertext []byte) (string, []byte, error) {
+	keys, ok := r.keys[keyID]
+	if !ok {
+		return "", nil, ErrKeyNotFound
+	}
+
+	// Parse outer format; the outer key_id is deliberately ignored
+	flag, _, nonce, encrypted, err := parseFormatBytes(ciphertext)
+	if err != nil {
+		return "", nil, err
+	}
+
+	hdr, plaintext, err := c.decryptInner(nil, keys, encrypted, nonce, flag, nil)
+	return hdr.keyID, plaintext, err
+}
+
+// SealedAt returns the UTC date (midnight) on which ciphertext was sealed, as
+// recorded by WithTimestampBinding. It decrypts the value to read the date,
+// so the key must be available; the plaintext is discarded. Values sealed
+// without the option, and NULL, return the zero time and no error. Values
+// sealed with associated data cannot be checked and return ErrDecryptionFailed.
+func (c *Cipher) SealedAt(ciphertext []byte) (time.Time, error) {
+	r, err := c.acquire()
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer r.release()
+
+	if ciphertext == nil {
+		return time.Time{}, nil
+	}
+
+	flag, keyID, nonce, encrypted, err := parseFormatBytes(ciphertext)
+	if err != nil {
+		return time.Time{}, err
+	}
+	keys, ok := r.keys[string(keyID)]
+	if !ok {
+		return time.Time{}, keyNotFoundError(string(keyID))
+	}
+
+	hdr, plaintext, err := c.decryptInner(nil, keys, encrypted, nonce, flag, nil)
+	clear(plaintext)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if subtle.ConstantTimeCompare([]byte(hdr.keyID), keyID) != 1 {
+		return time.Time{}, ErrKeyIDMismatch
+	}
+	if hdr.sealedDays < 0 {
+		return time.Time{}, nil
+	}
+	return sealEpochBase.AddDate(0, 0, hdr.sealedDays), nil
 }
 
 // DefaultKeyID returns the current default key identifier.
 func (c *Cipher) DefaultKeyID() string {
-	return c.defaultID
+	// defaultID is immutable per keyring, so no lock is needed
+	return c.ring.Load().defaultID
+}
+
+// SealOverhead returns the bytes Seal adds to an uncompressed value with this
+// Cipher's AEAD and default key, as Overhead does for secretbox: sealed
+// values are len(plaintext) + SealOverhead() bytes unless compressed.
+// WithTimestampBinding adds 4 bytes. WithPadding is not included: padded
+// values round up to its block size instead.
+func (c *Cipher) SealOverhead() int {
+	n := sealOverhead(c.DefaultKeyID(), c.config.aead)
+	if c.config.timestampBinding {
+		n += innerHeaderSize + innerSealDateSize
+	}
+	return n
 }
 
 // ActiveKeyIDs returns all registered key identifiers, sorted alphabetically.
+// Returns an empty slice after Close.
 func (c *Cipher) ActiveKeyIDs() []string {
-	return sortedMapKeys(c.keys)
+	r, err := c.acquire()
+	if err != nil {
+		return []string{}
+	}
+	defer r.release()
+	return sortedMapKeys(r.keys)
+}
+
+// Fingerprint returns a hex-encoded SHA-256 over the default key ID and every
+// key ID with its key-check value, a dedicated HKDF output that reveals nothing
+// about the keys. Ciphers built from the same master keys, key IDs, default
+// key and WithHKDFSalt have the same fingerprint however they were
+// constructed, so replicas can compare fingerprints to detect config drift.
+//
+// Returns "" after Close.
+func (c *Cipher) Fingerprint() string {
+	r, err := c.acquire()
+	if err != nil {
+		return ""
+	}
+	defer r.release()
+
+	h := sha256.New()
+	writeField := func(b []byte) {
+		h.Write([]byte{byte(len(b))})
+		h.Write(b)
+	}
+	writeField([]byte(infoFingerprint))
+	writeField([]byte(r.defaultID))
+	for _, keyID := range sortedMapKeys(r.keys) {
+		writeField([]byte(keyID))
+		writeField(r.keys[keyID].check[:])
+	}
+	return hex.EncodeToString(h.Sum(nil))
 }
 
 // Close zeros out all key material from memory.
 // Call this when the Cipher is no longer needed to reduce key exposure window.
 // After calling Close, the Cipher is no longer usable.
+//
+// Close waits for in-flight operations to finish before zeroing keys, so it is
+// safe to call during graceful shutdown while requests are still running:
+// operations already in progress complete with intact keys, and later calls
+// fail with ErrCipherClosed (Seal and BlindIndex panic; use TrySeal and
+// TryBlindIndex where that can happen). Close is idempotent.
 func (c *Cipher) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.closed.Store(true)
-	for _, dk := range c.keys {
-		for i := range dk.encryption {
-			dk.encryption[i] = 0
-		}
-		for i := range dk.hmac {
-			dk.hmac[i] = 0
-		}
+	c.ring.Load().retire()
+}
+
+// sealNonce returns the nonce for sealing plaintext under keyID: from
+// WithDerivedNonce if set, otherwise a fresh one from newNonces.
+func (c *Cipher) sealNonce(keyID string, plaintext []byte) []byte {
+	derive := c.config.derivedNonce
+	if derive == nil {
+		return c.newNonces(1)
+	}
+	nonce := derive(keyID, plaintext)
+	return append([]byte(nil), nonce[:c.config.aead.nonceSize()]...)
+}
+
+// newNonces returns count concatenated nonces for the configured AEAD, read
+// from crypto/rand in a single call, or from WithNonceSource if set.
+func (c *Cipher) newNonces(count int) []byte {
+	size := c.config.aead.nonceSize()
+	src := c.config.nonceSource
+	if src == nil {
+		return generateNonce(size * count)
+	}
+	nonces := make([]byte, 0, size*count)
+	for i := 0; i < count; i++ {
+		n := src()
+		nonces = append(nonces, n[:size]...)
 	}
-	c.keys = nil
+	return nonces
 }
 
-// generateNonce generates a cryptographically secure random 24-byte nonce.
+// generateNonce generates a cryptographically secure random nonce of the given size.
 // Panics if the system's random source fails (unrecoverable).
-func generateNonce() [24]byte {
-	var nonce [24]byte
-	if _, err := rand.Read(nonce[:]); err != nil {
+func generateNonce(size int) []byte {
+	nonce := make([]byte, size)
+	if _, err := rand.Read(nonce); err != nil {
 		panic("crypto/rand failed: " + err.Error())
 	}
 	return nonce
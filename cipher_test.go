@@ -2,9 +2,12 @@ package encryptedcol
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
 	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	"golang.org/x/crypto/nacl/secretbox"
@@ -138,6 +141,142 @@ func TestSealOpen_MultiKey(t *testing.T) {
 	require.Equal(t, []byte("world"), pt2)
 }
 
+func TestSealAppend(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	prefix := []byte("row:")
+	buf := make([]byte, len(prefix), 256)
+	copy(buf, prefix)
+
+	out := cipher.SealAppend(buf, []byte("hello"))
+	require.Equal(t, prefix, out[:len(prefix)])
+	require.Equal(t, &buf[:1][0], &out[:1][0], "should reuse dst capacity")
+
+	pt, err := cipher.Open(out[len(prefix):])
+	require.NoError(t, err)
+	require.Equal(t, []byte("hello"), pt)
+
+	// Appending to a buffer without spare capacity still works
+	out = cipher.SealAppend(prefix, []byte(strings.Repeat("x", 2048)))
+	pt, err = cipher.Open(out[len(prefix):])
+	require.NoError(t, err)
+	require.Equal(t, strings.Repeat("x", 2048), string(pt))
+}
+
+func TestSealAppend_Null(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	buf := []byte("keep")
+	require.Equal(t, []byte("keep"), cipher.SealAppend(buf, nil))
+	require.Nil(t, cipher.SealAppend(nil, nil))
+}
+
+func TestSealAppend_ReusedBuffer(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	var buf []byte
+	for _, s := range []string{"a", "bb", "ccc"} {
+		buf = cipher.SealAppend(buf[:0], []byte(s))
+		pt, err := cipher.Open(buf)
+		require.NoError(t, err)
+		require.Equal(t, s, string(pt))
+	}
+}
+
+func TestOpen_WipesIntermediateBuffers(t *testing.T) {
+	var wiped [][]byte
+	testHookWiped = func(buf []byte) {
+		wiped = append(wiped, bytes.Clone(buf))
+	}
+	t.Cleanup(func() { testHookWiped = nil })
+
+	tests := []struct {
+		name      string
+		plaintext []byte
+		wipes     int
+	}{
+		{"uncompressed", []byte("secret"), 1},
+		{"compressed", []byte(strings.Repeat("secret ", 500)), 2}, // decrypted + decompressed
+	}
+
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wiped = nil
+			pt, err := cipher.Open(cipher.Seal(tt.plaintext))
+			require.NoError(t, err)
+			require.Equal(t, tt.plaintext, pt)
+
+			require.Len(t, wiped, tt.wipes)
+			for _, buf := range wiped {
+				require.NotEmpty(t, buf)
+				require.Equal(t, make([]byte, len(buf)), buf, "intermediate buffer not zeroed")
+			}
+		})
+	}
+}
+
+func TestOpen_ReturnsIndependentCopy(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	first, err := cipher.Open(cipher.Seal([]byte("first")))
+	require.NoError(t, err)
+
+	// Later opens reuse pooled scratch buffers; earlier results must be unaffected
+	for i := 0; i < 10; i++ {
+		_, err := cipher.Open(cipher.Seal([]byte("other value")))
+		require.NoError(t, err)
+	}
+	require.Equal(t, []byte("first"), first)
+	require.Equal(t, len(first), cap(first))
+}
+
+func TestOpenTo(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	buf := make([]byte, 0, 256)
+	buf = append(buf, "row:"...)
+
+	out, err := cipher.OpenTo(buf, cipher.Seal([]byte("hello")))
+	require.NoError(t, err)
+	require.Equal(t, "row:hello", string(out))
+	require.Equal(t, &buf[:1][0], &out[:1][0], "should reuse dst capacity")
+
+	// nil dst allocates
+	out, err = cipher.OpenTo(nil, cipher.Seal([]byte("hello")))
+	require.NoError(t, err)
+	require.Equal(t, []byte("hello"), out)
+
+	// Reused buffer across rows
+	var row []byte
+	for _, s := range []string{"a", "bb", strings.Repeat("c", 4096)} {
+		row, err = cipher.OpenTo(row[:0], cipher.Seal([]byte(s)))
+		require.NoError(t, err)
+		require.Equal(t, s, string(row))
+	}
+}
+
+func TestOpenTo_Null(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	buf := []byte("keep")
+	out, err := cipher.OpenTo(buf, nil)
+	require.NoError(t, err)
+	require.Equal(t, []byte("keep"), out)
+
+	out, err = cipher.OpenTo(nil, nil)
+	require.NoError(t, err)
+	require.Nil(t, out)
+}
+
+func TestOpenTo_Error(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	out, err := cipher.OpenTo([]byte("keep"), []byte{0x00})
+	require.ErrorIs(t, err, ErrInvalidFormat)
+	require.Nil(t, out)
+}
+
 func TestSealWithKey_NotFound(t *testing.T) {
 	cipher, err := New(WithKey("v1", testKey("v1")))
 	require.NoError(t, err)
@@ -155,6 +294,35 @@ func TestOpen_KeyNotFound(t *testing.T) {
 	// cipher2 doesn't have v1 key
 	_, err := cipher2.Open(ciphertext)
 	require.ErrorIs(t, err, ErrKeyNotFound)
+	require.EqualError(t, err, `encryptedcol: key not found: key_id="v1"`)
+}
+
+func TestOpen_KeyNotFound_SanitizedKeyID(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	tests := []struct {
+		name  string
+		keyID string
+		want  string
+	}{
+		{"control characters", "v1\n\x1b[31m", `key_id="v1\n\x1b[31m"`},
+		{"invalid utf-8", "v\xff", `key_id="v\xff"`},
+		{"bidi override", "v\u202e1", `key_id="v\u202e1"`},
+		{"non-ascii", "v\u00e9", `key_id="v\u00e9"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ciphertext := formatCiphertext(flagNoCompression, tt.keyID, make([]byte, secretboxNonceSize), make([]byte, 32))
+
+			_, err := cipher.Open(ciphertext)
+			require.ErrorIs(t, err, ErrKeyNotFound)
+			require.Contains(t, err.Error(), tt.want)
+			for _, b := range []byte(err.Error()) {
+				require.True(t, b >= 0x20 && b < 0x7f, "unescaped byte %#x in %q", b, err.Error())
+			}
+		})
+	}
 }
 
 func TestOpen_WrongKey(t *testing.T) {
@@ -232,6 +400,60 @@ func TestOpenWithKey_KeyNotFound(t *testing.T) {
 	require.ErrorIs(t, err, ErrKeyNotFound)
 }
 
+func TestOpenRaw_CorruptedHeaderKeyID(t *testing.T) {
+	cipher, _ := New(
+		WithKey("v1", testKey("v1")),
+		WithKey("v2", testKey("v2")),
+	)
+
+	ciphertext := cipher.Seal([]byte("recover me"))
+
+	// Corrupt the outer key_id: "v1" -> "v2"
+	corrupted := bytes.Clone(ciphertext)
+	corrupted[3] = '2'
+
+	_, err := cipher.Open(corrupted)
+	require.Error(t, err)
+	_, err = cipher.OpenWithKey("v1", corrupted)
+	require.ErrorIs(t, err, ErrKeyIDMismatch)
+
+	plaintext, innerKeyID, err := cipher.OpenRaw("v1", corrupted)
+	require.NoError(t, err)
+	require.Equal(t, []byte("recover me"), plaintext)
+	require.Equal(t, "v1", innerKeyID)
+}
+
+func TestOpenRaw_Errors(t *testing.T) {
+	cipher, _ := New(
+		WithKey("v1", testKey("v1")),
+		WithKey("v2", testKey("v2")),
+	)
+	ciphertext := cipher.Seal([]byte("test"))
+
+	// The MAC still has to verify under the named key
+	_, _, err := cipher.OpenRaw("v2", ciphertext)
+	require.ErrorIs(t, err, ErrDecryptionFailed)
+
+	_, _, err = cipher.OpenRaw("nonexistent", ciphertext)
+	require.ErrorIs(t, err, ErrKeyNotFound)
+
+	_, _, err = cipher.OpenRaw("v1", []byte{0x00})
+	require.ErrorIs(t, err, ErrInvalidFormat)
+
+	// AAD-bound values cannot be opened without their AAD
+	_, _, err = cipher.OpenRaw("v1", cipher.SealWithAAD([]byte("test"), []byte("row-1")))
+	require.ErrorIs(t, err, ErrDecryptionFailed)
+
+	plaintext, innerKeyID, err := cipher.OpenRaw("v1", nil)
+	require.NoError(t, err)
+	require.Nil(t, plaintext)
+	require.Empty(t, innerKeyID)
+
+	cipher.Close()
+	_, _, err = cipher.OpenRaw("v1", ciphertext)
+	require.ErrorIs(t, err, ErrCipherClosed)
+}
+
 func TestSealOpen_Concurrent(t *testing.T) {
 	cipher, _ := New(WithKey("v1", testKey("v1")))
 
@@ -318,10 +540,10 @@ func TestSealOpen_CompressionDisabled(t *testing.T) {
 }
 
 func TestGenerateNonce_Unique(t *testing.T) {
-	nonces := make(map[[24]byte]bool)
+	nonces := make(map[string]bool)
 
 	for i := 0; i < 1000; i++ {
-		nonce := generateNonce()
+		nonce := string(generateNonce(secretboxNonceSize))
 		require.False(t, nonces[nonce], "nonce collision detected")
 		nonces[nonce] = true
 	}
@@ -340,7 +562,7 @@ func TestClose(t *testing.T) {
 	cipher.Close()
 
 	// Keys should be nil after Close
-	require.Nil(t, cipher.keys)
+	require.Nil(t, cipher.ring.Load().keys)
 }
 
 func TestClose_UseAfterClose(t *testing.T) {
@@ -357,6 +579,14 @@ func TestClose_UseAfterClose(t *testing.T) {
 		cipher.Seal([]byte("test"))
 	})
 
+	// ...even for NULL, which is checked after the Cipher
+	require.Panics(t, func() { cipher.Seal(nil) })
+	require.Panics(t, func() { cipher.SealAppend([]byte("dst"), nil) })
+	require.Panics(t, func() { cipher.SealWithAAD(nil, []byte("aad")) })
+	require.Panics(t, func() { cipher.SealNoCompress(nil) })
+	require.Panics(t, func() { cipher.SealDeterministic(nil) })
+	require.Panics(t, func() { cipher.SealEnvelope(nil) })
+
 	// Open should return ErrCipherClosed
 	_, err = cipher.Open(ciphertext)
 	require.ErrorIs(t, err, ErrCipherClosed)
@@ -370,6 +600,110 @@ func TestClose_UseAfterClose(t *testing.T) {
 	require.ErrorIs(t, err, ErrCipherClosed)
 }
 
+func TestClose_ConcurrentWithOperations(t *testing.T) {
+	// Close during heavy use must never panic or return a wrong result:
+	// in-flight operations finish with intact keys, later ones get ErrCipherClosed
+	for _, aead := range []AEAD{AEADSecretbox, AEADAESGCM, AEADGCMSIV, AEADChaCha20Poly1305} {
+		cipher, err := New(WithKey("v1", testKey("v1")), WithAEAD(aead))
+		require.NoError(t, err)
+
+		sealed := cipher.SealString("sealed before close")
+		wantIdx := cipher.BlindIndexString("indexed")
+
+		var wg sync.WaitGroup
+		errs := make(chan error, 64)
+		start := make(chan struct{})
+		for g := 0; g < 64; g++ {
+			wg.Add(1)
+			go func(g int) {
+				defer wg.Done()
+				<-start
+				for i := 0; ; i++ {
+					plaintext := []byte(fmt.Sprintf("goroutine %d value %d", g, i))
+					ct, err := cipher.TrySeal(plaintext)
+					if errors.Is(err, ErrCipherClosed) {
+						return
+					}
+					if err != nil {
+						errs <- err
+						return
+					}
+					got, err := cipher.Open(ct)
+					if errors.Is(err, ErrCipherClosed) {
+						return
+					}
+					if err != nil || !bytes.Equal(got, plaintext) {
+						errs <- fmt.Errorf("round trip: %q, %v", got, err)
+						return
+					}
+					s, err := cipher.OpenString(sealed)
+					if errors.Is(err, ErrCipherClosed) {
+						return
+					}
+					if err != nil || s != "sealed before close" {
+						errs <- fmt.Errorf("open: %q, %v", s, err)
+						return
+					}
+					idx, err := cipher.TryBlindIndex([]byte("indexed"))
+					if errors.Is(err, ErrCipherClosed) {
+						return
+					}
+					if err != nil || !bytes.Equal(idx, wantIdx) {
+						errs <- fmt.Errorf("blind index: %x, %v", idx, err)
+						return
+					}
+				}
+			}(g)
+		}
+
+		close(start)
+		time.Sleep(20 * time.Millisecond)
+		cipher.Close()
+		wg.Wait()
+		close(errs)
+
+		for err := range errs {
+			t.Error(err)
+		}
+		_, err = cipher.TrySeal([]byte("after"))
+		require.ErrorIs(t, err, ErrCipherClosed)
+	}
+}
+
+func TestTrySeal(t *testing.T) {
+	cipher, err := New(WithKey("v1", testKey("v1")))
+	require.NoError(t, err)
+
+	ciphertext, err := cipher.TrySeal([]byte("test"))
+	require.NoError(t, err)
+	plaintext, err := cipher.Open(ciphertext)
+	require.NoError(t, err)
+	require.Equal(t, []byte("test"), plaintext)
+
+	idx, err := cipher.TryBlindIndex([]byte("test"))
+	require.NoError(t, err)
+	require.Equal(t, cipher.BlindIndex([]byte("test")), idx)
+
+	// NULL preservation
+	ciphertext, err = cipher.TrySeal(nil)
+	require.NoError(t, err)
+	require.Nil(t, ciphertext)
+	idx, err = cipher.TryBlindIndex(nil)
+	require.NoError(t, err)
+	require.Nil(t, idx)
+
+	cipher.Close()
+
+	// Errors instead of panics after Close
+	require.NotPanics(t, func() {
+		_, err = cipher.TrySeal([]byte("test"))
+		require.ErrorIs(t, err, ErrCipherClosed)
+
+		_, err = cipher.TryBlindIndex([]byte("test"))
+		require.ErrorIs(t, err, ErrCipherClosed)
+	})
+}
+
 func TestNew_InvalidKeyID_Empty(t *testing.T) {
 	_, err := New(WithKey("", testKey("v1")))
 	require.ErrorIs(t, err, ErrInvalidKeyID)
@@ -389,6 +723,102 @@ func TestNew_InvalidKeyID_MaxLength(t *testing.T) {
 	require.NotNil(t, cipher)
 }
 
+func TestNew_StrictKeyIDs(t *testing.T) {
+	tests := []struct {
+		name  string
+		keyID string
+		err   error
+	}{
+		{"simple", "v1", nil},
+		{"all allowed characters", "Key_2026-01.v2", nil},
+		{"space", "v 1", ErrInvalidKeyIDChars},
+		{"newline", "v1\n", ErrInvalidKeyIDChars},
+		{"control character", "v\x001", ErrInvalidKeyIDChars},
+		{"slash", "prod/v1", ErrInvalidKeyIDChars},
+		{"non-ascii", "v\u00e9", ErrInvalidKeyIDChars},
+		{"invalid utf-8", "v\xff", ErrInvalidKeyIDChars},
+		{"empty", "", ErrInvalidKeyID},
+		{"too long", strings.Repeat("x", 256), ErrInvalidKeyID},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(WithKey(tt.keyID, testKey("v1")), WithStrictKeyIDs())
+			if tt.err == nil {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestNew_StrictKeyIDs_OptIn(t *testing.T) {
+	// Without the option, any 1-255 byte key ID is accepted
+	cipher, err := New(WithKey("prod/v1 \n", testKey("v1")))
+	require.NoError(t, err)
+
+	pt, err := cipher.Open(cipher.Seal([]byte("x")))
+	require.NoError(t, err)
+	require.Equal(t, []byte("x"), pt)
+}
+
+func TestReloadKeys_StrictKeyIDs(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")), WithStrictKeyIDs())
+
+	provider := NewStaticKeyProvider("v 2", map[string][]byte{"v 2": testKey("v2")})
+	require.ErrorIs(t, cipher.ReloadKeys(provider), ErrInvalidKeyIDChars)
+	require.Equal(t, "v1", cipher.DefaultKeyID())
+}
+
+func TestNew_RejectWeakKeys(t *testing.T) {
+	tests := []struct {
+		name string
+		key  []byte
+		err  error
+	}{
+		{"random key", testKey("v1"), nil},
+		{"all zeros", make([]byte, 32), ErrWeakKey},
+		{"all 0xFF", bytes.Repeat([]byte{0xFF}, 32), ErrWeakKey},
+		{"single differing byte", append(make([]byte, 31), 0x01), nil},
+		{"short repeated key", make([]byte, 16), ErrInvalidKeySize},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(WithKey("v1", tt.key), WithRejectWeakKeys())
+			if tt.err == nil {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestNew_RejectWeakKeys_OptIn(t *testing.T) {
+	// Without the option, an all-zero key is accepted
+	_, err := New(WithKey("v1", make([]byte, 32)))
+	require.NoError(t, err)
+
+	// Every key is checked, not just the default
+	_, err = New(
+		WithKey("v1", make([]byte, 32)),
+		WithKey("v2", testKey("v2")),
+		WithDefaultKeyID("v2"),
+		WithRejectWeakKeys(),
+	)
+	require.ErrorIs(t, err, ErrWeakKey)
+}
+
+func TestReloadKeys_RejectWeakKeys(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")), WithRejectWeakKeys())
+
+	provider := NewStaticKeyProvider("v2", map[string][]byte{"v2": make([]byte, 32)})
+	require.ErrorIs(t, cipher.ReloadKeys(provider), ErrWeakKey)
+	require.Equal(t, "v1", cipher.DefaultKeyID())
+}
+
 func TestSealWithKey_NullPreservation(t *testing.T) {
 	cipher, err := New(WithKey("v1", testKey("v1")))
 	require.NoError(t, err)
@@ -422,7 +852,7 @@ func TestOpen_InvalidFlag(t *testing.T) {
 	require.ErrorIs(t, err, ErrInvalidFormat)
 }
 
-func TestOpen_SnappyFlagUnsupported(t *testing.T) {
+func TestOpen_SnappyFlagOnUncompressedData(t *testing.T) {
 	cipher, err := New(WithKey("v1", testKey("v1")))
 	require.NoError(t, err)
 
@@ -433,8 +863,9 @@ func TestOpen_SnappyFlagUnsupported(t *testing.T) {
 	copy(tampered, ciphertext)
 	tampered[0] = flagSnappy
 
+	// Inner payload is not valid snappy data
 	_, err = cipher.Open(tampered)
-	require.ErrorIs(t, err, ErrUnsupportedCompression)
+	require.ErrorIs(t, err, ErrDecompressionFailed)
 }
 
 func TestActiveKeyIDs_Sorted(t *testing.T) {
@@ -507,9 +938,9 @@ func TestOpen_InnerKeyIDMismatch(t *testing.T) {
 	innerPlaintext := formatInnerPlaintext(wrongInnerKeyID, plaintext)
 
 	// Encrypt with v1 key (correct key for outer header)
-	keys := cipher.keys["v1"]
-	nonce := generateNonce()
-	encrypted := secretbox.Seal(nil, innerPlaintext, &nonce, &keys.encryption)
+	keys := cipher.ring.Load().keys["v1"]
+	nonce := generateNonce(secretboxNonceSize)
+	encrypted := secretbox.Seal(nil, innerPlaintext, (*[24]byte)(nonce), &keys.encryption)
 
 	// Format outer ciphertext with v1 (so it passes key lookup)
 	ciphertext := formatCiphertext(flagNoCompression, "v1", nonce, encrypted)
@@ -524,8 +955,8 @@ func TestOpen_InnerKeyIDMismatch(t *testing.T) {
 func TestOpen_InvalidInnerPlaintext(t *testing.T) {
 	cipher, _ := New(WithKey("v1", testKey("v1")))
 
-	keys := cipher.keys["v1"]
-	nonce := generateNonce()
+	keys := cipher.ring.Load().keys["v1"]
+	nonce := generateNonce(secretboxNonceSize)
 
 	tests := []struct {
 		name         string
@@ -547,7 +978,7 @@ func TestOpen_InvalidInnerPlaintext(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// Encrypt the invalid inner payload
-			encrypted := secretbox.Seal(nil, tt.innerPayload, &nonce, &keys.encryption)
+			encrypted := secretbox.Seal(nil, tt.innerPayload, (*[24]byte)(nonce), &keys.encryption)
 			ciphertext := formatCiphertext(flagNoCompression, "v1", nonce, encrypted)
 
 			_, err := cipher.Open(ciphertext)
@@ -555,3 +986,81 @@ func TestOpen_InvalidInnerPlaintext(t *testing.T) {
 		})
 	}
 }
+
+func TestFingerprint(t *testing.T) {
+	keys := map[string][]byte{"v1": testKey("v1"), "v2": testKey("v2")}
+	base, err := New(WithKey("v1", testKey("v1")), WithKey("v2", testKey("v2")), WithDefaultKeyID("v2"))
+	require.NoError(t, err)
+	fp := base.Fingerprint()
+	require.Len(t, fp, 64)
+
+	// Same key material through a different constructor
+	fromProvider, err := NewWithProvider(NewStaticKeyProvider("v2", keys))
+	require.NoError(t, err)
+	require.Equal(t, fp, fromProvider.Fingerprint())
+
+	tests := []struct {
+		name string
+		opts []Option
+	}{
+		{"different default", []Option{WithKey("v1", testKey("v1")), WithKey("v2", testKey("v2"))}},
+		{"missing key", []Option{WithKey("v2", testKey("v2"))}},
+		{"different key", []Option{WithKey("v1", testKey("v1")), WithKey("v2", testKey("v3")), WithDefaultKeyID("v2")}},
+		{"renamed key", []Option{WithKey("v1", testKey("v1")), WithKey("v3", testKey("v2")), WithDefaultKeyID("v3")}},
+		{"salted", []Option{WithKey("v1", testKey("v1")), WithKey("v2", testKey("v2")), WithDefaultKeyID("v2"), WithHKDFSalt([]byte("x"))}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			other, err := New(tt.opts...)
+			require.NoError(t, err)
+			require.NotEqual(t, fp, other.Fingerprint())
+		})
+	}
+
+	// Options unrelated to keys do not affect the fingerprint
+	gcm, err := New(WithKey("v1", testKey("v1")), WithKey("v2", testKey("v2")), WithDefaultKeyID("v2"), WithAEAD(AEADAESGCM))
+	require.NoError(t, err)
+	require.Equal(t, fp, gcm.Fingerprint())
+
+	base.Close()
+	require.Empty(t, base.Fingerprint())
+}
+
+// FuzzOpen checks that opening attacker-supplied bytes returns an error rather
+// than panicking, for the outer format and, by sealing data as an
+// authenticated payload, for decompression and the inner format too.
+func FuzzOpen(f *testing.F) {
+	var ciphers []*Cipher
+	for _, aead := range []AEAD{AEADSecretbox, AEADAESGCM, AEADGCMSIV, AEADChaCha20Poly1305} {
+		cipher, err := New(WithKey("v1", testKey("v1")), WithAEAD(aead), WithTimestampBinding())
+		require.NoError(f, err)
+		ciphers = append(ciphers, cipher)
+	}
+	for _, cipher := range ciphers {
+		f.Add(cipher.Seal([]byte("hello")), byte(0))
+		f.Add(cipher.Seal(bytes.Repeat([]byte("compressible "), 100)), flagZstd)
+		f.Add(cipher.SealWithAAD([]byte("hello"), []byte("row-1")), byte(0))
+	}
+	f.Add(formatInnerPlaintext("v1", []byte("hello")), flagNoCompression)
+	zstdSeed, _ := compressZstd(formatInnerPlaintext("v1", []byte("hello")))
+	f.Add(zstdSeed, flagZstd)
+	f.Add(compressSnappy(formatInnerPlaintext("v1", []byte("hello"))), flagSnappy)
+
+	f.Fuzz(func(t *testing.T, data []byte, compression byte) {
+		for _, cipher := range ciphers {
+			_, _ = cipher.Open(data)
+			_, _ = cipher.OpenWithAAD(data, []byte("row-1"))
+			_, _ = cipher.OpenWithKey("v1", data)
+			_, _, _ = cipher.OpenRaw("v1", data)
+			_, _ = cipher.SealedAt(data)
+			_, _ = cipher.Peek(data)
+
+			r := cipher.mustAcquire()
+			nonce := make([]byte, cipher.config.aead.nonceSize())
+			forged := cipher.sealPayload(r, nil, "v1", nil, data, compression&flagCompressionMask, nonce, nil)
+			r.release()
+			_, _ = cipher.Open(forged)
+			_, _ = cipher.SealedAt(forged)
+		}
+	})
+}
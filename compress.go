@@ -3,17 +3,21 @@ package encryptedcol
 import (
 	"sync"
 
+	"github.com/golang/snappy"
 	"github.com/klauspost/compress/zstd"
 )
 
 // Default compression settings
 const (
-	defaultCompressionThreshold = 1024 // 1KB
-	minCompressionSavings       = 0.10 // 10% minimum savings to use compression
+	defaultCompressionThreshold  = 1024 // 1KB
+	defaultCompressionLevel      = int(zstd.SpeedDefault)
+	minCompressionLevel          = int(zstd.SpeedFastest)
+	maxCompressionLevel          = int(zstd.SpeedBestCompression)
+	defaultMinCompressionSavings = 0.10 // 10% minimum savings to use compression
 
-	// maxDecompressedSize is the maximum allowed decompressed size (64MB).
+	// maxDecompressedSize is the default maximum decompressed size (64MB).
 	// This prevents zip bomb attacks where a small compressed payload
-	// expands to consume all available memory.
+	// expands to consume all available memory. See WithMaxDecompressedSize.
 	maxDecompressedSize = 64 * 1024 * 1024
 )
 
@@ -21,6 +25,8 @@ const (
 const (
 	compressionAlgorithmZstd   = "zstd"
 	compressionAlgorithmSnappy = "snappy"
+	compressionAlgorithmAuto   = "auto" // Smaller of zstd and snappy per value
+	compressionAlgorithmNone   = "none" // SealOpts only: never compress
 )
 
 var (
@@ -29,6 +35,14 @@ var (
 	zstdDecoder *zstd.Decoder
 	zstdOnce    sync.Once
 	zstdErr     error
+
+	// zstdLevelEncoders holds lazily created encoders for non-default levels,
+	// indexed by zstd.EncoderLevel. Each is shared by all Ciphers using that level.
+	zstdLevelEncoders [maxCompressionLevel + 1]struct {
+		once    sync.Once
+		encoder *zstd.Encoder
+		err     error
+	}
 )
 
 // initZstd initializes the zstd encoder and decoder once.
@@ -48,10 +62,32 @@ func initZstd() (*zstd.Encoder, *zstd.Decoder, error) {
 	return zstdEncoder, zstdDecoder, zstdErr
 }
 
-// compressZstd compresses data using zstd.
+// zstdEncoderForLevel returns the shared encoder for the given level.
+// The default level uses the encoder from initZstd.
+func zstdEncoderForLevel(level int) (*zstd.Encoder, error) {
+	if level == defaultCompressionLevel {
+		encoder, _, err := initZstd()
+		return encoder, err
+	}
+	if level < minCompressionLevel || level > maxCompressionLevel {
+		return nil, ErrInvalidCompressionLevel
+	}
+	slot := &zstdLevelEncoders[level]
+	slot.once.Do(func() {
+		slot.encoder, slot.err = zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.EncoderLevel(level)))
+	})
+	return slot.encoder, slot.err
+}
+
+// compressZstd compresses data using zstd at the default level.
 // Returns the compressed data.
 func compressZstd(data []byte) ([]byte, error) {
-	encoder, _, err := initZstd()
+	return compressZstdLevel(data, defaultCompressionLevel)
+}
+
+// compressZstdLevel compresses data using zstd at the given encoder level.
+func compressZstdLevel(data []byte, level int) ([]byte, error) {
+	encoder, err := zstdEncoderForLevel(level)
 	if err != nil {
 		return nil, err
 	}
@@ -59,8 +95,8 @@ func compressZstd(data []byte) ([]byte, error) {
 }
 
 // decompressZstd decompresses zstd-compressed data.
-// Returns ErrDecompressionFailed if decompressed size exceeds maxDecompressedSize.
-func decompressZstd(data []byte) ([]byte, error) {
+// Returns ErrDecompressionFailed if decompressed size exceeds maxSize.
+func decompressZstd(data []byte, maxSize int) ([]byte, error) {
 	_, decoder, err := initZstd()
 	if err != nil {
 		return nil, err
@@ -69,55 +105,100 @@ func decompressZstd(data []byte) ([]byte, error) {
 	if err != nil {
 		return nil, ErrDecompressionFailed
 	}
-	if len(result) > maxDecompressedSize {
+	if len(result) > maxSize {
 		return nil, ErrDecompressionFailed
 	}
 	return result, nil
 }
 
-// maybeCompress compresses data if it exceeds the threshold and compression is beneficial.
-// Returns the (possibly compressed) data and the flag byte indicating compression status.
-func maybeCompress(data []byte, threshold int, algorithm string, disabled bool) ([]byte, byte) {
-	// Skip compression if disabled or below threshold
-	if disabled || len(data) < threshold {
-		return data, flagNoCompression
+// compressSnappy compresses data using snappy block format.
+func compressSnappy(data []byte) []byte {
+	return snappy.Encode(nil, data)
+}
+
+// decompressSnappy decompresses snappy-compressed data.
+// The decoded length is read from the block header and checked against
+// maxSize before any allocation.
+func decompressSnappy(data []byte, maxSize int) ([]byte, error) {
+	n, err := snappy.DecodedLen(data)
+	if err != nil || n > maxSize {
+		return nil, ErrDecompressionFailed
 	}
+	result, err := snappy.Decode(nil, data)
+	if err != nil {
+		return nil, ErrDecompressionFailed
+	}
+	return result, nil
+}
+
+// compressWith compresses data with the named algorithm.
+// level applies to zstd only; 0 selects the default level.
+// Returns the compressed data and its flag byte.
+func compressWith(data []byte, algorithm string, level int) ([]byte, byte, error) {
+	switch algorithm {
+	case compressionAlgorithmZstd:
+		if level == 0 {
+			level = defaultCompressionLevel
+		}
+		compressed, err := compressZstdLevel(data, level)
+		return compressed, flagZstd, err
+	case compressionAlgorithmSnappy:
+		return compressSnappy(data), flagSnappy, nil
+	case compressionAlgorithmAuto:
+		zstdData, _, err := compressWith(data, compressionAlgorithmZstd, level)
+		if err != nil {
+			return nil, flagNoCompression, err
+		}
+		if snappyData := compressSnappy(data); len(snappyData) < len(zstdData) {
+			return snappyData, flagSnappy, nil
+		}
+		return zstdData, flagZstd, nil
+	default:
+		return nil, flagNoCompression, ErrUnsupportedCompression
+	}
+}
 
-	// Only zstd is supported for now
-	if algorithm != compressionAlgorithmZstd {
+// maybeCompress compresses data if it exceeds the threshold and compression is beneficial.
+// level is the zstd encoder level (0 selects the default).
+// Data larger than maxSize, the decompression limit, is never compressed, so
+// every sealed value can be opened again under the same limit. Compressed
+// output is kept only if it saves at least minSavings (a fraction of the input).
+// Returns the (possibly compressed) data and the flag byte indicating compression status.
+func maybeCompress(data []byte, threshold int, algorithm string, level int, disabled bool, maxSize int, minSavings float64) ([]byte, byte) {
+	// Skip compression if disabled, below threshold, or too large to decompress
+	if disabled || len(data) < threshold || len(data) > maxSize {
 		return data, flagNoCompression
 	}
 
-	compressed, err := compressZstd(data)
+	compressed, flag, err := compressWith(data, algorithm, level)
 	if err != nil {
-		// If compression fails, return uncompressed
+		// Unsupported algorithm or compression failure: return uncompressed
 		return data, flagNoCompression
 	}
 
-	// Check if compression achieved minimum savings (10%)
+	// Check if compression achieved minimum savings
 	originalSize := len(data)
 	compressedSize := len(compressed)
 	savings := float64(originalSize-compressedSize) / float64(originalSize)
 
-	if savings < minCompressionSavings {
+	if savings < minSavings {
 		// Compression didn't save enough, use original
 		return data, flagNoCompression
 	}
 
-	return compressed, flagZstd
+	return compressed, flag
 }
 
 // decompress decompresses data based on the flag byte.
-func decompress(data []byte, flag byte) ([]byte, error) {
+// Returns ErrDecompressionFailed if the output would exceed maxSize.
+func decompress(data []byte, flag byte, maxSize int) ([]byte, error) {
 	switch flag {
 	case flagNoCompression:
 		return data, nil
 	case flagZstd:
-		return decompressZstd(data)
+		return decompressZstd(data, maxSize)
 	case flagSnappy:
-		// NOTE: Snappy is reserved for future implementation. The constant is
-		// defined to maintain forward compatibility in the ciphertext format.
-		return nil, ErrUnsupportedCompression
+		return decompressSnappy(data, maxSize)
 	default:
 		return nil, ErrInvalidFormat
 	}
@@ -2,6 +2,9 @@ package encryptedcol
 
 import (
 	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"math"
 	"strings"
 	"sync"
 	"testing"
@@ -26,7 +29,7 @@ func TestCompressZstd_RoundTrip(t *testing.T) {
 			compressed, err := compressZstd(tt.data)
 			require.NoError(t, err)
 
-			decompressed, err := decompressZstd(compressed)
+			decompressed, err := decompressZstd(compressed, maxDecompressedSize)
 			require.NoError(t, err)
 			require.True(t, bytes.Equal(tt.data, decompressed))
 		})
@@ -48,7 +51,7 @@ func TestMaybeCompress_BelowThreshold(t *testing.T) {
 	data := []byte("small")
 	threshold := 1024
 
-	result, flag := maybeCompress(data, threshold, compressionAlgorithmZstd, false)
+	result, flag := maybeCompress(data, threshold, compressionAlgorithmZstd, 0, false, maxDecompressedSize, defaultMinCompressionSavings)
 
 	require.Equal(t, flagNoCompression, flag)
 	require.True(t, bytes.Equal(data, result))
@@ -58,7 +61,7 @@ func TestMaybeCompress_AboveThreshold(t *testing.T) {
 	// Compressible data above threshold
 	data := []byte(strings.Repeat("hello world ", 200)) // ~2.4KB
 
-	result, flag := maybeCompress(data, 1024, compressionAlgorithmZstd, false)
+	result, flag := maybeCompress(data, 1024, compressionAlgorithmZstd, 0, false, maxDecompressedSize, defaultMinCompressionSavings)
 
 	require.Equal(t, flagZstd, flag)
 	require.Less(t, len(result), len(data), "compressed should be smaller")
@@ -67,7 +70,7 @@ func TestMaybeCompress_AboveThreshold(t *testing.T) {
 func TestMaybeCompress_Disabled(t *testing.T) {
 	data := []byte(strings.Repeat("hello world ", 200))
 
-	result, flag := maybeCompress(data, 1024, compressionAlgorithmZstd, true)
+	result, flag := maybeCompress(data, 1024, compressionAlgorithmZstd, 0, true, maxDecompressedSize, defaultMinCompressionSavings)
 
 	require.Equal(t, flagNoCompression, flag)
 	require.True(t, bytes.Equal(data, result))
@@ -80,7 +83,7 @@ func TestMaybeCompress_InsufficientSavings(t *testing.T) {
 		data[i] = byte(i * 17 % 256) // pseudo-random pattern
 	}
 
-	result, flag := maybeCompress(data, 1024, compressionAlgorithmZstd, false)
+	result, flag := maybeCompress(data, 1024, compressionAlgorithmZstd, 0, false, maxDecompressedSize, defaultMinCompressionSavings)
 
 	// If savings < 10%, should not compress
 	if flag == flagNoCompression {
@@ -88,14 +91,14 @@ func TestMaybeCompress_InsufficientSavings(t *testing.T) {
 	} else {
 		// If it did compress, verify savings >= 10%
 		savings := float64(len(data)-len(result)) / float64(len(data))
-		require.GreaterOrEqual(t, savings, minCompressionSavings)
+		require.GreaterOrEqual(t, savings, defaultMinCompressionSavings)
 	}
 }
 
 func TestMaybeCompress_UnsupportedAlgorithm(t *testing.T) {
 	data := []byte(strings.Repeat("hello ", 500))
 
-	result, flag := maybeCompress(data, 100, "unknown", false)
+	result, flag := maybeCompress(data, 100, "unknown", 0, false, maxDecompressedSize, defaultMinCompressionSavings)
 
 	require.Equal(t, flagNoCompression, flag)
 	require.True(t, bytes.Equal(data, result))
@@ -104,7 +107,7 @@ func TestMaybeCompress_UnsupportedAlgorithm(t *testing.T) {
 func TestDecompress_NoCompression(t *testing.T) {
 	data := []byte("uncompressed data")
 
-	result, err := decompress(data, flagNoCompression)
+	result, err := decompress(data, flagNoCompression, maxDecompressedSize)
 	require.NoError(t, err)
 	require.True(t, bytes.Equal(data, result))
 }
@@ -114,7 +117,7 @@ func TestDecompress_Zstd(t *testing.T) {
 	compressed, err := compressZstd(original)
 	require.NoError(t, err)
 
-	result, err := decompress(compressed, flagZstd)
+	result, err := decompress(compressed, flagZstd, maxDecompressedSize)
 	require.NoError(t, err)
 	require.True(t, bytes.Equal(original, result))
 }
@@ -122,23 +125,119 @@ func TestDecompress_Zstd(t *testing.T) {
 func TestDecompress_InvalidZstd(t *testing.T) {
 	invalidData := []byte("not valid zstd data")
 
-	_, err := decompress(invalidData, flagZstd)
+	_, err := decompress(invalidData, flagZstd, maxDecompressedSize)
 	require.ErrorIs(t, err, ErrDecompressionFailed)
 }
 
 func TestDecompress_UnknownFlag(t *testing.T) {
 	data := []byte("data")
 
-	_, err := decompress(data, 0xFF)
+	_, err := decompress(data, 0xFF, maxDecompressedSize)
 	require.ErrorIs(t, err, ErrInvalidFormat)
 }
 
 func TestDecompress_Snappy(t *testing.T) {
-	// Snappy is reserved but not implemented
-	data := []byte("data")
+	original := []byte("test data for compression")
+	compressed := compressSnappy(original)
 
-	_, err := decompress(data, flagSnappy)
-	require.ErrorIs(t, err, ErrUnsupportedCompression)
+	result, err := decompress(compressed, flagSnappy, maxDecompressedSize)
+	require.NoError(t, err)
+	require.True(t, bytes.Equal(original, result))
+}
+
+func TestDecompress_InvalidSnappy(t *testing.T) {
+	_, err := decompress([]byte{0xff, 0xff, 0xff}, flagSnappy, maxDecompressedSize)
+	require.ErrorIs(t, err, ErrDecompressionFailed)
+}
+
+func TestDecompressSnappy_ExceedsMaxSize(t *testing.T) {
+	// Header claims a decoded length above maxDecompressedSize
+	header := binary.AppendUvarint(nil, uint64(maxDecompressedSize+1))
+
+	_, err := decompressSnappy(append(header, 0x00), maxDecompressedSize)
+	require.ErrorIs(t, err, ErrDecompressionFailed)
+}
+
+func TestMaybeCompress_Snappy(t *testing.T) {
+	data := []byte(strings.Repeat("hello world ", 200))
+
+	result, flag := maybeCompress(data, 1024, compressionAlgorithmSnappy, 0, false, maxDecompressedSize, defaultMinCompressionSavings)
+
+	require.Equal(t, flagSnappy, flag)
+	require.Less(t, len(result), len(data), "compressed should be smaller")
+
+	decompressed, err := decompress(result, flag, maxDecompressedSize)
+	require.NoError(t, err)
+	require.True(t, bytes.Equal(data, decompressed))
+}
+
+func TestMaybeCompress_Auto(t *testing.T) {
+	inputs := map[string][]byte{
+		"repetitive": []byte(strings.Repeat("hello world ", 200)),
+		"json":       []byte(strings.Repeat(`{"id":12345,"name":"Alice","email":"alice@example.com","tags":["a","b"]},`, 30)),
+	}
+
+	for name, data := range inputs {
+		t.Run(name, func(t *testing.T) {
+			result, flag := maybeCompress(data, 1024, compressionAlgorithmAuto, 0, false, maxDecompressedSize, defaultMinCompressionSavings)
+
+			zstdData, _ := compressZstd(data)
+			snappyData := compressSnappy(data)
+			if len(snappyData) < len(zstdData) {
+				require.Equal(t, flagSnappy, flag)
+				require.Equal(t, snappyData, result)
+			} else {
+				require.Equal(t, flagZstd, flag)
+				require.Equal(t, zstdData, result)
+			}
+
+			decompressed, err := decompress(result, flag, maxDecompressedSize)
+			require.NoError(t, err)
+			require.True(t, bytes.Equal(data, decompressed))
+		})
+	}
+
+	// Incompressible data stays uncompressed
+	random := make([]byte, 2048)
+	_, _ = rand.Read(random)
+	_, flag := maybeCompress(random, 1024, compressionAlgorithmAuto, 0, false, maxDecompressedSize, defaultMinCompressionSavings)
+	require.Equal(t, flagNoCompression, flag)
+}
+
+func TestAutoCipher_RoundTrip(t *testing.T) {
+	data := []byte(strings.Repeat("auto mode data ", 200))
+
+	cipher, err := New(WithKey("v1", testKey("v1")), WithCompressionAlgorithm("auto"))
+	require.NoError(t, err)
+
+	ciphertext := cipher.Seal(data)
+	require.Contains(t, []byte{flagZstd, flagSnappy}, compressionFromFlag(ciphertext[0]))
+
+	// Any cipher reads auto output, regardless of its own algorithm
+	zstdCipher, _ := New(WithKey("v1", testKey("v1")))
+	decrypted, err := zstdCipher.Open(ciphertext)
+	require.NoError(t, err)
+	require.True(t, bytes.Equal(data, decrypted))
+}
+
+func TestSnappyCipher_ReadsZstd(t *testing.T) {
+	data := []byte(strings.Repeat("mixed algorithm data ", 200))
+
+	zstdCipher, _ := New(WithKey("v1", testKey("v1")))
+	snappyCipher, _ := New(WithKey("v1", testKey("v1")), WithCompressionAlgorithm("snappy"))
+
+	zstdCiphertext := zstdCipher.Seal(data)
+	snappyCiphertext := snappyCipher.Seal(data)
+	require.Equal(t, flagZstd, zstdCiphertext[0])
+	require.Equal(t, flagSnappy, snappyCiphertext[0])
+
+	for _, c := range []*Cipher{zstdCipher, snappyCipher} {
+		for _, ct := range [][]byte{zstdCiphertext, snappyCiphertext} {
+			decrypted, err := c.Open(ct)
+			require.NoError(t, err)
+			require.True(t, bytes.Equal(data, decrypted))
+		}
+	}
 }
 
 func TestCompressZstd_Concurrent(t *testing.T) {
@@ -159,7 +258,7 @@ func TestCompressZstd_Concurrent(t *testing.T) {
 				return
 			}
 
-			decompressed, err := decompressZstd(compressed)
+			decompressed, err := decompressZstd(compressed, maxDecompressedSize)
 			if err != nil {
 				errors <- err
 				return
@@ -186,7 +285,7 @@ func TestMaybeCompress_ExactThreshold(t *testing.T) {
 		data[i] = 'a' // Compressible
 	}
 
-	result, flag := maybeCompress(data, 1024, compressionAlgorithmZstd, false)
+	result, flag := maybeCompress(data, 1024, compressionAlgorithmZstd, 0, false, maxDecompressedSize, defaultMinCompressionSavings)
 
 	// At exactly threshold, should attempt compression
 	require.Equal(t, flagZstd, flag, "at threshold should compress")
@@ -199,8 +298,274 @@ func TestMaybeCompress_JustBelowThreshold(t *testing.T) {
 		data[i] = 'a'
 	}
 
-	result, flag := maybeCompress(data, 1024, compressionAlgorithmZstd, false)
+	result, flag := maybeCompress(data, 1024, compressionAlgorithmZstd, 0, false, maxDecompressedSize, defaultMinCompressionSavings)
 
 	require.Equal(t, flagNoCompression, flag, "below threshold should not compress")
 	require.True(t, bytes.Equal(data, result))
 }
+
+func TestCompressZstdLevel_RoundTrip(t *testing.T) {
+	data := []byte(strings.Repeat("level test data ", 500))
+
+	for level := minCompressionLevel; level <= maxCompressionLevel; level++ {
+		compressed, err := compressZstdLevel(data, level)
+		require.NoError(t, err)
+
+		decompressed, err := decompressZstd(compressed, maxDecompressedSize)
+		require.NoError(t, err)
+		require.True(t, bytes.Equal(data, decompressed), "level %d", level)
+	}
+}
+
+func TestCompressZstdLevel_Invalid(t *testing.T) {
+	for _, level := range []int{-1, 0, 5} {
+		_, err := compressZstdLevel([]byte("data"), level)
+		require.ErrorIs(t, err, ErrInvalidCompressionLevel, "level %d", level)
+	}
+}
+
+func TestCompressZstdLevel_Concurrent(t *testing.T) {
+	// Per-level encoders are shared and must be safe for concurrent use
+	data := []byte(strings.Repeat("concurrent level data ", 100))
+
+	var wg sync.WaitGroup
+	errors := make(chan error, 100)
+
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(level int) {
+			defer wg.Done()
+
+			compressed, err := compressZstdLevel(data, level)
+			if err != nil {
+				errors <- err
+				return
+			}
+
+			decompressed, err := decompressZstd(compressed, maxDecompressedSize)
+			if err != nil {
+				errors <- err
+				return
+			}
+
+			if !bytes.Equal(data, decompressed) {
+				errors <- ErrDecompressionFailed
+			}
+		}(minCompressionLevel + i%maxCompressionLevel)
+	}
+
+	wg.Wait()
+	close(errors)
+
+	for err := range errors {
+		t.Fatalf("concurrent compression error: %v", err)
+	}
+}
+
+func TestMaybeCompress_AboveMaxSize(t *testing.T) {
+	data := []byte(strings.Repeat("hello world ", 200))
+
+	// Values that could not be decompressed under the limit stay uncompressed
+	result, flag := maybeCompress(data, 1024, compressionAlgorithmZstd, 0, false, len(data)-1, defaultMinCompressionSavings)
+	require.Equal(t, flagNoCompression, flag)
+	require.Equal(t, data, result)
+
+	_, flag = maybeCompress(data, 1024, compressionAlgorithmZstd, 0, false, len(data), defaultMinCompressionSavings)
+	require.Equal(t, flagZstd, flag)
+}
+
+func TestDecompress_ExceedsMaxSize(t *testing.T) {
+	data := []byte(strings.Repeat("hello world ", 200))
+
+	for _, algorithm := range []string{compressionAlgorithmZstd, compressionAlgorithmSnappy} {
+		compressed, flag, err := compressWith(data, algorithm, 0)
+		require.NoError(t, err)
+
+		_, err = decompress(compressed, flag, len(data)-1)
+		require.ErrorIs(t, err, ErrDecompressionFailed, algorithm)
+
+		result, err := decompress(compressed, flag, len(data))
+		require.NoError(t, err)
+		require.True(t, bytes.Equal(data, result))
+	}
+}
+
+func TestWithMaxDecompressedSize(t *testing.T) {
+	data := []byte(strings.Repeat("hello world ", 1000)) // 12KB, compressible
+
+	small, err := New(WithKey("v1", testKey("v1")), WithMaxDecompressedSize(4096))
+	require.NoError(t, err)
+	def, _ := New(WithKey("v1", testKey("v1")))
+
+	// Sealed uncompressed under the small limit, and readable everywhere
+	ct := small.Seal(data)
+	require.Equal(t, flagNoCompression, compressionFromFlag(ct[0]))
+	for _, c := range []*Cipher{small, def} {
+		result, err := c.Open(ct)
+		require.NoError(t, err)
+		require.True(t, bytes.Equal(data, result))
+	}
+
+	// A value compressed under a larger limit exceeds the small one
+	ct = def.Seal(data)
+	require.Equal(t, flagZstd, compressionFromFlag(ct[0]))
+	_, err = small.Open(ct)
+	require.ErrorIs(t, err, ErrDecompressionFailed)
+
+	for _, n := range []int{0, -1} {
+		_, err := New(WithKey("v1", testKey("v1")), WithMaxDecompressedSize(n))
+		require.ErrorIs(t, err, ErrInvalidMaxDecompressedSize)
+	}
+}
+
+func TestMaybeCompress_MinSavingsBoundary(t *testing.T) {
+	data := []byte(strings.Repeat("hello world ", 200))
+	compressed, _, err := compressWith(data, compressionAlgorithmZstd, 0)
+	require.NoError(t, err)
+	savings := float64(len(data)-len(compressed)) / float64(len(data))
+
+	// Savings exactly at the floor compress; just above it they don't
+	result, flag := maybeCompress(data, 1024, compressionAlgorithmZstd, 0, false, maxDecompressedSize, savings)
+	require.Equal(t, flagZstd, flag)
+	require.Equal(t, compressed, result)
+
+	result, flag = maybeCompress(data, 1024, compressionAlgorithmZstd, 0, false, maxDecompressedSize, math.Nextafter(savings, 1))
+	require.Equal(t, flagNoCompression, flag)
+	require.Equal(t, data, result)
+}
+
+func TestWithMinCompressionSavings(t *testing.T) {
+	// Random bytes with a compressible tail: zstd saves roughly 20%
+	data := make([]byte, 4096)
+	_, err := rand.Read(data[:3200])
+	require.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		ratio float64
+		want  byte
+	}{
+		{"archive accepts small savings", 0.03, flagZstd},
+		{"default", defaultMinCompressionSavings, flagZstd},
+		{"latency-sensitive requires more", 0.30, flagNoCompression},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cipher, err := New(WithKey("v1", testKey("v1")), WithMinCompressionSavings(tt.ratio))
+			require.NoError(t, err)
+
+			ct := cipher.Seal(data)
+			require.Equal(t, tt.want, compressionFromFlag(ct[0]))
+
+			result, err := cipher.Open(ct)
+			require.NoError(t, err)
+			require.True(t, bytes.Equal(data, result))
+		})
+	}
+}
+
+func TestWithMinCompressionSavings_Invalid(t *testing.T) {
+	for _, ratio := range []float64{0, 1, -0.1, 1.5, math.NaN(), math.Inf(1)} {
+		_, err := New(WithKey("v1", testKey("v1")), WithMinCompressionSavings(ratio))
+		require.ErrorIs(t, err, ErrInvalidMinCompressionSavings, "ratio=%v", ratio)
+	}
+}
+
+func TestSealNoCompress(t *testing.T) {
+	data := []byte(strings.Repeat("compressible data ", 200))
+
+	for _, aead := range []AEAD{AEADSecretbox, AEADAESGCM, AEADGCMSIV, AEADChaCha20Poly1305} {
+		cipher, _ := New(WithKey("v1", testKey("v1")), WithAEAD(aead), WithCompressionAlgorithm(compressionAlgorithmAuto))
+
+		// The same cipher compresses with Seal
+		require.NotEqual(t, flagNoCompression, compressionFromFlag(cipher.Seal(data)[0]))
+
+		ct := cipher.SealNoCompress(data)
+		require.Equal(t, flagFor(aead, flagNoCompression), ct[0])
+		require.Equal(t, headerSize("v1", aead.nonceSize())+1+len("v1")+len(data)+aeadOverhead, len(ct))
+
+		result, err := cipher.Open(ct)
+		require.NoError(t, err)
+		require.True(t, bytes.Equal(data, result))
+	}
+}
+
+func TestSealNoCompress_Null(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+	require.Nil(t, cipher.SealNoCompress(nil))
+
+	ct := cipher.SealNoCompress([]byte{})
+	result, err := cipher.Open(ct)
+	require.NoError(t, err)
+	require.Empty(t, result)
+
+	cipher.Close()
+	require.Panics(t, func() { cipher.SealNoCompress([]byte("x")) })
+}
+
+func TestSealWithOptions(t *testing.T) {
+	data := []byte(strings.Repeat("compressible data ", 20)) // 360 bytes, below the default threshold
+	cipher, _ := New(
+		WithKey("v1", testKey("v1")),
+		WithKey("v2", testKey("v2")),
+		WithDefaultKeyID("v2"),
+	)
+
+	tests := []struct {
+		name  string
+		opts  SealOpts
+		keyID string
+		flag  byte
+	}{
+		{"zero opts behave like Seal", SealOpts{}, "v2", flagNoCompression},
+		{"lower threshold", SealOpts{CompressionThreshold: 64}, "v2", flagZstd},
+		{"snappy", SealOpts{CompressionAlgorithm: "snappy", CompressionThreshold: 64}, "v2", flagSnappy},
+		{"none", SealOpts{CompressionAlgorithm: "none", CompressionThreshold: 64}, "v2", flagNoCompression},
+		{"key override", SealOpts{KeyID: "v1", CompressionThreshold: 64}, "v1", flagZstd},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ct, err := cipher.SealWithOptions(data, tt.opts)
+			require.NoError(t, err)
+			info, err := cipher.Peek(ct)
+			require.NoError(t, err)
+			require.Equal(t, tt.keyID, info.KeyID)
+			require.Equal(t, tt.flag, compressionFromFlag(ct[0]))
+
+			result, err := cipher.Open(ct)
+			require.NoError(t, err)
+			require.Equal(t, data, result)
+		})
+	}
+
+	// An explicit algorithm overrides WithCompressionDisabled; a threshold alone does not
+	disabled, _ := New(WithKey("v1", testKey("v1")), WithCompressionDisabled())
+	ct, err := disabled.SealWithOptions(data, SealOpts{CompressionAlgorithm: "zstd", CompressionThreshold: 64})
+	require.NoError(t, err)
+	require.Equal(t, flagZstd, compressionFromFlag(ct[0]))
+	ct, err = disabled.SealWithOptions(data, SealOpts{CompressionThreshold: 64})
+	require.NoError(t, err)
+	require.Equal(t, flagNoCompression, compressionFromFlag(ct[0]))
+}
+
+func TestSealWithOptions_NullAndErrors(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	ct, err := cipher.SealWithOptions(nil, SealOpts{})
+	require.NoError(t, err)
+	require.Nil(t, ct)
+
+	_, err = cipher.SealWithOptions([]byte("x"), SealOpts{KeyID: "v9"})
+	require.ErrorIs(t, err, ErrKeyNotFound)
+	_, err = cipher.SealWithOptions([]byte("x"), SealOpts{CompressionAlgorithm: "lz4"})
+	require.ErrorIs(t, err, ErrUnsupportedCompression)
+
+	reader, _ := New(WithKey("v1", testKey("v1")), WithReadOnly())
+	_, err = reader.SealWithOptions([]byte("x"), SealOpts{})
+	require.ErrorIs(t, err, ErrReadOnly)
+
+	cipher.Close()
+	_, err = cipher.SealWithOptions([]byte("x"), SealOpts{})
+	require.ErrorIs(t, err, ErrCipherClosed)
+}
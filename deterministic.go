@@ -0,0 +1,63 @@
+package encryptedcol
+
+// SealDeterministic encrypts plaintext with the default key so that identical
+// plaintext always yields identical ciphertext under the same key.
+// Returns nil if plaintext is nil (NULL preservation).
+//
+// WARNING: deterministic ciphertext leaks equality. Anyone with database access
+// can see which rows share a value (and count how often it occurs). Use it only
+// for columns that need equality joins or lookups without a separate _idx column;
+// prefer Seal plus a blind index otherwise.
+//
+// With WithAEAD(AEADGCMSIV), the nonce is fixed (all zeros) and AES-GCM-SIV's
+// own synthetic IV makes the output deterministic. With other AEADs, the nonce
+// is synthetic (SIV-style): an HMAC-SHA256 of the key ID and plaintext under a
+// key derived with its own HKDF info string ("encryptedcol-deterministic-nonce"),
+// truncated to the AEAD nonce size.
+// The output uses the standard format, so Open decrypts it unchanged.
+//
+// Ciphertexts only match when produced by the same key version, AEAD, and
+// compression settings. After key rotation, search with each active key
+// version via SealDeterministicWithKey.
+func (c *Cipher) SealDeterministic(plaintext []byte) []byte {
+	r := c.mustAcquireWritable()
+	defer r.release()
+	if plaintext == nil {
+		return nil // NULL preservation
+	}
+	return c.sealDeterministic(r, r.defaultID, plaintext)
+}
+
+// SealDeterministicWithKey is SealDeterministic with a specific key version.
+func (c *Cipher) SealDeterministicWithKey(keyID string, plaintext []byte) ([]byte, error) {
+	r, err := c.acquireWritable()
+	if err != nil {
+		return nil, err
+	}
+	defer r.release()
+	if _, ok := r.keys[keyID]; !ok {
+		return nil, ErrKeyNotFound
+	}
+	if plaintext == nil {
+		return nil, nil // NULL preservation
+	}
+	return c.sealDeterministic(r, keyID, plaintext), nil
+}
+
+// sealDeterministic encrypts with a fixed nonce under AES-GCM-SIV, or a nonce
+// derived from the inner plaintext otherwise.
+func (c *Cipher) sealDeterministic(r *keyring, keyID string, plaintext []byte) []byte {
+	// Never dated, which would make the output change daily
+	innerPlaintext := c.appendInnerWith(nil, 0, keyID, plaintext)
+
+	if c.config.aead == AEADGCMSIV {
+		// GCM-SIV derives its IV from the plaintext; a fixed nonce is safe
+		return c.sealInner(r, nil, keyID, innerPlaintext, make([]byte, gcmSIVNonceSize), nil)
+	}
+
+	// Inner plaintext includes the key ID, so the nonce is bound to it as well
+	mac := computeHMACWithKey(&r.keys[keyID].siv, innerPlaintext)
+	nonce := mac[:c.config.aead.nonceSize()]
+
+	return c.sealInner(r, nil, keyID, innerPlaintext, nonce, nil)
+}
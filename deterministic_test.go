@@ -0,0 +1,113 @@
+package encryptedcol
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSealDeterministic_RoundTrip(t *testing.T) {
+	for _, aead := range []AEAD{AEADSecretbox, AEADAESGCM, AEADGCMSIV, AEADChaCha20Poly1305} {
+		cipher, err := New(WithKey("v1", testKey("v1")), WithAEAD(aead))
+		require.NoError(t, err)
+
+		for _, pt := range [][]byte{{}, []byte("alice@example.com"), []byte(strings.Repeat("x", 4096))} {
+			ct := cipher.SealDeterministic(pt)
+			got, err := cipher.Open(ct)
+			require.NoError(t, err)
+			require.Equal(t, pt, got)
+		}
+	}
+}
+
+func TestSealDeterministic_Deterministic(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	ct1 := cipher.SealDeterministic([]byte("alice"))
+	ct2 := cipher.SealDeterministic([]byte("alice"))
+	require.Equal(t, ct1, ct2)
+
+	ct3 := cipher.SealDeterministic([]byte("bob"))
+	require.NotEqual(t, ct1, ct3)
+
+	// Same result from an independently constructed Cipher
+	cipher2, _ := New(WithKey("v1", testKey("v1")))
+	require.Equal(t, ct1, cipher2.SealDeterministic([]byte("alice")))
+}
+
+func TestSealDeterministic_GCMSIV(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")), WithAEAD(AEADGCMSIV))
+
+	ct1 := cipher.SealDeterministic([]byte("alice"))
+	require.Equal(t, ct1, cipher.SealDeterministic([]byte("alice")))
+	require.NotEqual(t, ct1, cipher.SealDeterministic([]byte("bob")))
+	require.False(t, bytes.Equal(ct1, cipher.Seal([]byte("alice"))))
+
+	// Fixed nonce; GCM-SIV's synthetic IV provides the determinism
+	flag, _, nonce, _, err := parseFormat(ct1)
+	require.NoError(t, err)
+	require.Equal(t, AEADGCMSIV, aeadFromFlag(flag))
+	require.Equal(t, make([]byte, gcmSIVNonceSize), nonce)
+
+	// Not interchangeable with the synthetic-nonce construction
+	sb, _ := New(WithKey("v1", testKey("v1")))
+	require.NotEqual(t, sb.SealDeterministic([]byte("alice")), ct1)
+
+	got, err := sb.Open(ct1)
+	require.NoError(t, err)
+	require.Equal(t, []byte("alice"), got)
+}
+
+func TestSealDeterministic_DiffersFromRandomized(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	det := cipher.SealDeterministic([]byte("alice"))
+	require.False(t, bytes.Equal(det, cipher.Seal([]byte("alice"))))
+
+	// Synthetic nonce key is separate from the blind index key
+	dk := cipher.ring.Load().keys["v1"]
+	require.NotEqual(t, dk.hmac, dk.siv)
+	require.NotEqual(t, dk.encryption, dk.siv)
+}
+
+func TestSealDeterministic_KeyVersions(t *testing.T) {
+	cipher, _ := New(
+		WithKey("v1", testKey("v1")),
+		WithKey("v2", testKey("v2")),
+		WithDefaultKeyID("v2"),
+	)
+
+	v1, err := cipher.SealDeterministicWithKey("v1", []byte("alice"))
+	require.NoError(t, err)
+	v2 := cipher.SealDeterministic([]byte("alice"))
+	require.NotEqual(t, v1, v2)
+
+	again, err := cipher.SealDeterministicWithKey("v2", []byte("alice"))
+	require.NoError(t, err)
+	require.Equal(t, v2, again)
+
+	_, err = cipher.SealDeterministicWithKey("v9", []byte("alice"))
+	require.ErrorIs(t, err, ErrKeyNotFound)
+}
+
+func TestSealDeterministic_Null(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+	require.Nil(t, cipher.SealDeterministic(nil))
+
+	ct, err := cipher.SealDeterministicWithKey("v1", nil)
+	require.NoError(t, err)
+	require.Nil(t, ct)
+}
+
+func TestSealDeterministic_Closed(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+	cipher.Close()
+
+	require.Panics(t, func() {
+		cipher.SealDeterministic([]byte("alice"))
+	})
+	_, err := cipher.SealDeterministicWithKey("v1", []byte("alice"))
+	require.ErrorIs(t, err, ErrCipherClosed)
+}
@@ -0,0 +1,79 @@
+package encryptedcol
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// NewFromEnv creates a Cipher from hex-encoded master keys in environment
+// variables named {prefix}KEY_{id}. The default key ID is read from
+// {prefix}DEFAULT_KEY_ID; it may be omitted when exactly one key is set.
+// opts are applied after the keys, for the remaining configuration.
+//
+// Key IDs are taken verbatim from the variable name (case-sensitive), so
+// ENCCOL_KEY_v1 registers key "v1":
+//
+//	ENCCOL_KEY_v1=<64 hex chars>
+//	ENCCOL_KEY_v2=<64 hex chars>
+//	ENCCOL_DEFAULT_KEY_ID=v2
+//
+//	cipher, err := encryptedcol.NewFromEnv("ENCCOL_")
+//
+// Keys are parsed with ParseKeyHex. Errors name the offending variable and wrap
+// ErrNoKeys, ErrInvalidKeyID, ErrInvalidKeySize or ErrDefaultKeyNotFound;
+// key values are never included.
+func NewFromEnv(prefix string, opts ...Option) (*Cipher, error) {
+	keyPrefix := prefix + "KEY_"
+	defaultVar := prefix + "DEFAULT_KEY_ID"
+
+	keys := make(map[string][]byte)
+	// Zero decoded master keys once New has copied them
+	defer func() {
+		for _, key := range keys {
+			clear(key)
+		}
+	}()
+
+	for _, kv := range os.Environ() {
+		name, value, _ := strings.Cut(kv, "=")
+		keyID, ok := strings.CutPrefix(name, keyPrefix)
+		if !ok {
+			continue
+		}
+		if keyID == "" {
+			return nil, fmt.Errorf("%w: %s has no key ID", ErrInvalidKeyID, name)
+		}
+		key, err := ParseKeyHex(value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s must be %d hex-encoded bytes", err, name, masterKeySize)
+		}
+		keys[keyID] = key
+	}
+
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("%w: no %s* environment variables set", ErrNoKeys, keyPrefix)
+	}
+
+	defaultID, ok := os.LookupEnv(defaultVar)
+	switch {
+	case ok:
+		if _, found := keys[defaultID]; !found {
+			return nil, fmt.Errorf("%w: %s=%q has no matching %s%s", ErrDefaultKeyNotFound, defaultVar, defaultID, keyPrefix, defaultID)
+		}
+	case len(keys) == 1:
+		for keyID := range keys {
+			defaultID = keyID
+		}
+	default:
+		return nil, fmt.Errorf("%w: %s must be set when several keys are configured", ErrDefaultKeyNotFound, defaultVar)
+	}
+
+	envOpts := make([]Option, 0, len(keys)+1+len(opts))
+	for _, keyID := range sortedMapKeys(keys) {
+		envOpts = append(envOpts, WithKey(keyID, keys[keyID]))
+	}
+	envOpts = append(envOpts, WithDefaultKeyID(defaultID))
+
+	return New(append(envOpts, opts...)...)
+}
@@ -0,0 +1,71 @@
+package encryptedcol
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewFromEnv(t *testing.T) {
+	t.Setenv("TESTENC_KEY_v1", hex.EncodeToString(testKey("v1")))
+	t.Setenv("TESTENC_KEY_v2", hex.EncodeToString(testKey("v2"))+"\n")
+	t.Setenv("TESTENC_DEFAULT_KEY_ID", "v2")
+
+	cipher, err := NewFromEnv("TESTENC_")
+	require.NoError(t, err)
+	require.Equal(t, "v2", cipher.DefaultKeyID())
+	require.Equal(t, []string{"v1", "v2"}, cipher.ActiveKeyIDs())
+
+	// Same keys as WithKey
+	direct, _ := New(WithKey("v1", testKey("v1")), WithKey("v2", testKey("v2")), WithDefaultKeyID("v2"))
+	s, err := direct.OpenString(cipher.SealString("hello"))
+	require.NoError(t, err)
+	require.Equal(t, "hello", s)
+}
+
+func TestNewFromEnv_SingleKeyDefault(t *testing.T) {
+	t.Setenv("TESTENC_KEY_prod", strings.ToUpper(hex.EncodeToString(testKey("prod"))))
+
+	cipher, err := NewFromEnv("TESTENC_", WithAEAD(AEADAESGCM))
+	require.NoError(t, err)
+	require.Equal(t, "prod", cipher.DefaultKeyID())
+	require.Equal(t, flagFor(AEADAESGCM, flagNoCompression), cipher.Seal([]byte("x"))[0])
+}
+
+func TestNewFromEnv_Errors(t *testing.T) {
+	validKey := hex.EncodeToString(testKey("v1"))
+
+	tests := []struct {
+		name string
+		env  map[string]string
+		err  error
+		msg  string
+	}{
+		{"no keys", nil, ErrNoKeys, "TESTENC_KEY_"},
+		{"invalid hex", map[string]string{"TESTENC_KEY_v1": "not-hex"}, ErrInvalidKeySize, "TESTENC_KEY_v1"},
+		{"short key", map[string]string{"TESTENC_KEY_v1": "abcd"}, ErrInvalidKeySize, "TESTENC_KEY_v1"},
+		{"empty key ID", map[string]string{"TESTENC_KEY_": validKey}, ErrInvalidKeyID, "TESTENC_KEY_"},
+		{"missing default", map[string]string{
+			"TESTENC_KEY_v1": validKey,
+			"TESTENC_KEY_v2": validKey,
+		}, ErrDefaultKeyNotFound, "TESTENC_DEFAULT_KEY_ID"},
+		{"unknown default", map[string]string{
+			"TESTENC_KEY_v1":         validKey,
+			"TESTENC_DEFAULT_KEY_ID": "v9",
+		}, ErrDefaultKeyNotFound, "v9"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			_, err := NewFromEnv("TESTENC_")
+			require.ErrorIs(t, err, tt.err)
+			require.Contains(t, err.Error(), tt.msg)
+			require.NotContains(t, err.Error(), validKey)
+		})
+	}
+}
@@ -0,0 +1,215 @@
+package encryptedcol
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// Envelope format:
+// [wrappedLen:2][wrappedDEK:wrappedLen][payload]
+//
+// wrappedDEK is a regular ciphertext (see format.go) of the 32-byte data key,
+// sealed with a master-derived key. payload is:
+// [flag:1][nonce:N][aead_DEK(plaintext)]
+//
+// The payload flag uses the same AEAD/compression layout as the outer format.
+
+// dekSize is the size of a per-record data encryption key.
+const dekSize = 32
+
+// SealEnvelope encrypts plaintext with a fresh random 32-byte data key (DEK),
+// then wraps the DEK with the default master key.
+// Returns nil if plaintext is nil (NULL preservation).
+//
+// Envelope encryption limits master-key exposure to small DEKs and allows
+// master key rotation via RewrapEnvelope without re-encrypting large payloads.
+// Envelopes are not interchangeable with Seal output; use OpenEnvelope.
+func (c *Cipher) SealEnvelope(plaintext []byte) []byte {
+	if plaintext == nil {
+		c.mustAcquireWritable().release() // Panics after Close, as Seal does
+		return nil                        // NULL preservation
+	}
+
+	dek := generateDEK()
+	defer zeroKey(&dek)
+
+	wrapped := c.Seal(dek[:])
+
+	aead := c.config.aead
+	dataKeys, err := newDataKeys(&dek, aead)
+	if err != nil {
+		// AES key setup only fails on invalid key sizes, which cannot happen here
+		panic("encryptedcol: internal error: " + err.Error())
+	}
+	defer zeroKey(&dataKeys.encryption)
+
+	toEncrypt, compression := maybeCompress(
+		plaintext,
+		c.config.compressionThreshold,
+		c.config.compressionAlgorithm,
+		c.config.compressionLevel,
+		c.config.compressionDisabled,
+		c.config.maxDecompressedSize,
+		c.config.minCompressionSavings,
+	)
+	nonce := c.newNonces(1)
+
+	size := 2 + len(wrapped) + 1 + len(nonce) + len(toEncrypt) + aeadOverhead
+	result := make([]byte, 0, size)
+	result = binary.BigEndian.AppendUint16(result, uint16(len(wrapped)))
+	result = append(result, wrapped...)
+	result = append(result, flagFor(aead, compression))
+	result = append(result, nonce...)
+	return dataKeys.seal(result, aead, nonce, toEncrypt, nil)
+}
+
+// OpenEnvelope decrypts an envelope produced by SealEnvelope.
+// Returns nil, nil if envelope is nil (NULL preservation).
+func (c *Cipher) OpenEnvelope(envelope []byte) ([]byte, error) {
+	if envelope == nil {
+		return nil, nil // NULL preservation
+	}
+
+	wrapped, payload, err := parseEnvelope(envelope)
+	if err != nil {
+		return nil, err
+	}
+
+	dekBytes, err := c.Open(wrapped)
+	if err != nil {
+		return nil, err
+	}
+	if len(dekBytes) != dekSize {
+		return nil, ErrInvalidFormat
+	}
+	var dek [dekSize]byte
+	copy(dek[:], dekBytes)
+	for i := range dekBytes {
+		dekBytes[i] = 0
+	}
+	defer zeroKey(&dek)
+
+	flag := payload[0]
+	aead := aeadFromFlag(flag)
+	if !aead.valid() || hasAAD(flag) {
+		return nil, ErrInvalidFormat
+	}
+	nonceLen := aead.nonceSize()
+	if len(payload) < 1+nonceLen+1 {
+		return nil, ErrInvalidFormat
+	}
+	nonce := payload[1 : 1+nonceLen]
+
+	dataKeys, err := newDataKeys(&dek, aead)
+	if err != nil {
+		return nil, err
+	}
+	defer zeroKey(&dataKeys.encryption)
+
+	decrypted, ok := dataKeys.open(nil, aead, nonce, payload[1+nonceLen:], nil)
+	if !ok {
+		return nil, ErrDecryptionFailed
+	}
+	compression := compressionFromFlag(flag)
+	if compression == flagNoCompression {
+		return decrypted, nil
+	}
+
+	// The compressed plaintext is only an intermediate copy
+	defer wipe(decrypted)
+	return decompress(decrypted, compression, c.config.maxDecompressedSize)
+}
+
+// RewrapEnvelope re-wraps an envelope's data key with the current default key.
+// The encrypted payload is copied unchanged, so this is cheap even for large values.
+// Returns nil, nil if envelope is nil (NULL stays NULL).
+func (c *Cipher) RewrapEnvelope(envelope []byte) ([]byte, error) {
+	if err := c.checkWritable(); err != nil {
+		return nil, err
+	}
+	if envelope == nil {
+		return nil, nil
+	}
+
+	wrapped, payload, err := parseEnvelope(envelope)
+	if err != nil {
+		return nil, err
+	}
+
+	dek, err := c.Open(wrapped)
+	if err != nil {
+		return nil, err
+	}
+	if len(dek) != dekSize {
+		return nil, ErrInvalidFormat
+	}
+	defer func() {
+		for i := range dek {
+			dek[i] = 0
+		}
+	}()
+
+	rewrapped := c.Seal(dek)
+	c.observeRotate(wrapped, rewrapped)
+
+	result := make([]byte, 0, 2+len(rewrapped)+len(payload))
+	result = binary.BigEndian.AppendUint16(result, uint16(len(rewrapped)))
+	result = append(result, rewrapped...)
+	return append(result, payload...), nil
+}
+
+// EnvelopeKeyID extracts the key_id that wraps an envelope's data key, without decrypting.
+// Returns empty string and nil error for nil envelope.
+func (c *Cipher) EnvelopeKeyID(envelope []byte) (string, error) {
+	if envelope == nil {
+		return "", nil
+	}
+	wrapped, _, err := parseEnvelope(envelope)
+	if err != nil {
+		return "", err
+	}
+	return c.ExtractKeyID(wrapped)
+}
+
+// parseEnvelope splits an envelope into the wrapped DEK and the payload.
+func parseEnvelope(envelope []byte) (wrapped, payload []byte, err error) {
+	if len(envelope) < 2 {
+		return nil, nil, ErrInvalidFormat
+	}
+	wrappedLen := int(binary.BigEndian.Uint16(envelope[:2]))
+	if wrappedLen == 0 || len(envelope) < 2+wrappedLen+1 {
+		return nil, nil, ErrInvalidFormat
+	}
+	return envelope[2 : 2+wrappedLen], envelope[2+wrappedLen:], nil
+}
+
+// generateDEK generates a random data encryption key.
+// Panics if the system's random source fails (unrecoverable).
+func generateDEK() [dekSize]byte {
+	var dek [dekSize]byte
+	if _, err := rand.Read(dek[:]); err != nil {
+		panic("crypto/rand failed: " + err.Error())
+	}
+	return dek
+}
+
+// newDataKeys builds state for a data key with only the AEAD it is used with.
+// The DEK is used directly (no HKDF): each DEK encrypts exactly one payload.
+func newDataKeys(dek *[dekSize]byte, aead AEAD) (*derivedKeys, error) {
+	keys := &derivedKeys{encryption: *dek}
+	var err error
+	switch aead {
+	case AEADAESGCM:
+		keys.gcm, err = newAESGCM(dek)
+	case AEADGCMSIV:
+		keys.gcmsiv, err = newAESGCMSIV(dek[:])
+	case AEADChaCha20Poly1305:
+		keys.chacha, err = chacha20poly1305.New(dek[:])
+	}
+	if err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
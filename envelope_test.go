@@ -0,0 +1,184 @@
+package encryptedcol
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSealEnvelope_OpenEnvelope(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      []Option
+		plaintext []byte
+	}{
+		{"small", nil, []byte("hello")},
+		{"empty", nil, []byte{}},
+		{"compressible", nil, []byte(strings.Repeat("large blob ", 2000))},
+		{"aes-gcm", []Option{WithAEAD(AEADAESGCM)}, []byte("hello")},
+		{"aes-gcm-siv", []Option{WithAEAD(AEADGCMSIV)}, []byte("hello")},
+		{"chacha20-poly1305", []Option{WithAEAD(AEADChaCha20Poly1305)}, []byte("hello")},
+		{"snappy", []Option{WithCompressionAlgorithm("snappy")}, []byte(strings.Repeat("x", 4096))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := append([]Option{WithKey("v1", testKey("v1"))}, tt.opts...)
+			cipher, err := New(opts...)
+			require.NoError(t, err)
+
+			envelope := cipher.SealEnvelope(tt.plaintext)
+			result, err := cipher.OpenEnvelope(envelope)
+			require.NoError(t, err)
+			require.True(t, bytes.Equal(tt.plaintext, result))
+		})
+	}
+}
+
+func TestSealEnvelope_Null(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	require.Nil(t, cipher.SealEnvelope(nil))
+
+	result, err := cipher.OpenEnvelope(nil)
+	require.NoError(t, err)
+	require.Nil(t, result)
+
+	rewrapped, err := cipher.RewrapEnvelope(nil)
+	require.NoError(t, err)
+	require.Nil(t, rewrapped)
+}
+
+func TestSealEnvelope_UniqueDataKeys(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	a := cipher.SealEnvelope([]byte("same"))
+	b := cipher.SealEnvelope([]byte("same"))
+	require.NotEqual(t, a, b)
+}
+
+func TestRewrapEnvelope(t *testing.T) {
+	old, _ := New(WithKey("v1", testKey("v1")))
+	rotated, _ := New(
+		WithKey("v1", testKey("v1")),
+		WithKey("v2", testKey("v2")),
+		WithDefaultKeyID("v2"),
+	)
+
+	plaintext := []byte(strings.Repeat("multi-megabyte payload ", 1000))
+	envelope := old.SealEnvelope(plaintext)
+
+	keyID, err := rotated.EnvelopeKeyID(envelope)
+	require.NoError(t, err)
+	require.Equal(t, "v1", keyID)
+
+	rewrapped, err := rotated.RewrapEnvelope(envelope)
+	require.NoError(t, err)
+
+	keyID, err = rotated.EnvelopeKeyID(rewrapped)
+	require.NoError(t, err)
+	require.Equal(t, "v2", keyID)
+
+	// Payload bytes are carried over unchanged
+	_, oldPayload, _ := parseEnvelope(envelope)
+	_, newPayload, _ := parseEnvelope(rewrapped)
+	require.Equal(t, oldPayload, newPayload)
+
+	result, err := rotated.OpenEnvelope(rewrapped)
+	require.NoError(t, err)
+	require.Equal(t, plaintext, result)
+
+	// A cipher with only v2 can read the rewrapped envelope
+	v2Only, _ := New(WithKey("v2", testKey("v2")))
+	result, err = v2Only.OpenEnvelope(rewrapped)
+	require.NoError(t, err)
+	require.Equal(t, plaintext, result)
+}
+
+func TestOpenEnvelope_Tampered(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	envelope := cipher.SealEnvelope([]byte("secret"))
+	envelope[len(envelope)-1] ^= 0xFF
+
+	_, err := cipher.OpenEnvelope(envelope)
+	require.ErrorIs(t, err, ErrDecryptionFailed)
+}
+
+func TestOpenEnvelope_SwappedPayload(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	a := cipher.SealEnvelope([]byte("payload a"))
+	b := cipher.SealEnvelope([]byte("payload b"))
+
+	wrappedA, _, _ := parseEnvelope(a)
+	_, payloadB, _ := parseEnvelope(b)
+	mixed := append(a[:2+len(wrappedA):2+len(wrappedA)], payloadB...)
+
+	_, err := cipher.OpenEnvelope(mixed)
+	require.ErrorIs(t, err, ErrDecryptionFailed)
+}
+
+func TestOpenEnvelope_Malformed(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	tests := []struct {
+		name     string
+		envelope []byte
+	}{
+		{"empty", []byte{}},
+		{"one byte", []byte{0x00}},
+		{"zero wrapped length", []byte{0x00, 0x00, 0x01}},
+		{"wrapped length exceeds data", []byte{0x00, 0x50, 0x01, 0x02}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := cipher.OpenEnvelope(tt.envelope)
+			require.ErrorIs(t, err, ErrInvalidFormat)
+		})
+	}
+}
+
+func TestOpenEnvelope_NotAnEnvelope(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	_, err := cipher.OpenEnvelope(cipher.Seal([]byte("regular ciphertext")))
+	require.Error(t, err)
+}
+
+func TestOpenEnvelope_WipesCompressedPayload(t *testing.T) {
+	var wiped [][]byte
+	testHookWiped = func(buf []byte) {
+		wiped = append(wiped, bytes.Clone(buf))
+	}
+	t.Cleanup(func() { testHookWiped = nil })
+
+	tests := []struct {
+		name      string
+		plaintext []byte
+		wipes     int
+	}{
+		{"uncompressed", []byte("secret"), 1},                     // wrapped data key
+		{"compressed", []byte(strings.Repeat("secret ", 500)), 2}, // wrapped data key + compressed payload
+	}
+
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			envelope := cipher.SealEnvelope(tt.plaintext)
+			wiped = nil
+			pt, err := cipher.OpenEnvelope(envelope)
+			require.NoError(t, err)
+			require.Equal(t, tt.plaintext, pt)
+
+			require.Len(t, wiped, tt.wipes)
+			for _, buf := range wiped {
+				require.NotEmpty(t, buf)
+				require.Equal(t, make([]byte, len(buf)), buf, "intermediate buffer not zeroed")
+			}
+		})
+	}
+}
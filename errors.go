@@ -1,6 +1,9 @@
 package encryptedcol
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
 	// ErrDecryptionFailed indicates secretbox authentication failed (wrong key or corrupted data).
@@ -31,12 +34,94 @@ var (
 	// ErrDefaultKeyNotFound indicates the specified default key ID was not found.
 	ErrDefaultKeyNotFound = errors.New("encryptedcol: default key not found")
 
+	// ErrNoDefaultKey indicates WithStrictDefault was set but WithDefaultKeyID was not.
+	ErrNoDefaultKey = errors.New("encryptedcol: no default key ID set")
+
+	// ErrDuplicateKeyID indicates the same key ID was registered more than once in New.
+	ErrDuplicateKeyID = errors.New("encryptedcol: duplicate key ID")
+
 	// ErrInvalidKeyID indicates the key ID is invalid (empty or too long).
 	ErrInvalidKeyID = errors.New("encryptedcol: key ID must be 1-255 bytes")
 
+	// ErrInvalidKeyIDChars indicates a key ID with characters outside [A-Za-z0-9._-]
+	// (only checked with WithStrictKeyIDs).
+	ErrInvalidKeyIDChars = errors.New("encryptedcol: key ID may only contain A-Z, a-z, 0-9, '.', '_' and '-'")
+
+	// ErrInvalidMaxDecompressedSize indicates WithMaxDecompressedSize was given a non-positive size.
+	ErrInvalidMaxDecompressedSize = errors.New("encryptedcol: max decompressed size must be positive")
+
+	// ErrInvalidMinCompressionSavings indicates WithMinCompressionSavings was given a ratio outside (0, 1).
+	ErrInvalidMinCompressionSavings = errors.New("encryptedcol: min compression savings must be between 0 and 1")
+
+	// ErrWeakKey indicates a master key made of a single repeated byte, such as
+	// all zeros (only checked with WithRejectWeakKeys).
+	ErrWeakKey = errors.New("encryptedcol: master key is a single repeated byte")
+
 	// ErrUnsupportedCompression indicates an unsupported compression algorithm.
 	ErrUnsupportedCompression = errors.New("encryptedcol: unsupported compression algorithm")
 
+	// ErrInvalidCompressionLevel indicates a compression level outside the supported range.
+	ErrInvalidCompressionLevel = errors.New("encryptedcol: compression level must be 1-4")
+
+	// ErrUnsupportedAEAD indicates an unsupported AEAD was configured.
+	ErrUnsupportedAEAD = errors.New("encryptedcol: unsupported AEAD")
+
+	// ErrInvalidBlindIndexSize indicates a blind index size outside 4-32 bytes.
+	ErrInvalidBlindIndexSize = errors.New("encryptedcol: blind index size must be 4-32 bytes")
+
+	// ErrInvalidBlindIndexStretch indicates a blind index stretch iteration count below 1.
+	ErrInvalidBlindIndexStretch = errors.New("encryptedcol: blind index stretch iterations must be at least 1")
+
+	// ErrInvalidBlindIndexPepper indicates a blind index pepper shorter than 16 bytes.
+	ErrInvalidBlindIndexPepper = errors.New("encryptedcol: blind index pepper must be at least 16 bytes")
+
+	// ErrInvalidPaddingBlockSize indicates WithPadding was given a block size outside 2-255.
+	ErrInvalidPaddingBlockSize = errors.New("encryptedcol: padding block size must be 2-255")
+
+	// ErrIncompatibleOptions indicates options that cannot be combined, such as
+	// WithTimestampBinding and WithDerivedNonce.
+	ErrIncompatibleOptions = errors.New("encryptedcol: incompatible options")
+
+	// ErrUnsupportedPlaceholderStyle indicates an unknown SQL placeholder style was configured.
+	ErrUnsupportedPlaceholderStyle = errors.New("encryptedcol: unsupported placeholder style")
+
+	// ErrUnsupportedScanType indicates a database value that cannot hold ciphertext was scanned.
+	ErrUnsupportedScanType = errors.New("encryptedcol: unsupported scan source type")
+
+	// ErrStreamTruncated indicates an encrypted stream ended before its final frame.
+	ErrStreamTruncated = errors.New("encryptedcol: stream truncated")
+
+	// ErrIndexMismatch indicates a stored blind index does not match the decrypted value.
+	ErrIndexMismatch = errors.New("encryptedcol: blind index does not match plaintext")
+
+	// ErrInvalidStruct indicates SealStruct was given something other than a struct or non-nil pointer to one.
+	ErrInvalidStruct = errors.New("encryptedcol: value must be a struct or non-nil pointer to struct")
+
+	// ErrInvalidStructTag indicates a malformed encryptedcol struct tag, or one on an unexported field.
+	ErrInvalidStructTag = errors.New("encryptedcol: invalid encryptedcol struct tag")
+
+	// ErrNoCipherCouldDecrypt indicates OpenChain tried every cipher without success.
+	ErrNoCipherCouldDecrypt = errors.New("encryptedcol: no cipher in chain could decrypt")
+
+	// ErrSelfTestFailed indicates Cipher.SelfTest found a key or primitive that does not work.
+	ErrSelfTestFailed = errors.New("encryptedcol: self-test failed")
+
+	// ErrReadOnly indicates an attempt to create ciphertext with a Cipher built WithReadOnly.
+	ErrReadOnly = errors.New("encryptedcol: cipher is read-only")
+
 	// ErrCipherClosed indicates the cipher was used after Close() was called.
 	ErrCipherClosed = errors.New("encryptedcol: cipher is closed")
 )
+
+// keyNotFoundError wraps ErrKeyNotFound with the key ID read from a ciphertext
+// header, so operators can tell which key version is missing. The key ID is
+// attacker-controllable, so it is quoted with non-printable and non-ASCII
+// characters escaped.
+func keyNotFoundError(keyID string) error {
+	return fmt.Errorf("%w: key_id=%+q", ErrKeyNotFound, keyID)
+}
+
+// duplicateKeyIDError wraps ErrDuplicateKeyID with the offending key ID.
+func duplicateKeyIDError(keyID string) error {
+	return fmt.Errorf("%w: key_id=%+q", ErrDuplicateKeyID, keyID)
+}
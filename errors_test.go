@@ -19,6 +19,8 @@ func TestErrors_Identity(t *testing.T) {
 		ErrInvalidFormat,
 		ErrNoKeys,
 		ErrDefaultKeyNotFound,
+		ErrNoDefaultKey,
+		ErrDuplicateKeyID,
 		ErrInvalidKeyID,
 		ErrUnsupportedCompression,
 		ErrCipherClosed,
@@ -54,6 +56,8 @@ func TestErrors_Messages(t *testing.T) {
 		{"ErrInvalidFormat", ErrInvalidFormat, "invalid ciphertext format"},
 		{"ErrNoKeys", ErrNoKeys, "no keys"},
 		{"ErrDefaultKeyNotFound", ErrDefaultKeyNotFound, "default key not found"},
+		{"ErrNoDefaultKey", ErrNoDefaultKey, "no default key"},
+		{"ErrDuplicateKeyID", ErrDuplicateKeyID, "duplicate key ID"},
 		{"ErrInvalidKeyID", ErrInvalidKeyID, "key ID"},
 		{"ErrUnsupportedCompression", ErrUnsupportedCompression, "unsupported compression"},
 		{"ErrCipherClosed", ErrCipherClosed, "cipher is closed"},
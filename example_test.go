@@ -1,6 +1,7 @@
 package encryptedcol_test
 
 import (
+	"database/sql"
 	"fmt"
 
 	"github.com/ai8future/encryptedcol"
@@ -138,3 +139,34 @@ func Example_nullHandling() {
 	// Open(nil): [] <nil>
 	// Empty string encrypted: true
 }
+
+func Example_databaseSQL() {
+	masterKey := []byte("01234567890123456789012345678901")
+	cipher, _ := encryptedcol.New(encryptedcol.WithKey("v1", masterKey))
+
+	type User struct {
+		ID    int64
+		Email *string // nil stored as NULL
+		Age   *int64
+	}
+
+	var db *sql.DB // opened with sql.Open in real code
+	if db == nil {
+		return
+	}
+
+	email := "alice@example.com"
+	age := int64(30)
+	user := User{ID: 1, Email: &email, Age: &age}
+
+	// Seal on write
+	_, _ = db.Exec(
+		"INSERT INTO users (id, email_encrypted, age_encrypted) VALUES ($1, $2, $3)",
+		user.ID, cipher.EncryptedString(&user.Email), cipher.EncryptedInt64(&user.Age),
+	)
+
+	// Open on read
+	var loaded User
+	_ = db.QueryRow("SELECT id, email_encrypted, age_encrypted FROM users WHERE id = $1", 1).
+		Scan(&loaded.ID, cipher.EncryptedString(&loaded.Email), cipher.EncryptedInt64(&loaded.Age))
+}
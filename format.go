@@ -1,16 +1,39 @@
 package encryptedcol
 
+import (
+	"encoding/binary"
+	"math"
+	"time"
+)
+
 // Ciphertext format:
-// [flag:1][keyIDLen:1][keyID:n][nonce:24][secretbox(innerKeyID + plaintext)]
+// [flag:1][keyIDLen:1][keyID:n][nonce:N][aead(innerKeyID + plaintext)]
+//
+// Flag byte layout: high nibble = AEAD, bit 3 = AAD bound, bits 0-2 = compression.
 //
-// Flag byte values:
+// Compression (bits 0-2):
 //   0x00 = no compression
 //   0x01 = zstd compressed
 //   0x02 = snappy compressed
 //
+// AAD bound (bit 3):
+//   0x08 = sealed with non-empty associated data (the AAD itself is not stored)
+//
+// AEAD (high nibble):
+//   0x0_ = XSalsa20-Poly1305 (secretbox), 24-byte nonce
+//   0x1_ = AES-256-GCM, 12-byte nonce
+//   0x2_ = AES-256-GCM-SIV, 12-byte nonce
+//   0x3_ = ChaCha20-Poly1305, 12-byte nonce
+//
+// Ciphertexts produced before AEAD selection existed have a zero high nibble
+// and therefore decode as secretbox.
+//
 // Inner plaintext format (before encryption):
 // [keyIDLen:1][keyID:n][actualPlaintext]
 //
+// With WithTimestampBinding or WithPadding an inner header precedes the key_id:
+// [0x00][innerFlags:1][days:2, if sealDate][keyIDLen:1][keyID:n][actualPlaintext][padding, if padded]
+//
 // The inner key_id provides cryptographic binding (authenticated by secretbox).
 
 const (
@@ -18,88 +41,253 @@ const (
 	flagZstd          byte = 0x01
 	flagSnappy        byte = 0x02
 
-	nonceSize = 24
+	flagCompressionMask byte = 0x07
+	flagAAD             byte = 0x08
+	flagAEADShift            = 4
+
+	// maxKeyIDLen is the longest key ID the single length byte can record.
+	maxKeyIDLen = 255
 )
 
-// formatCiphertext assembles the outer ciphertext format.
-// Returns: [flag:1][keyIDLen:1][keyID:n][nonce:24][ciphertext]
-func formatCiphertext(flag byte, keyID string, nonce [24]byte, ciphertext []byte) []byte {
-	keyIDBytes := []byte(keyID)
-	keyIDLen := len(keyIDBytes)
+// flagFor combines an AEAD and a compression flag into a single flag byte.
+func flagFor(aead AEAD, compression byte) byte {
+	return byte(aead)<<flagAEADShift | compression
+}
+
+// aeadFromFlag extracts the AEAD from a flag byte.
+func aeadFromFlag(flag byte) AEAD {
+	return AEAD(flag >> flagAEADShift)
+}
+
+// hasAAD reports whether the flag byte marks an AAD-bound ciphertext.
+func hasAAD(flag byte) bool {
+	return flag&flagAAD != 0
+}
 
-	// Total size: 1 (flag) + 1 (keyIDLen) + len(keyID) + 24 (nonce) + len(ciphertext)
-	totalSize := 1 + 1 + keyIDLen + nonceSize + len(ciphertext)
+// compressionFromFlag extracts the compression flag from a flag byte.
+func compressionFromFlag(flag byte) byte {
+	return flag & flagCompressionMask
+}
+
+// formatCiphertext assembles the outer ciphertext format.
+// Returns: [flag:1][keyIDLen:1][keyID:n][nonce:N][ciphertext]
+func formatCiphertext(flag byte, keyID string, nonce []byte, ciphertext []byte) []byte {
+	// Total size: 1 (flag) + 1 (keyIDLen) + len(keyID) + len(nonce) + len(ciphertext)
+	totalSize := headerSize(keyID, len(nonce)) + len(ciphertext)
 	result := make([]byte, 0, totalSize)
 
-	result = append(result, flag)
-	result = append(result, byte(keyIDLen))
-	result = append(result, keyIDBytes...)
-	result = append(result, nonce[:]...)
+	result = appendCiphertextHeader(result, flag, keyID, nonce)
 	result = append(result, ciphertext...)
 
 	return result
 }
 
+// headerSize returns the outer header size for a key ID and nonce size.
+func headerSize(keyID string, nonceLen int) int {
+	return 1 + 1 + len(keyID) + nonceLen
+}
+
+// Overhead returns the bytes Seal adds to an uncompressed value under keyID
+// with the default AEAD (secretbox): the outer header, nonce and tag plus the
+// key ID repeated inside the encrypted payload. A sealed value is
+// len(plaintext) + Overhead(keyID) bytes unless compression shrinks it.
+// Cipher.SealOverhead reports the same for a Cipher's AEAD and default key.
+//
+// SealEnvelope and SealStream use their own formats and are not covered.
+func Overhead(keyID string) int {
+	return sealOverhead(keyID, AEADSecretbox)
+}
+
+// sealOverhead is Overhead for any AEAD.
+func sealOverhead(keyID string, aead AEAD) int {
+	return headerSize(keyID, aead.nonceSize()) + aeadOverhead + 1 + len(keyID)
+}
+
+// appendCiphertextHeader appends [flag:1][keyIDLen:1][keyID:n][nonce:N] to dst.
+func appendCiphertextHeader(dst []byte, flag byte, keyID string, nonce []byte) []byte {
+	dst = append(dst, flag, byte(len(keyID)))
+	dst = append(dst, keyID...)
+	return append(dst, nonce...)
+}
+
 // parseFormat parses the outer ciphertext format.
-// Returns flag, keyID, nonce, encrypted data (secretbox ciphertext), and error.
-func parseFormat(data []byte) (flag byte, keyID string, nonce [24]byte, ciphertext []byte, err error) {
-	// Minimum size: flag(1) + keyIDLen(1) + keyID(1 min) + nonce(24) + some ciphertext
-	minSize := 1 + 1 + 1 + nonceSize + 1
-	if len(data) < minSize {
+// Returns flag, keyID, nonce, encrypted data (AEAD ciphertext), and error.
+// The nonce size is determined by the AEAD recorded in the flag byte.
+func parseFormat(data []byte) (flag byte, keyID string, nonce []byte, ciphertext []byte, err error) {
+	var keyIDBytes []byte
+	flag, keyIDBytes, nonce, ciphertext, err = parseFormatBytes(data)
+	keyID = string(keyIDBytes)
+	return
+}
+
+// parseFormatBytes is parseFormat without the key ID string conversion.
+// All returned slices alias data.
+func parseFormatBytes(data []byte) (flag byte, keyID []byte, nonce []byte, ciphertext []byte, err error) {
+	if len(data) < 2 {
 		err = ErrInvalidFormat
 		return
 	}
 
 	flag = data[0]
+	aead := aeadFromFlag(flag)
+	if !aead.valid() {
+		err = ErrInvalidFormat
+		return
+	}
+	nonceLen := aead.nonceSize()
+
+	// Minimum size: flag(1) + keyIDLen(1) + keyID(1 min) + nonce + some ciphertext
+	minSize := 1 + 1 + 1 + nonceLen + 1
+	if len(data) < minSize {
+		err = ErrInvalidFormat
+		return
+	}
+
 	keyIDLen := int(data[1])
 
 	// Validate keyIDLen
-	if keyIDLen == 0 || keyIDLen > 255 {
+	if keyIDLen == 0 || keyIDLen > maxKeyIDLen {
 		err = ErrInvalidFormat
 		return
 	}
 
 	// Check we have enough data for keyID + nonce + at least 1 byte ciphertext
-	headerSize := 1 + 1 + keyIDLen + nonceSize
+	headerSize := 1 + 1 + keyIDLen + nonceLen
 	if len(data) < headerSize+1 {
 		err = ErrInvalidFormat
 		return
 	}
 
-	keyID = string(data[2 : 2+keyIDLen])
-	copy(nonce[:], data[2+keyIDLen:2+keyIDLen+nonceSize])
+	keyID = data[2 : 2+keyIDLen]
+	nonce = data[2+keyIDLen : headerSize]
 	ciphertext = data[headerSize:]
 
 	return
 }
 
+// Inner header for WithTimestampBinding and WithPadding, placed before the
+// inner key_id: [marker:1=0x00][innerFlags:1][days:2 big-endian, if sealDate]
+//
+// The marker can never be a key ID length (key IDs are 1-255 bytes), so
+// readers without the header reject these values with ErrInvalidFormat rather
+// than misreading them, as do readers that meet an unknown flag bit. days
+// counts whole days since sealEpochBase. Padded inner plaintexts end in
+// PKCS#7 padding: n bytes of value n, 1 <= n <= 255.
+const (
+	innerHeaderMarker byte = 0x00
+	innerFlagSealDate byte = 0x01
+	innerFlagPadded   byte = 0x02
+
+	innerHeaderSize   = 2
+	innerSealDateSize = 2
+
+	minPaddingBlockSize = 2
+	maxPaddingBlockSize = 255
+)
+
+// sealEpochBase is day 0 of the WithTimestampBinding seal date.
+var sealEpochBase = time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// sealEpochDays returns the seal date of t, clamped to the uint16 range.
+func sealEpochDays(t time.Time) uint16 {
+	days := int64(t.Sub(sealEpochBase) / (24 * time.Hour))
+	return uint16(max(0, min(days, math.MaxUint16)))
+}
+
+// appendInnerHeader appends the inner header with flags to dst, including the
+// seal date t if flags has innerFlagSealDate.
+func appendInnerHeader(dst []byte, flags byte, t time.Time) []byte {
+	dst = append(dst, innerHeaderMarker, flags)
+	if flags&innerFlagSealDate != 0 {
+		dst = binary.BigEndian.AppendUint16(dst, sealEpochDays(t))
+	}
+	return dst
+}
+
+// appendPadding appends PKCS#7 padding to dst, whose last n bytes are an
+// inner plaintext, so that the inner plaintext becomes a multiple of
+// blockSize (2-255). A full block of padding is added if n already is one.
+func appendPadding(dst []byte, n, blockSize int) []byte {
+	pad := blockSize - n%blockSize
+	for range pad {
+		dst = append(dst, byte(pad))
+	}
+	return dst
+}
+
+// innerPlaintextLen returns the length of the plaintext within an inner
+// plaintext for keyID built by appendInnerWith, without validating it.
+func innerPlaintextLen(inner []byte, keyID string) int {
+	n := len(inner) - 1 - len(keyID)
+	if inner[0] == innerHeaderMarker {
+		flags := inner[1]
+		n -= innerHeaderSize
+		if flags&innerFlagSealDate != 0 {
+			n -= innerSealDateSize
+		}
+		if flags&innerFlagPadded != 0 {
+			n -= int(inner[len(inner)-1])
+		}
+	}
+	return n
+}
+
+// innerHeader is the metadata parsed from an inner plaintext.
+type innerHeader struct {
+	keyID      string
+	sealedDays int // Days since sealEpochBase, or -1 if not recorded
+}
+
 // formatInnerPlaintext prepends the key_id to the plaintext.
 // This inner key_id is authenticated by secretbox encryption.
 // Returns: [keyIDLen:1][keyID:n][plaintext]
 func formatInnerPlaintext(keyID string, plaintext []byte) []byte {
-	keyIDBytes := []byte(keyID)
-	keyIDLen := len(keyIDBytes)
+	totalSize := 1 + len(keyID) + len(plaintext)
+	return appendInnerPlaintext(make([]byte, 0, totalSize), keyID, plaintext)
+}
 
-	totalSize := 1 + keyIDLen + len(plaintext)
-	result := make([]byte, 0, totalSize)
+// appendInnerPlaintext appends [keyIDLen:1][keyID:n][plaintext] to dst.
+func appendInnerPlaintext(dst []byte, keyID string, plaintext []byte) []byte {
+	dst = append(dst, byte(len(keyID)))
+	dst = append(dst, keyID...)
+	return append(dst, plaintext...)
+}
 
-	result = append(result, byte(keyIDLen))
-	result = append(result, keyIDBytes...)
-	result = append(result, plaintext...)
+// parseInnerPlaintext extracts the header (key_id and optional seal date) and
+// actual plaintext from the inner format.
+func parseInnerPlaintext(data []byte) (hdr innerHeader, plaintext []byte, err error) {
+	hdr.sealedDays = -1
+	if len(data) > 0 && data[0] == innerHeaderMarker {
+		if len(data) < innerHeaderSize || data[1]&^(innerFlagSealDate|innerFlagPadded) != 0 {
+			err = ErrInvalidFormat
+			return
+		}
+		flags := data[1]
+		data = data[innerHeaderSize:]
 
-	return result
-}
+		if flags&innerFlagSealDate != 0 {
+			if len(data) < innerSealDateSize {
+				err = ErrInvalidFormat
+				return
+			}
+			hdr.sealedDays = int(binary.BigEndian.Uint16(data))
+			data = data[innerSealDateSize:]
+		}
+
+		if flags&innerFlagPadded != 0 {
+			if data, err = stripPadding(data); err != nil {
+				return
+			}
+		}
+	}
 
-// parseInnerPlaintext extracts the key_id and actual plaintext from the inner format.
-// Returns keyID, plaintext, and error.
-func parseInnerPlaintext(data []byte) (keyID string, plaintext []byte, err error) {
 	if len(data) < 2 {
 		err = ErrInvalidFormat
 		return
 	}
 
 	keyIDLen := int(data[0])
-	if keyIDLen == 0 || keyIDLen > 255 {
+	if keyIDLen == 0 || keyIDLen > maxKeyIDLen {
 		err = ErrInvalidFormat
 		return
 	}
@@ -109,8 +297,25 @@ func parseInnerPlaintext(data []byte) (keyID string, plaintext []byte, err error
 		return
 	}
 
-	keyID = string(data[1 : 1+keyIDLen])
+	hdr.keyID = string(data[1 : 1+keyIDLen])
 	plaintext = data[1+keyIDLen:]
 
 	return
 }
+
+// stripPadding removes the PKCS#7 padding appended by appendPadding.
+func stripPadding(data []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, ErrInvalidFormat
+	}
+	pad := int(data[len(data)-1])
+	if pad == 0 || pad > len(data) {
+		return nil, ErrInvalidFormat
+	}
+	for _, b := range data[len(data)-pad:] {
+		if int(b) != pad {
+			return nil, ErrInvalidFormat
+		}
+	}
+	return data[:len(data)-pad], nil
+}
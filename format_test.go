@@ -2,7 +2,11 @@ package encryptedcol
 
 import (
 	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"math"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -54,13 +58,13 @@ func TestFormatCiphertext_RoundTrip(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			formatted := formatCiphertext(tt.flag, tt.keyID, tt.nonce, tt.ciphertext)
+			formatted := formatCiphertext(tt.flag, tt.keyID, tt.nonce[:], tt.ciphertext)
 
 			flag, keyID, nonce, ciphertext, err := parseFormat(formatted)
 			require.NoError(t, err)
 			require.Equal(t, tt.flag, flag)
 			require.Equal(t, tt.keyID, keyID)
-			require.Equal(t, tt.nonce, nonce)
+			require.Equal(t, tt.nonce[:], nonce)
 			require.True(t, bytes.Equal(tt.ciphertext, ciphertext))
 		})
 	}
@@ -124,9 +128,10 @@ func TestFormatInnerPlaintext_RoundTrip(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			formatted := formatInnerPlaintext(tt.keyID, tt.plaintext)
 
-			keyID, plaintext, err := parseInnerPlaintext(formatted)
+			hdr, plaintext, err := parseInnerPlaintext(formatted)
 			require.NoError(t, err)
-			require.Equal(t, tt.keyID, keyID)
+			require.Equal(t, tt.keyID, hdr.keyID)
+			require.Equal(t, -1, hdr.sealedDays)
 			require.True(t, bytes.Equal(tt.plaintext, plaintext))
 		})
 	}
@@ -141,6 +146,13 @@ func TestParseInnerPlaintext_MalformedInput(t *testing.T) {
 		{"too short - 1 byte", []byte{0x02}},
 		{"keyIDLen 0", []byte{0x00, 'x'}},
 		{"keyIDLen exceeds data", []byte{0x10, 'v', '1'}},
+		{"seal date truncated", []byte{0x00, 0x01, 0x00}},
+		{"unknown inner flag", []byte{0x00, 0x04, 0x02, 'v', '1'}},
+		{"padding byte 0", []byte{0x00, 0x02, 0x02, 'v', '1', 0x00}},
+		{"padding exceeds data", []byte{0x00, 0x02, 0x02, 'v', '1', 0x06}},
+		{"padding bytes differ", []byte{0x00, 0x02, 0x02, 'v', '1', 0x01, 0x02}},
+		{"padding leaves no keyID", []byte{0x00, 0x02, 0x02, 0x02}},
+		{"seal date without keyID", []byte{0x00, 0x01, 0x00, 0x01, 0x00, 'x'}},
 	}
 
 	for _, tt := range tests {
@@ -158,7 +170,7 @@ func TestFormatCiphertext_Structure(t *testing.T) {
 	nonce := [24]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20, 21, 22, 23, 24}
 	ciphertext := []byte("ct")
 
-	result := formatCiphertext(flag, keyID, nonce, ciphertext)
+	result := formatCiphertext(flag, keyID, nonce[:], ciphertext)
 
 	// Expected: [0x01][0x02]['v']['1'][nonce:24]['c']['t']
 	require.Equal(t, byte(0x01), result[0], "flag byte")
@@ -195,3 +207,245 @@ func TestFlagConstants(t *testing.T) {
 		seen[f] = true
 	}
 }
+
+// fixedNonce returns a WithNonceSource source that always yields 00 01 02 ... 17.
+func fixedNonce() func() [24]byte {
+	var n [24]byte
+	for i := range n {
+		n[i] = byte(i)
+	}
+	return func() [24]byte { return n }
+}
+
+func TestSeal_GoldenCiphertext(t *testing.T) {
+	tests := []struct {
+		name string
+		aead AEAD
+		want string
+	}{
+		{
+			name: "secretbox",
+			aead: AEADSecretbox,
+			want: "00027631" + "000102030405060708090a0b0c0d0e0f1011121314151617" +
+				"810ed78d7bb5483aa04a97d0d72ee989a2c686dc32563f09",
+		},
+		{
+			name: "aes-gcm uses first 12 nonce bytes",
+			aead: AEADAESGCM,
+			want: "10027631" + "000102030405060708090a0b" +
+				"2afef1d2e9b8a678736f0ee906d2c381b75b8c8e4ab696f6",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cipher, err := New(WithKey("v1", testKey("v1")), WithAEAD(tt.aead), WithNonceSource(fixedNonce()))
+			require.NoError(t, err)
+
+			ct := cipher.Seal([]byte("hello"))
+			require.Equal(t, tt.want, hex.EncodeToString(ct))
+			require.Equal(t, ct, cipher.Seal([]byte("hello")))
+
+			pt, err := cipher.Open(ct)
+			require.NoError(t, err)
+			require.Equal(t, []byte("hello"), pt)
+		})
+	}
+}
+
+func TestWithNonceSource_AllPaths(t *testing.T) {
+	var calls int
+	src := fixedNonce()
+	cipher, err := New(
+		WithKey("v1", testKey("v1")),
+		WithKey("v2", testKey("v2")),
+		WithDefaultKeyID("v2"),
+		WithNonceSource(func() [24]byte { calls++; return src() }),
+	)
+	require.NoError(t, err)
+	nonce := src()
+
+	// nonceOf returns the nonce stored in a ciphertext header
+	nonceOf := func(ct []byte) []byte {
+		_, _, n, _, err := parseFormatBytes(ct)
+		require.NoError(t, err)
+		return n
+	}
+
+	require.Equal(t, nonce[:], nonceOf(cipher.Seal([]byte("seal"))))
+	require.Equal(t, nonce[:], nonceOf(cipher.SealNoCompress([]byte("nocompress"))))
+	require.Equal(t, 2, calls)
+
+	batch := cipher.SealBatch([][]byte{[]byte("a"), nil, []byte("b")})
+	require.Equal(t, nonce[:], nonceOf(batch[0]))
+	require.Equal(t, nonce[:], nonceOf(batch[2]))
+
+	old, err := New(WithKey("v1", testKey("v1")))
+	require.NoError(t, err)
+	rotated, err := cipher.RotateBatch([][]byte{old.Seal([]byte("rotate"))})
+	require.NoError(t, err)
+	require.Equal(t, nonce[:], nonceOf(rotated[0].Ciphertext))
+
+	var stream bytes.Buffer
+	require.NoError(t, cipher.SealStream(&stream, bytes.NewReader([]byte("stream"))))
+	require.Equal(t, nonce[:], nonceOf(stream.Bytes()))
+
+	// Envelope nonces are equal but data keys stay random
+	e1, e2 := cipher.SealEnvelope([]byte("envelope")), cipher.SealEnvelope([]byte("envelope"))
+	require.NotEqual(t, e1, e2)
+	pt, err := cipher.OpenEnvelope(e1)
+	require.NoError(t, err)
+	require.Equal(t, []byte("envelope"), pt)
+}
+
+func TestWithDerivedNonce(t *testing.T) {
+	// Derive from a row ID prefixed to the plaintext (the test's "storage layer")
+	derive := func(keyID string, plaintext []byte) [24]byte {
+		var n [24]byte
+		copy(n[:], keyID)
+		copy(n[4:], plaintext[:2])
+		return n
+	}
+	nonceFor := func(keyID string, plaintext []byte) []byte {
+		n := derive(keyID, plaintext)
+		return n[:]
+	}
+	nonceOf := func(ct []byte) []byte {
+		_, _, n, _, err := parseFormatBytes(ct)
+		require.NoError(t, err)
+		return n
+	}
+
+	cipher, err := New(
+		WithKey("v1", testKey("v1")),
+		WithKey("v2", testKey("v2")),
+		WithDefaultKeyID("v2"),
+		WithDerivedNonce(derive),
+		WithNonceSource(fixedNonce()), // overridden by WithDerivedNonce
+	)
+	require.NoError(t, err)
+
+	ct := cipher.Seal([]byte("01:alice"))
+	require.Equal(t, nonceFor("v2", []byte("01")), nonceOf(ct))
+	pt, err := cipher.Open(ct)
+	require.NoError(t, err)
+	require.Equal(t, []byte("01:alice"), pt)
+
+	onV1, err := cipher.SealWithKey("v1", []byte("02:bob"))
+	require.NoError(t, err)
+	require.Equal(t, nonceFor("v1", []byte("02")), nonceOf(onV1))
+	require.Equal(t, nonceFor("v2", []byte("03")), nonceOf(cipher.SealNoCompress([]byte("03:carol"))))
+
+	batch := cipher.SealBatch([][]byte{[]byte("04:dave"), nil, []byte("05:erin")})
+	require.Equal(t, nonceFor("v2", []byte("04")), nonceOf(batch[0]))
+	require.Equal(t, nonceFor("v2", []byte("05")), nonceOf(batch[2]))
+
+	rotated, err := cipher.RotateBatch([][]byte{onV1})
+	require.NoError(t, err)
+	require.Equal(t, nonceFor("v2", []byte("02")), nonceOf(rotated[0].Ciphertext))
+
+	// Envelopes keep using random data keys
+	require.NotEqual(t, cipher.SealEnvelope([]byte("06:frank")), cipher.SealEnvelope([]byte("06:frank")))
+}
+
+func TestWithDerivedNonce_ShortNonceAEAD(t *testing.T) {
+	cipher, err := New(
+		WithKey("v1", testKey("v1")),
+		WithAEAD(AEADAESGCM),
+		WithDerivedNonce(func(string, []byte) [24]byte { return fixedNonce()() }),
+	)
+	require.NoError(t, err)
+
+	ct := cipher.Seal([]byte("hello"))
+	_, _, nonce, _, err := parseFormatBytes(ct)
+	require.NoError(t, err)
+	require.Equal(t, []byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11}, nonce)
+
+	pt, err := cipher.Open(ct)
+	require.NoError(t, err)
+	require.Equal(t, []byte("hello"), pt)
+}
+
+func TestOverhead(t *testing.T) {
+	// flag + keyIDLen + "v1" + 24-byte nonce + 16-byte tag + inner keyIDLen + "v1"
+	require.Equal(t, 47, Overhead("v1"))
+	require.Equal(t, 47+2*8, Overhead("v1-2026-01"))
+
+	for _, aead := range []AEAD{AEADSecretbox, AEADAESGCM, AEADGCMSIV, AEADChaCha20Poly1305} {
+		cipher, err := New(WithKey("key-2026", testKey("key-2026")), WithAEAD(aead))
+		require.NoError(t, err)
+
+		for _, size := range []int{0, 16, 4096} {
+			plaintext := make([]byte, size)
+			_, _ = rand.Read(plaintext) // incompressible
+			require.Len(t, cipher.Seal(plaintext), size+cipher.SealOverhead(), "aead=%d size=%d", aead, size)
+		}
+	}
+
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+	require.Equal(t, Overhead("v1"), cipher.SealOverhead())
+}
+
+func TestInnerSealDate(t *testing.T) {
+	sealed := time.Date(2026, time.March, 14, 15, 9, 26, 0, time.UTC)
+	inner := appendInnerPlaintext(appendInnerHeader(nil, innerFlagSealDate, sealed), "v1", []byte("hello"))
+
+	// Expected: [0x00][0x01][days:2][0x02]['v']['1']['h']['e']['l']['l']['o']
+	require.Equal(t, []byte{0x00, 0x01}, inner[:2], "marker and version")
+	require.Len(t, inner, innerHeaderSize+innerSealDateSize+3+5)
+
+	hdr, plaintext, err := parseInnerPlaintext(inner)
+	require.NoError(t, err)
+	require.Equal(t, "v1", hdr.keyID)
+	require.Equal(t, []byte("hello"), plaintext)
+	require.Equal(t, time.Date(2026, time.March, 14, 0, 0, 0, 0, time.UTC), sealEpochBase.AddDate(0, 0, hdr.sealedDays))
+
+	// Out-of-range dates are clamped
+	require.Equal(t, uint16(0), sealEpochDays(time.Date(1999, time.January, 1, 0, 0, 0, 0, time.UTC)))
+	require.Equal(t, uint16(math.MaxUint16), sealEpochDays(time.Date(2300, time.January, 1, 0, 0, 0, 0, time.UTC)))
+}
+
+func FuzzParseFormat(f *testing.F) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+	f.Add(cipher.Seal([]byte("hello")))
+	f.Add(cipher.Seal(bytes.Repeat([]byte("compressible "), 100)))
+	f.Add(formatInnerPlaintext("v1", []byte("hello")))
+	f.Add(appendInnerPlaintext(appendInnerHeader(nil, innerFlagSealDate, time.Now()), "v1", []byte("hello")))
+	f.Add(appendPadding(appendInnerPlaintext(appendInnerHeader(nil, innerFlagPadded, time.Time{}), "v1", []byte("hello")), 10, 16))
+	f.Add([]byte{0x10, 0xff})
+	f.Add([]byte{})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		flag, keyID, nonce, ciphertext, err := parseFormatBytes(data)
+		if err == nil {
+			require.True(t, aeadFromFlag(flag).valid())
+			require.NotEmpty(t, keyID)
+			require.Len(t, nonce, aeadFromFlag(flag).nonceSize())
+			require.NotEmpty(t, ciphertext)
+			require.Equal(t, data, formatCiphertext(flag, string(keyID), nonce, ciphertext))
+		}
+
+		// The inner format is only reachable past the MAC, so parse it directly
+		hdr, plaintext, err := parseInnerPlaintext(data)
+		if err == nil {
+			require.NotEmpty(t, hdr.keyID)
+			require.True(t, hdr.sealedDays >= -1 && hdr.sealedDays <= math.MaxUint16)
+			require.True(t, bytes.Contains(data, plaintext))
+		}
+	})
+}
+
+func TestInnerPadding(t *testing.T) {
+	for n := 0; n <= 40; n++ {
+		plaintext := bytes.Repeat([]byte{0x01}, n) // Looks like padding
+		inner := appendInnerPlaintext(appendInnerHeader(nil, innerFlagPadded, time.Time{}), "v1", plaintext)
+		inner = appendPadding(inner, len(inner), 16)
+		require.Zero(t, len(inner)%16)
+
+		hdr, got, err := parseInnerPlaintext(inner)
+		require.NoError(t, err)
+		require.Equal(t, "v1", hdr.keyID)
+		require.Equal(t, plaintext, got)
+		require.Equal(t, n, innerPlaintextLen(inner, "v1"))
+	}
+}
@@ -0,0 +1,220 @@
+package encryptedcol
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+)
+
+// AES-GCM-SIV (RFC 8452), a nonce-misuse-resistant AEAD.
+//
+// Each message derives its own authentication and encryption keys from the
+// key-generating key and nonce. The tag is a POLYVAL of the AAD and plaintext
+// encrypted under the message key, and doubles as the CTR initial counter
+// (the synthetic IV). Repeating a nonce therefore only reveals whether two
+// messages were identical, which SealDeterministic relies on.
+//
+// The standard library does not provide GCM-SIV, so it is implemented here on
+// top of crypto/aes. POLYVAL uses a constant-time bitwise multiply; it is
+// slower than AES-GCM and meant for short column values.
+
+const (
+	gcmSIVNonceSize = 12
+	gcmSIVTagSize   = 16
+
+	// polyvalReduce is x^-1 reduction for POLYVAL's polynomial
+	// x^128 + x^127 + x^126 + x^121 + 1, applied to the high word.
+	polyvalReduce uint64 = 0xE100000000000000
+)
+
+var errGCMSIVOpen = errors.New("encryptedcol: gcm-siv: message authentication failed")
+
+// gcmSIV implements cipher.AEAD for AES-GCM-SIV.
+type gcmSIV struct {
+	block  cipher.Block // Key-generating key
+	keyLen int          // 16 (AES-128-GCM-SIV) or 32 (AES-256-GCM-SIV)
+}
+
+// newAESGCMSIV creates an AES-GCM-SIV AEAD from a 16- or 32-byte key.
+func newAESGCMSIV(key []byte) (cipher.AEAD, error) {
+	if len(key) != 16 && len(key) != 32 {
+		return nil, aes.KeySizeError(len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return &gcmSIV{block: block, keyLen: len(key)}, nil
+}
+
+func (g *gcmSIV) NonceSize() int { return gcmSIVNonceSize }
+func (g *gcmSIV) Overhead() int  { return gcmSIVTagSize }
+
+// Seal implements cipher.AEAD.
+func (g *gcmSIV) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
+	if len(nonce) != gcmSIVNonceSize {
+		panic("encryptedcol: gcm-siv: incorrect nonce length")
+	}
+	authKey, enc := g.messageKeys(nonce)
+	tag := gcmSIVTag(&authKey, enc, nonce, plaintext, additionalData)
+
+	ret, out := sliceForAppend(dst, len(plaintext)+gcmSIVTagSize)
+	gcmSIVCTR(enc, &tag, out, plaintext)
+	copy(out[len(plaintext):], tag[:])
+	return ret
+}
+
+// Open implements cipher.AEAD.
+func (g *gcmSIV) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(nonce) != gcmSIVNonceSize {
+		panic("encryptedcol: gcm-siv: incorrect nonce length")
+	}
+	if len(ciphertext) < gcmSIVTagSize {
+		return nil, errGCMSIVOpen
+	}
+	var tag [gcmSIVTagSize]byte
+	copy(tag[:], ciphertext[len(ciphertext)-gcmSIVTagSize:])
+	ciphertext = ciphertext[:len(ciphertext)-gcmSIVTagSize]
+
+	authKey, enc := g.messageKeys(nonce)
+	ret, out := sliceForAppend(dst, len(ciphertext))
+	gcmSIVCTR(enc, &tag, out, ciphertext)
+
+	expected := gcmSIVTag(&authKey, enc, nonce, out, additionalData)
+	if subtle.ConstantTimeCompare(expected[:], tag[:]) != 1 {
+		clear(out)
+		return nil, errGCMSIVOpen
+	}
+	return ret, nil
+}
+
+// messageKeys derives the per-nonce POLYVAL key and AES encryption key:
+// the first 8 bytes of AES(K, le32(i) || nonce) for i = 0..1 (auth) and
+// i = 2..3 or 2..5 (encryption, 16 or 32 bytes).
+func (g *gcmSIV) messageKeys(nonce []byte) (authKey [16]byte, enc cipher.Block) {
+	var in, out [16]byte
+	var material [16 + 32]byte
+	defer clear(material[:])
+	defer clear(out[:])
+
+	copy(in[4:], nonce)
+	blocks := (16 + g.keyLen) / 8
+	for i := 0; i < blocks; i++ {
+		binary.LittleEndian.PutUint32(in[:4], uint32(i))
+		g.block.Encrypt(out[:], in[:])
+		copy(material[8*i:], out[:8])
+	}
+
+	copy(authKey[:], material[:16])
+	enc, err := aes.NewCipher(material[16 : 16+g.keyLen])
+	if err != nil {
+		// Key length is always 16 or 32
+		panic("encryptedcol: internal error: " + err.Error())
+	}
+	return authKey, enc
+}
+
+// gcmSIVTag computes AES(encKey, POLYVAL(authKey, pad(aad) || pad(pt) || lengths) ^ nonce),
+// with the most significant bit of the last byte cleared before encryption.
+func gcmSIVTag(authKey *[16]byte, enc cipher.Block, nonce, plaintext, aad []byte) [16]byte {
+	var p polyval
+	p.init(authKey)
+	p.update(aad)
+	p.update(plaintext)
+
+	var lengths [16]byte
+	binary.LittleEndian.PutUint64(lengths[:8], uint64(len(aad))*8)
+	binary.LittleEndian.PutUint64(lengths[8:], uint64(len(plaintext))*8)
+	p.block(&lengths)
+
+	s := p.sum()
+	for i := range nonce {
+		s[i] ^= nonce[i]
+	}
+	s[15] &= 0x7f
+	enc.Encrypt(s[:], s[:])
+	return s
+}
+
+// gcmSIVCTR XORs src with the keystream AES(encKey, counter) into dst, where
+// the counter starts as the tag with its top bit set and its first 32 bits
+// increment as a little-endian integer.
+func gcmSIVCTR(enc cipher.Block, tag *[16]byte, dst, src []byte) {
+	var ctr, keystream [16]byte
+	ctr = *tag
+	ctr[15] |= 0x80
+	defer clear(keystream[:])
+
+	for len(src) > 0 {
+		enc.Encrypt(keystream[:], ctr[:])
+		n := subtle.XORBytes(dst, src, keystream[:])
+		dst, src = dst[n:], src[n:]
+		binary.LittleEndian.PutUint32(ctr[:4], binary.LittleEndian.Uint32(ctr[:4])+1)
+	}
+}
+
+// sliceForAppend extends in by n bytes, reallocating if needed, and returns
+// the full slice and the n-byte tail.
+func sliceForAppend(in []byte, n int) (head, tail []byte) {
+	if total := len(in) + n; cap(in) >= total {
+		head = in[:total]
+	} else {
+		head = make([]byte, total)
+		copy(head, in)
+	}
+	return head, head[len(in):]
+}
+
+// polyval computes POLYVAL (RFC 8452 section 3) over 16-byte blocks.
+// Field elements are little-endian: word 0 holds x^0..x^63.
+type polyval struct {
+	h, s [2]uint64
+}
+
+func (p *polyval) init(key *[16]byte) {
+	p.h = [2]uint64{binary.LittleEndian.Uint64(key[:8]), binary.LittleEndian.Uint64(key[8:])}
+	p.s = [2]uint64{}
+}
+
+// update absorbs data, zero-padding the final partial block.
+func (p *polyval) update(data []byte) {
+	for len(data) > 0 {
+		var b [16]byte
+		n := copy(b[:], data)
+		data = data[n:]
+		p.block(&b)
+	}
+}
+
+// block absorbs one block: S = dot(S ^ X, H).
+func (p *polyval) block(b *[16]byte) {
+	p.s[0] ^= binary.LittleEndian.Uint64(b[:8])
+	p.s[1] ^= binary.LittleEndian.Uint64(b[8:])
+	p.s = polyvalDot(p.s, p.h)
+}
+
+func (p *polyval) sum() [16]byte {
+	var out [16]byte
+	binary.LittleEndian.PutUint64(out[:8], p.s[0])
+	binary.LittleEndian.PutUint64(out[8:], p.s[1])
+	return out
+}
+
+// polyvalDot returns a * b * x^-128 in POLYVAL's field (Montgomery-style:
+// add a for each set bit of b, from x^0 up, then multiply by x^-1).
+// Branch-free, so the running time does not depend on the operands.
+func polyvalDot(a, b [2]uint64) [2]uint64 {
+	var r0, r1 uint64
+	for i := 0; i < 128; i++ {
+		mask := -((b[i/64] >> (i % 64)) & 1)
+		r0 ^= a[0] & mask
+		r1 ^= a[1] & mask
+
+		lsb := r0 & 1
+		r0 = r0>>1 | r1<<63
+		r1 = r1>>1 ^ (-lsb & polyvalReduce)
+	}
+	return [2]uint64{r0, r1}
+}
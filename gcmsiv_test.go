@@ -0,0 +1,139 @@
+package encryptedcol
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func mustHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	require.NoError(t, err)
+	return b
+}
+
+// Test vectors from RFC 8452 appendix C
+func TestGCMSIV_RFC8452Vectors(t *testing.T) {
+	tests := []struct {
+		name      string
+		key       string
+		nonce     string
+		aad       string
+		plaintext string
+		result    string
+	}{
+		{
+			name:   "AES-128 empty",
+			key:    "01000000000000000000000000000000",
+			nonce:  "030000000000000000000000",
+			result: "dc20e2d83f25705bb49e439eca56de25",
+		},
+		{
+			name:      "AES-128 8 bytes",
+			key:       "01000000000000000000000000000000",
+			nonce:     "030000000000000000000000",
+			plaintext: "0100000000000000",
+			result:    "b5d839330ac7b786578782fff6013b815b287c22493a364c",
+		},
+		{
+			name:      "AES-128 with AAD",
+			key:       "01000000000000000000000000000000",
+			nonce:     "030000000000000000000000",
+			aad:       "01",
+			plaintext: "0200000000000000",
+			result:    "1e6daba35669f4273b0a1a2560969cdf790d99759abd1508",
+		},
+		{
+			name:   "AES-256 empty",
+			key:    "0100000000000000000000000000000000000000000000000000000000000000",
+			nonce:  "030000000000000000000000",
+			result: "07f5f4169bbf55a8400cd47ea6fd400f",
+		},
+		{
+			name:      "AES-256 8 bytes",
+			key:       "0100000000000000000000000000000000000000000000000000000000000000",
+			nonce:     "030000000000000000000000",
+			plaintext: "0100000000000000",
+			result:    "c2ef328e5c71c83b843122130f7364b761e0b97427e3df28",
+		},
+		{
+			name:      "AES-256 12 bytes",
+			key:       "0100000000000000000000000000000000000000000000000000000000000000",
+			nonce:     "030000000000000000000000",
+			plaintext: "010000000000000000000000",
+			result:    "9aab2aeb3faa0a34aea8e2b18ca50da9ae6559e48fd10f6e5c9ca17e",
+		},
+		{
+			name:      "AES-256 counter wrap",
+			key:       "0000000000000000000000000000000000000000000000000000000000000000",
+			nonce:     "000000000000000000000000",
+			plaintext: "000000000000000000000000000000004db923dc793ee6497c76dcc03a98e108",
+			result:    "f3f80f2cf0cb2dd9c5984fcda908456cc537703b5ba70324a6793a7bf218d3eaffffffff000000000000000000000000",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			aead, err := newAESGCMSIV(mustHex(t, tt.key))
+			require.NoError(t, err)
+			nonce, aad, plaintext := mustHex(t, tt.nonce), mustHex(t, tt.aad), mustHex(t, tt.plaintext)
+
+			sealed := aead.Seal(nil, nonce, plaintext, aad)
+			require.Equal(t, tt.result, hex.EncodeToString(sealed))
+
+			opened, err := aead.Open(nil, nonce, sealed, aad)
+			require.NoError(t, err)
+			require.True(t, bytes.Equal(plaintext, opened))
+		})
+	}
+}
+
+// Test vector from RFC 8452 appendix A
+func TestPolyval_RFC8452Vector(t *testing.T) {
+	var p polyval
+	p.init((*[16]byte)(mustHex(t, "25629347589242761d31f826ba4b757b")))
+	p.update(mustHex(t, "4f4f95668c83dfb6401762bb2d01a262"))
+	p.update(mustHex(t, "d1a24ddd2721d006bbe45f20d3c9f362"))
+
+	sum := p.sum()
+	require.Equal(t, "f7a3b47b846119fae5b7866cf5e5b77e", hex.EncodeToString(sum[:]))
+}
+
+func TestGCMSIV_OpenRejectsTampering(t *testing.T) {
+	aead, _ := newAESGCMSIV(testKey("v1"))
+	nonce := make([]byte, gcmSIVNonceSize)
+	sealed := aead.Seal(nil, nonce, []byte("secret"), []byte("aad"))
+
+	for i := range sealed {
+		tampered := bytes.Clone(sealed)
+		tampered[i] ^= 0x01
+		_, err := aead.Open(nil, nonce, tampered, []byte("aad"))
+		require.Error(t, err, "byte %d", i)
+	}
+
+	_, err := aead.Open(nil, nonce, sealed, []byte("other"))
+	require.Error(t, err)
+	_, err = aead.Open(nil, nonce, sealed[:gcmSIVTagSize-1], nil)
+	require.Error(t, err)
+
+	_, err = newAESGCMSIV(make([]byte, 24))
+	require.Error(t, err)
+}
+
+func TestGCMSIV_InPlace(t *testing.T) {
+	aead, _ := newAESGCMSIV(testKey("v1"))
+	nonce := make([]byte, gcmSIVNonceSize)
+	plaintext := []byte("in-place round trip across more than one block")
+
+	buf := make([]byte, len(plaintext), len(plaintext)+gcmSIVTagSize)
+	copy(buf, plaintext)
+	sealed := aead.Seal(buf[:0], nonce, buf, nil)
+	require.Equal(t, aead.Seal(nil, nonce, plaintext, nil), sealed)
+
+	opened, err := aead.Open(sealed[:0], nonce, sealed, nil)
+	require.NoError(t, err)
+	require.Equal(t, plaintext, opened)
+}
@@ -3,9 +3,11 @@ module github.com/ai8future/encryptedcol
 go 1.24.0
 
 require (
+	github.com/golang/snappy v1.0.0
 	github.com/klauspost/compress v1.18.3
 	github.com/stretchr/testify v1.11.1
 	golang.org/x/crypto v0.47.0
+	golang.org/x/text v0.33.0
 )
 
 require (
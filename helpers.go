@@ -1,8 +1,11 @@
 package encryptedcol
 
 import (
+	"bytes"
+	"crypto/subtle"
 	"encoding/binary"
 	"encoding/json"
+	"time"
 )
 
 // SealedValue holds encrypted data with its blind index for searchable fields.
@@ -12,9 +15,58 @@ type SealedValue struct {
 	KeyID      string // Key version used
 }
 
+// IsNull reports whether s represents a NULL value (no ciphertext).
+func (s *SealedValue) IsNull() bool {
+	return s == nil || s.Ciphertext == nil
+}
+
+// Columns returns s as column values for an insert or update map, as used by
+// query builders such as squirrel or gorp:
+//
+//	{prefix + "_encrypted": Ciphertext, prefix + "_idx": BlindIndex, "key_id": KeyID}
+//
+// These are the columns ColumnDDL creates and WriteColumns sets; a NULL value
+// maps both data columns to a nil []byte, which drivers write as NULL.
+// A nil s is treated as NULL with an empty key_id, matching IsNull.
+// Panics if prefix is not a valid identifier.
+//
+// Example:
+//
+//	sq.Insert("users").SetMap(cipher.SealStringIndexed(email).Columns("email"))
+func (s *SealedValue) Columns(prefix string) map[string]any {
+	if !isValidColumnName(prefix) {
+		panic("encryptedcol: invalid column name (must start with letter/underscore, contain only alphanumeric/underscore)")
+	}
+	if s == nil {
+		return map[string]any{
+			prefix + "_encrypted": []byte(nil),
+			prefix + "_idx":       []byte(nil),
+			"key_id":              "",
+		}
+	}
+	return map[string]any{
+		prefix + "_encrypted": s.Ciphertext,
+		prefix + "_idx":       s.BlindIndex,
+		"key_id":              s.KeyID,
+	}
+}
+
 // nullSealedValue returns a SealedValue representing NULL.
 func (c *Cipher) nullSealedValue() *SealedValue {
-	return &SealedValue{KeyID: c.defaultID}
+	return &SealedValue{KeyID: c.DefaultKeyID()}
+}
+
+// sealIndexed encrypts plaintext and computes the blind index of indexInput
+// from one key snapshot, so KeyID always matches both even during ReloadKeys.
+// plaintext must not be nil.
+func (c *Cipher) sealIndexed(plaintext, indexInput []byte) *SealedValue {
+	r := c.mustAcquireWritable()
+	defer r.release()
+	return &SealedValue{
+		Ciphertext: c.sealWithKeyID(r, nil, r.defaultID, plaintext, nil),
+		BlindIndex: c.computeHMAC(r, r.defaultID, indexInput),
+		KeyID:      r.defaultID,
+	}
 }
 
 // SealString encrypts a string value.
@@ -40,6 +92,83 @@ func (c *Cipher) OpenString(ciphertext []byte) (string, error) {
 	return string(plaintext), nil
 }
 
+// OpenStringOr is OpenString for columns where NULL has a natural value:
+// it returns fallback, nil if ciphertext is nil instead of ErrWasNull.
+// Decryption errors are still returned.
+//
+//	name, err := cipher.OpenStringOr(row.NicknameEncrypted, "")
+func (c *Cipher) OpenStringOr(ciphertext []byte, fallback string) (string, error) {
+	if ciphertext == nil {
+		return fallback, nil
+	}
+	return c.OpenString(ciphertext)
+}
+
+// CompareString decrypts ciphertext and reports whether it equals candidate,
+// without materializing the plaintext as a string. The decrypted bytes are
+// zeroed before returning. Use it to weed out blind index false positives
+// (e.g. with truncated indexes) while scanning rows.
+//
+// The comparison is constant-time for equal-length values; a length mismatch
+// returns early.
+// Returns false and ErrWasNull if ciphertext is nil.
+func (c *Cipher) CompareString(ciphertext []byte, candidate string) (bool, error) {
+	if ciphertext == nil {
+		return false, ErrWasNull
+	}
+	plaintext, err := c.Open(ciphertext)
+	if err != nil {
+		return false, err
+	}
+	defer clear(plaintext)
+
+	if len(plaintext) != len(candidate) {
+		return false, nil
+	}
+	var diff byte
+	for i := range plaintext {
+		diff |= plaintext[i] ^ candidate[i]
+	}
+	return subtle.ConstantTimeByteEq(diff, 0) == 1, nil
+}
+
+// OpenVerifyIndex decrypts ciphertext and checks that blindIndex is the blind
+// index of the plaintext under norm (nil for none), computed with the key
+// version that sealed it. It catches _encrypted and _idx columns that have
+// drifted apart, e.g. after a buggy write or a row mix-up.
+//
+// The plaintext is returned only on a verified match; otherwise ErrIndexMismatch.
+// The comparison is constant-time. Indexes from BlindIndexForColumn do not
+// verify, since they are bound to a column name.
+// Returns "" and ErrWasNull if ciphertext is nil.
+func (c *Cipher) OpenVerifyIndex(ciphertext, blindIndex []byte, norm Normalizer) (string, error) {
+	if ciphertext == nil {
+		return "", ErrWasNull
+	}
+	r, err := c.acquire()
+	if err != nil {
+		return "", err
+	}
+	defer r.release()
+
+	keyID, plaintext, err := c.openOuter(r, nil, ciphertext, nil)
+	c.observeOpen(keyID, err)
+	if err != nil {
+		return "", err
+	}
+	defer clear(plaintext)
+
+	s := string(plaintext)
+	indexInput := s
+	if norm != nil {
+		indexInput = norm(s)
+	}
+	if !BlindIndexEqual(c.computeHMAC(r, string(keyID), []byte(indexInput)), blindIndex) {
+		return "", ErrIndexMismatch
+	}
+	return s, nil
+}
+
 // SealStringPtr encrypts a string pointer.
 // Returns nil if s is nil (NULL preservation).
 func (c *Cipher) SealStringPtr(s *string) []byte {
@@ -68,11 +197,7 @@ func (c *Cipher) SealStringIndexed(s string) *SealedValue {
 	if c.config.emptyStringAsNull && s == "" {
 		return c.nullSealedValue()
 	}
-	return &SealedValue{
-		Ciphertext: c.Seal([]byte(s)),
-		BlindIndex: c.BlindIndex([]byte(s)),
-		KeyID:      c.defaultID,
-	}
+	return c.sealIndexed([]byte(s), []byte(s))
 }
 
 // SealStringIndexedNormalized encrypts a string and computes a normalized blind index.
@@ -88,27 +213,124 @@ func (c *Cipher) SealStringIndexedNormalized(s string, norm Normalizer) *SealedV
 		return c.nullSealedValue()
 	}
 	normalized := norm(s)
-	return &SealedValue{
-		Ciphertext: c.Seal([]byte(s)),                // Original preserved
-		BlindIndex: c.BlindIndex([]byte(normalized)), // Normalized for search
-		KeyID:      c.defaultID,
+	// Original preserved in ciphertext; normalized for search
+	return c.sealIndexed([]byte(s), []byte(normalized))
+}
+
+// SealStringIndexedForColumn encrypts a string and computes a column-bound blind index.
+// See BlindIndexForColumn. Search with SearchConditionForColumn.
+func (c *Cipher) SealStringIndexedForColumn(column string, s string) *SealedValue {
+	if c.config.emptyStringAsNull && s == "" {
+		return c.nullSealedValue()
+	}
+	if !isValidColumnName(column) {
+		panic("encryptedcol: invalid column name (must start with letter/underscore, contain only alphanumeric/underscore)")
 	}
+	return c.sealIndexed([]byte(s), columnIndexInput(column, []byte(s)))
+}
+
+// SealStringMultiIndexed encrypts a string once and computes one blind index
+// per entry in norms, all under the same key version. Each map key names the
+// index column ({name}_idx) and the index is bound to that name (see
+// BlindIndexForColumn), so e.g. a case-insensitive and an exact index of the
+// same value are unrelated in the database. A nil Normalizer indexes s as is.
+//
+//	ct, idx, keyID := cipher.SealStringMultiIndexed(email, map[string]Normalizer{
+//		"email":       NormalizeEmail, // email_idx
+//		"email_exact": nil,            // email_exact_idx
+//	})
+//
+// Search each index with SearchConditionForColumn(name, norm(input), ...).
+// With WithEmptyStringAsNull and s == "", the ciphertext and all indexes are nil.
+// Panics if a name is not a valid column name.
+func (c *Cipher) SealStringMultiIndexed(s string, norms map[string]Normalizer) (ciphertext []byte, indexes map[string][]byte, keyID string) {
+	for name := range norms {
+		if !isValidColumnName(name) {
+			panic("encryptedcol: invalid column name (must start with letter/underscore, contain only alphanumeric/underscore)")
+		}
+	}
+
+	r := c.mustAcquireWritable()
+	defer r.release()
+
+	indexes = make(map[string][]byte, len(norms))
+	if c.config.emptyStringAsNull && s == "" {
+		for name := range norms {
+			indexes[name] = nil
+		}
+		return nil, indexes, r.defaultID
+	}
+
+	for name, norm := range norms {
+		input := s
+		if norm != nil {
+			input = norm(s)
+		}
+		indexes[name] = c.blindIndexForColumnWithKey(r, r.defaultID, name, []byte(input))
+	}
+	return c.sealWithKeyID(r, nil, r.defaultID, []byte(s), nil), indexes, r.defaultID
+}
+
+// SealStringDualIndexed encrypts a string and computes two normalized blind
+// indexes under the same key version, for migrating a column to a new
+// normalizer without an atomic reindex:
+//
+//  1. Add a second index column (e.g. email_v2_idx) and write both indexes on
+//     every insert and update.
+//  2. Backfill email_v2_idx for existing rows, keeping reads on email_idx
+//     (SearchConditionStringNormalized("email", ..., oldNorm)).
+//  3. Once the backfill completes, search "email_v2" with newNorm, stop
+//     writing email_idx, and drop it.
+//
+// The key version is embedded in the ciphertext (see ExtractKeyID).
+// A nil Normalizer indexes s as is.
+// With WithEmptyStringAsNull and s == "", all three results are nil.
+func (c *Cipher) SealStringDualIndexed(s string, oldNorm, newNorm Normalizer) (ciphertext []byte, oldIdx, newIdx []byte) {
+	r := c.mustAcquireWritable()
+	defer r.release()
+	if c.config.emptyStringAsNull && s == "" {
+		return nil, nil, nil
+	}
+	oldInput, newInput := s, s
+	if oldNorm != nil {
+		oldInput = oldNorm(s)
+	}
+	if newNorm != nil {
+		newInput = newNorm(s)
+	}
+	oldIdx = c.computeHMAC(r, r.defaultID, []byte(oldInput))
+	newIdx = c.computeHMAC(r, r.defaultID, []byte(newInput))
+	return c.sealWithKeyID(r, nil, r.defaultID, []byte(s), nil), oldIdx, newIdx
 }
 
 // SealIndexed encrypts bytes and computes blind index.
+// For a normalized index over byte input, use SealBytesIndexedNormalized.
 func (c *Cipher) SealIndexed(plaintext []byte) *SealedValue {
 	if plaintext == nil {
 		return c.nullSealedValue()
 	}
-	return &SealedValue{
-		Ciphertext: c.Seal(plaintext),
-		BlindIndex: c.BlindIndex(plaintext),
-		KeyID:      c.defaultID,
+	return c.sealIndexed(plaintext, plaintext)
+}
+
+// SealBytesIndexedNormalized is SealStringIndexedNormalized for byte input.
+// The original bytes are preserved in the ciphertext; the blind index is
+// computed over norm(string(data)). Returns a NULL SealedValue if data is nil.
+//
+// Search with SearchConditionNormalized using the same normalizer.
+func (c *Cipher) SealBytesIndexedNormalized(data []byte, norm Normalizer) *SealedValue {
+	if data == nil {
+		return c.nullSealedValue()
 	}
+	normalized := norm(string(data))
+	// Original preserved in ciphertext; normalized for search
+	return c.sealIndexed(data, []byte(normalized))
 }
 
 // SealJSON encrypts a JSON-serializable value.
 func SealJSON[T any](c *Cipher, data T) ([]byte, error) {
+	if err := c.checkWritable(); err != nil {
+		return nil, err
+	}
 	jsonBytes, err := json.Marshal(data)
 	if err != nil {
 		return nil, err
@@ -135,18 +357,94 @@ func OpenJSON[T any](c *Cipher, ciphertext []byte) (T, error) {
 	return result, nil
 }
 
+// OpenJSONPtr decrypts and unmarshals JSON data into a new *T.
+// Returns nil if ciphertext is nil (NULL preservation), so callers can tell a
+// NULL column from a stored empty value: NULL gives nil, SealJSON(c, "") gives
+// a pointer to "".
+//
+// SealJSON ignores WithEmptyStringAsNull: it always seals "" as an empty
+// JSON string, never as NULL. Only a nil ciphertext reads back as nil.
+func OpenJSONPtr[T any](c *Cipher, ciphertext []byte) (*T, error) {
+	if ciphertext == nil {
+		return nil, nil
+	}
+	result, err := OpenJSON[T](c, ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// SealCanonicalJSON encrypts a JSON-serializable value in canonical form:
+// compact, with object keys sorted at every level (including struct fields)
+// and numbers kept exactly as encoding/json wrote them.
+// Equal values therefore always produce the same plaintext, regardless of
+// struct field order or how the value was built. Decrypt with OpenJSON.
+func SealCanonicalJSON[T any](c *Cipher, data T) ([]byte, error) {
+	if err := c.checkWritable(); err != nil {
+		return nil, err
+	}
+	jsonBytes, err := CanonicalJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	return c.Seal(jsonBytes), nil
+}
+
 // SealJSONIndexed encrypts JSON data and computes its blind index.
-// The blind index is computed on the JSON serialization.
+// Both are computed on the canonical serialization (see SealCanonicalJSON),
+// since a blind index only matches if equal values serialize identically.
+// Search with SearchCondition over the canonical JSON of the value.
 func SealJSONIndexed[T any](c *Cipher, data T) (*SealedValue, error) {
-	jsonBytes, err := json.Marshal(data)
+	if err := c.checkWritable(); err != nil {
+		return nil, err
+	}
+	jsonBytes, err := CanonicalJSON(data)
 	if err != nil {
 		return nil, err
 	}
-	return &SealedValue{
-		Ciphertext: c.Seal(jsonBytes),
-		BlindIndex: c.BlindIndex(jsonBytes),
-		KeyID:      c.defaultID,
-	}, nil
+	return c.sealIndexed(jsonBytes, jsonBytes), nil
+}
+
+// SealJSONIndexedNormalized is SealJSONIndexed with a normalized blind index:
+// the canonical JSON is encrypted as is, and norm is applied to it before
+// hashing. Use it for case-insensitive search over JSON-encoded identifiers.
+//
+// Search with the same normalizer over the canonical JSON:
+//
+//	canonical, _ := encryptedcol.CanonicalJSON(value)
+//	cond := cipher.SearchConditionStringNormalized("ref", string(canonical), 1, encryptedcol.NormalizeLower)
+//
+// norm sees JSON text, so it should only make changes that keep equal values
+// equal (case folding, Unicode normalization), not strip structure.
+func SealJSONIndexedNormalized[T any](c *Cipher, data T, norm Normalizer) (*SealedValue, error) {
+	if err := c.checkWritable(); err != nil {
+		return nil, err
+	}
+	jsonBytes, err := CanonicalJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	return c.sealIndexed(jsonBytes, []byte(norm(string(jsonBytes)))), nil
+}
+
+// CanonicalJSON returns the canonical serialization used by SealCanonicalJSON
+// and SealJSONIndexed. Use it to compute the search value for a JSON blind index.
+//
+// data is marshaled, then re-encoded through a generic value so that all
+// object keys are sorted (encoding/json sorts map keys).
+func CanonicalJSON(data any) ([]byte, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber() // Keep numbers exactly as written
+	var generic any
+	if err := dec.Decode(&generic); err != nil {
+		return nil, err
+	}
+	return json.Marshal(generic)
 }
 
 // SealInt64 encrypts an int64 value.
@@ -174,6 +472,191 @@ func (c *Cipher) OpenInt64(ciphertext []byte) (int64, error) {
 	return int64(binary.BigEndian.Uint64(plaintext)), nil
 }
 
+// SealInt32 encrypts an int32 value as 4 big-endian bytes.
+func (c *Cipher) SealInt32(n int32) []byte {
+	buf := make([]byte, 4)
+	binary.BigEndian.PutUint32(buf, uint32(n))
+	return c.Seal(buf)
+}
+
+// OpenInt32 decrypts to an int32 value.
+// Returns ErrInvalidFormat unless the plaintext is exactly 4 bytes.
+func (c *Cipher) OpenInt32(ciphertext []byte) (int32, error) {
+	if ciphertext == nil {
+		return 0, ErrWasNull
+	}
+
+	plaintext, err := c.Open(ciphertext)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(plaintext) != 4 {
+		return 0, ErrInvalidFormat
+	}
+
+	return int32(binary.BigEndian.Uint32(plaintext)), nil
+}
+
+// SealUint64 encrypts a uint64 value, such as a snowflake ID, as 8 big-endian bytes.
+// The encoding is the same as SealInt64, so only the interpretation differs.
+func (c *Cipher) SealUint64(n uint64) []byte {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, n)
+	return c.Seal(buf)
+}
+
+// OpenUint64 decrypts to a uint64 value.
+// Returns ErrInvalidFormat unless the plaintext is exactly 8 bytes.
+func (c *Cipher) OpenUint64(ciphertext []byte) (uint64, error) {
+	if ciphertext == nil {
+		return 0, ErrWasNull
+	}
+
+	plaintext, err := c.Open(ciphertext)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(plaintext) != 8 {
+		return 0, ErrInvalidFormat
+	}
+
+	return binary.BigEndian.Uint64(plaintext), nil
+}
+
+// SealBool encrypts a boolean value as a single byte (0x00 or 0x01).
+//
+// NOTE: Booleans are not meant for SealIndexed. A blind index over a
+// two-value domain reveals which rows share the same value, which defeats
+// the purpose of encrypting the field.
+func (c *Cipher) SealBool(b bool) []byte {
+	buf := []byte{0x00}
+	if b {
+		buf[0] = 0x01
+	}
+	return c.Seal(buf)
+}
+
+// OpenBool decrypts to a boolean value.
+// Returns false and ErrWasNull if ciphertext is nil.
+// Returns ErrInvalidFormat unless the plaintext is exactly one byte of 0x00 or 0x01.
+func (c *Cipher) OpenBool(ciphertext []byte) (bool, error) {
+	if ciphertext == nil {
+		return false, ErrWasNull
+	}
+
+	plaintext, err := c.Open(ciphertext)
+	if err != nil {
+		return false, err
+	}
+
+	if len(plaintext) != 1 {
+		return false, ErrInvalidFormat
+	}
+
+	switch plaintext[0] {
+	case 0x00:
+		return false, nil
+	case 0x01:
+		return true, nil
+	default:
+		return false, ErrInvalidFormat
+	}
+}
+
+// uuidSize is the size of a binary UUID.
+const uuidSize = 16
+
+// SealUUID encrypts a UUID in its 16-byte binary form.
+// Accepts [16]byte so any UUID library works without a dependency
+// (e.g. google/uuid.UUID converts directly).
+func (c *Cipher) SealUUID(u [16]byte) []byte {
+	return c.Seal(u[:])
+}
+
+// OpenUUID decrypts to a 16-byte UUID.
+// Returns the zero UUID and ErrWasNull if ciphertext is nil.
+// Returns ErrInvalidFormat unless the plaintext is exactly 16 bytes.
+func (c *Cipher) OpenUUID(ciphertext []byte) ([16]byte, error) {
+	var u [16]byte
+	if ciphertext == nil {
+		return u, ErrWasNull
+	}
+
+	plaintext, err := c.Open(ciphertext)
+	if err != nil {
+		return u, err
+	}
+
+	if len(plaintext) != uuidSize {
+		return u, ErrInvalidFormat
+	}
+
+	copy(u[:], plaintext)
+	return u, nil
+}
+
+// SealUUIDIndexed encrypts a UUID and computes its blind index over the 16 raw bytes.
+// Search with SearchCondition(column, u[:], ...).
+func (c *Cipher) SealUUIDIndexed(u [16]byte) *SealedValue {
+	return c.sealIndexed(u[:], u[:])
+}
+
+// timeEncodedSize is the size of the binary time layout used by SealTime:
+// [unixSeconds:8][nanoseconds:4][zoneOffsetSeconds:4]
+const timeEncodedSize = 16
+
+// SealTime encrypts a time.Time value.
+// The instant and zone offset are preserved; the zone name is not.
+// Monotonic clock readings are stripped so equal wall-clock times encode identically.
+func (c *Cipher) SealTime(t time.Time) []byte {
+	return c.Seal(encodeTime(t))
+}
+
+// encodeTime returns the binary time layout used by SealTime.
+func encodeTime(t time.Time) []byte {
+	t = t.Round(0) // Strip monotonic clock reading
+	_, offset := t.Zone()
+
+	buf := make([]byte, timeEncodedSize)
+	binary.BigEndian.PutUint64(buf[0:8], uint64(t.Unix()))
+	binary.BigEndian.PutUint32(buf[8:12], uint32(t.Nanosecond()))
+	binary.BigEndian.PutUint32(buf[12:16], uint32(int32(offset)))
+	return buf
+}
+
+// OpenTime decrypts to a time.Time value.
+// Returns the zero time and ErrWasNull if ciphertext is nil.
+// The returned time uses a fixed zone with the original offset (UTC if the offset was 0).
+func (c *Cipher) OpenTime(ciphertext []byte) (time.Time, error) {
+	if ciphertext == nil {
+		return time.Time{}, ErrWasNull
+	}
+
+	plaintext, err := c.Open(ciphertext)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if len(plaintext) != timeEncodedSize {
+		return time.Time{}, ErrInvalidFormat
+	}
+
+	sec := int64(binary.BigEndian.Uint64(plaintext[0:8]))
+	nsec := int64(binary.BigEndian.Uint32(plaintext[8:12]))
+	offset := int(int32(binary.BigEndian.Uint32(plaintext[12:16])))
+	if nsec >= int64(time.Second) {
+		return time.Time{}, ErrInvalidFormat
+	}
+
+	loc := time.UTC
+	if offset != 0 {
+		loc = time.FixedZone("", offset)
+	}
+	return time.Unix(sec, nsec).In(loc), nil
+}
+
 // WasNull returns true if the ciphertext represents a NULL value.
 func (c *Cipher) WasNull(ciphertext []byte) bool {
 	return ciphertext == nil
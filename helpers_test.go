@@ -3,6 +3,7 @@ package encryptedcol
 import (
 	"bytes"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -38,6 +39,125 @@ func TestOpenString_Null(t *testing.T) {
 	require.Equal(t, "", result)
 }
 
+func TestOpenStringOr(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	tests := []struct {
+		name       string
+		ciphertext []byte
+		want       string
+	}{
+		{"null", nil, "n/a"},
+		{"value", cipher.SealString("alice"), "alice"},
+		{"empty string", cipher.SealString(""), ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := cipher.OpenStringOr(tt.ciphertext, "n/a")
+			require.NoError(t, err)
+			require.Equal(t, tt.want, s)
+		})
+	}
+
+	// Genuine decryption errors are not masked by the fallback
+	_, err := cipher.OpenStringOr([]byte{0x00}, "n/a")
+	require.ErrorIs(t, err, ErrInvalidFormat)
+}
+
+func TestCompareString(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+	ciphertext := cipher.SealString("alice@example.com")
+
+	tests := []struct {
+		name      string
+		candidate string
+		want      bool
+	}{
+		{"equal", "alice@example.com", true},
+		{"different", "alice@example.org", false},
+		{"prefix", "alice", false},
+		{"longer", "alice@example.com ", false},
+		{"case differs", "Alice@example.com", false},
+		{"empty", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			equal, err := cipher.CompareString(ciphertext, tt.candidate)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, equal)
+		})
+	}
+
+	equal, err := cipher.CompareString(cipher.SealString(""), "")
+	require.NoError(t, err)
+	require.True(t, equal)
+}
+
+func TestCompareString_Errors(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	equal, err := cipher.CompareString(nil, "")
+	require.ErrorIs(t, err, ErrWasNull)
+	require.False(t, equal)
+
+	_, err = cipher.CompareString([]byte{0x00}, "x")
+	require.ErrorIs(t, err, ErrInvalidFormat)
+}
+
+func TestOpenVerifyIndex(t *testing.T) {
+	v1, _ := New(WithKey("v1", testKey("v1")))
+	sealed := v1.SealStringIndexedNormalized("Alice@Example.com", NormalizeEmail)
+	other := v1.SealStringIndexedNormalized("bob@example.com", NormalizeEmail)
+
+	// Rotated cipher still verifies rows indexed under the old key
+	rotated, _ := New(WithKey("v1", testKey("v1")), WithKey("v2", testKey("v2")), WithDefaultKeyID("v2"))
+
+	tests := []struct {
+		name  string
+		c     *Cipher
+		index []byte
+		norm  Normalizer
+		err   error
+	}{
+		{"match", v1, sealed.BlindIndex, NormalizeEmail, nil},
+		{"match old key", rotated, sealed.BlindIndex, NormalizeEmail, nil},
+		{"other row index", v1, other.BlindIndex, NormalizeEmail, ErrIndexMismatch},
+		{"wrong normalizer", v1, sealed.BlindIndex, nil, ErrIndexMismatch},
+		{"missing index", v1, nil, NormalizeEmail, ErrIndexMismatch},
+		{"truncated index", v1, sealed.BlindIndex[:16], NormalizeEmail, ErrIndexMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := tt.c.OpenVerifyIndex(sealed.Ciphertext, tt.index, tt.norm)
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+				require.Empty(t, s)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, "Alice@Example.com", s)
+		})
+	}
+}
+
+func TestOpenVerifyIndex_Errors(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	_, err := cipher.OpenVerifyIndex(nil, nil, nil)
+	require.ErrorIs(t, err, ErrWasNull)
+
+	_, err = cipher.OpenVerifyIndex([]byte{0x00}, nil, nil)
+	require.ErrorIs(t, err, ErrInvalidFormat)
+
+	sealed := cipher.SealStringIndexed("x")
+	cipher.Close()
+	_, err = cipher.OpenVerifyIndex(sealed.Ciphertext, sealed.BlindIndex, nil)
+	require.ErrorIs(t, err, ErrCipherClosed)
+}
+
 func TestSealStringPtr_OpenStringPtr(t *testing.T) {
 	cipher, _ := New(WithKey("v1", testKey("v1")))
 
@@ -94,6 +214,36 @@ func TestSealStringIndexedNormalized(t *testing.T) {
 	require.True(t, bytes.Equal(sealed.BlindIndex, expectedIndex))
 }
 
+func TestSealBytesIndexedNormalized(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	data := []byte(" Alice@Example.COM ")
+	sealed := cipher.SealBytesIndexedNormalized(data, NormalizeEmail)
+
+	// Ciphertext preserves the original bytes
+	result, err := cipher.Open(sealed.Ciphertext)
+	require.NoError(t, err)
+	require.Equal(t, data, result)
+
+	// Blind index matches the string variant and the normalized search
+	require.Equal(t, cipher.SealStringIndexedNormalized(string(data), NormalizeEmail).BlindIndex, sealed.BlindIndex)
+	cond := cipher.SearchConditionNormalized("email", []byte("alice@example.com"), 1, NormalizeEmail)
+	require.Contains(t, cond.Args, sealed.BlindIndex)
+}
+
+func TestSealBytesIndexedNormalized_Null(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	sealed := cipher.SealBytesIndexedNormalized(nil, NormalizeEmail)
+	require.Nil(t, sealed.Ciphertext)
+	require.Nil(t, sealed.BlindIndex)
+	require.Equal(t, "v1", sealed.KeyID)
+
+	// Empty (non-nil) input is a value, not NULL
+	sealed = cipher.SealBytesIndexedNormalized([]byte{}, NormalizeEmail)
+	require.NotNil(t, sealed.Ciphertext)
+}
+
 func TestSealStringIndexed_EmptyStringAsNull(t *testing.T) {
 	cipher, _ := New(
 		WithKey("v1", testKey("v1")),
@@ -164,6 +314,47 @@ func TestSealJSON_Null(t *testing.T) {
 	require.ErrorIs(t, err, ErrWasNull)
 }
 
+func TestOpenJSONPtr(t *testing.T) {
+	for _, name := range []string{"default", "empty string as null"} {
+		t.Run(name, func(t *testing.T) {
+			opts := []Option{WithKey("v1", testKey("v1"))}
+			if name != "default" {
+				opts = append(opts, WithEmptyStringAsNull())
+			}
+			cipher, _ := New(opts...)
+
+			// NULL
+			got, err := OpenJSONPtr[string](cipher, nil)
+			require.NoError(t, err)
+			require.Nil(t, got)
+
+			// Present but empty, whatever WithEmptyStringAsNull says
+			ct, err := SealJSON(cipher, "")
+			require.NoError(t, err)
+			require.NotNil(t, ct)
+			got, err = OpenJSONPtr[string](cipher, ct)
+			require.NoError(t, err)
+			require.NotNil(t, got)
+			require.Equal(t, "", *got)
+
+			// Value
+			ct, err = SealJSON(cipher, "hello")
+			require.NoError(t, err)
+			got, err = OpenJSONPtr[string](cipher, ct)
+			require.NoError(t, err)
+			require.Equal(t, "hello", *got)
+		})
+	}
+}
+
+func TestOpenJSONPtr_Error(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	got, err := OpenJSONPtr[int](cipher, cipher.Seal([]byte("not json")))
+	require.Error(t, err)
+	require.Nil(t, got)
+}
+
 func TestSealJSONIndexed(t *testing.T) {
 	cipher, _ := New(WithKey("v1", testKey("v1")))
 
@@ -180,6 +371,110 @@ func TestSealJSONIndexed(t *testing.T) {
 	require.NotNil(t, sealed.BlindIndex)
 }
 
+func TestCanonicalJSON(t *testing.T) {
+	type Inner struct {
+		Zeta  int `json:"zeta"`
+		Alpha int `json:"alpha"`
+	}
+	type Outer struct {
+		Name  string         `json:"name"`
+		Inner Inner          `json:"inner"`
+		Tags  map[string]any `json:"tags"`
+		Big   int64          `json:"big"`
+	}
+
+	out, err := CanonicalJSON(Outer{
+		Name:  "a<b",
+		Inner: Inner{Zeta: 1, Alpha: 2},
+		Tags:  map[string]any{"y": 1.5, "x": []any{"b", "a"}},
+		Big:   9007199254740993, // Not representable as float64
+	})
+	require.NoError(t, err)
+	require.Equal(t, `{"big":9007199254740993,"inner":{"alpha":2,"zeta":1},"name":"a\u003cb","tags":{"x":["b","a"],"y":1.5}}`, string(out))
+
+	_, err = CanonicalJSON(make(chan int))
+	require.Error(t, err)
+}
+
+func TestSealJSONIndexed_Canonical(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	type AB struct {
+		A int `json:"a"`
+		B int `json:"b"`
+	}
+	type BA struct {
+		B int `json:"b"`
+		A int `json:"a"`
+	}
+
+	// Semantically equal objects with different field orders share an index
+	s1, err := SealJSONIndexed(cipher, AB{A: 1, B: 2})
+	require.NoError(t, err)
+	s2, err := SealJSONIndexed(cipher, BA{B: 2, A: 1})
+	require.NoError(t, err)
+	s3, err := SealJSONIndexed(cipher, map[string]int{"b": 2, "a": 1})
+	require.NoError(t, err)
+	require.Equal(t, s1.BlindIndex, s2.BlindIndex)
+	require.Equal(t, s1.BlindIndex, s3.BlindIndex)
+
+	// The search value is the canonical JSON
+	canonical, _ := CanonicalJSON(BA{B: 2, A: 1})
+	require.Equal(t, s1.BlindIndex, cipher.BlindIndex(canonical))
+
+	result, err := OpenJSON[BA](cipher, s1.Ciphertext)
+	require.NoError(t, err)
+	require.Equal(t, BA{B: 2, A: 1}, result)
+}
+
+func TestSealJSONIndexedNormalized(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	type Ref struct {
+		Kind string `json:"kind"`
+		ID   string `json:"id"`
+	}
+
+	s1, err := SealJSONIndexedNormalized(cipher, Ref{Kind: "User", ID: "ABC-123"}, NormalizeLower)
+	require.NoError(t, err)
+	s2, err := SealJSONIndexedNormalized(cipher, map[string]string{"id": "abc-123", "kind": "user"}, NormalizeLower)
+	require.NoError(t, err)
+	require.Equal(t, s1.BlindIndex, s2.BlindIndex, "case-insensitive match")
+	require.Equal(t, "v1", s1.KeyID)
+
+	// The original object is stored, not the normalized form
+	result, err := OpenJSON[Ref](cipher, s1.Ciphertext)
+	require.NoError(t, err)
+	require.Equal(t, Ref{Kind: "User", ID: "ABC-123"}, result)
+
+	// Search over the canonical JSON with the same normalizer
+	canonical, _ := CanonicalJSON(Ref{Kind: "USER", ID: "abc-123"})
+	cond := cipher.SearchConditionStringNormalized("ref", string(canonical), 1, NormalizeLower)
+	require.Equal(t, s1.BlindIndex, cond.Args[1])
+
+	// NormalizeNone matches SealJSONIndexed
+	plain, _ := SealJSONIndexed(cipher, Ref{Kind: "User", ID: "ABC-123"})
+	none, _ := SealJSONIndexedNormalized(cipher, Ref{Kind: "User", ID: "ABC-123"}, NormalizeNone)
+	require.Equal(t, plain.BlindIndex, none.BlindIndex)
+
+	_, err = SealJSONIndexedNormalized(cipher, func() {}, NormalizeLower)
+	require.Error(t, err)
+}
+
+func TestSealCanonicalJSON(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	ct, err := SealCanonicalJSON(cipher, map[string]any{"b": true, "a": nil})
+	require.NoError(t, err)
+
+	plaintext, err := cipher.Open(ct)
+	require.NoError(t, err)
+	require.Equal(t, `{"a":null,"b":true}`, string(plaintext))
+
+	_, err = SealCanonicalJSON(cipher, make(chan int))
+	require.Error(t, err)
+}
+
 func TestSealInt64_OpenInt64(t *testing.T) {
 	cipher, _ := New(WithKey("v1", testKey("v1")))
 
@@ -213,6 +508,73 @@ func TestOpenInt64_Null(t *testing.T) {
 	require.Equal(t, int64(0), result)
 }
 
+func TestSealInt32_OpenInt32(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	tests := []int32{0, 1, -1, 42, -42, 2147483647, -2147483648}
+
+	for _, n := range tests {
+		ciphertext := cipher.SealInt32(n)
+		require.NotNil(t, ciphertext)
+
+		result, err := cipher.OpenInt32(ciphertext)
+		require.NoError(t, err)
+		require.Equal(t, n, result)
+	}
+}
+
+func TestSealUint64_OpenUint64(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	tests := []uint64{
+		0,
+		1,
+		1 << 63,              // above max int64
+		18446744073709551615, // max uint64
+	}
+
+	for _, n := range tests {
+		ciphertext := cipher.SealUint64(n)
+		require.NotNil(t, ciphertext)
+
+		result, err := cipher.OpenUint64(ciphertext)
+		require.NoError(t, err)
+		require.Equal(t, n, result)
+	}
+}
+
+func TestOpenInt32_OpenUint64_Errors(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	tests := []struct {
+		name       string
+		ciphertext []byte
+		err        error
+	}{
+		{"null", nil, ErrWasNull},
+		{"too short", cipher.Seal([]byte{0x01, 0x02, 0x03}), ErrInvalidFormat},
+		{"too long", cipher.Seal(make([]byte, 9)), ErrInvalidFormat},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n32, err := cipher.OpenInt32(tt.ciphertext)
+			require.ErrorIs(t, err, tt.err)
+			require.Equal(t, int32(0), n32)
+
+			n64, err := cipher.OpenUint64(tt.ciphertext)
+			require.ErrorIs(t, err, tt.err)
+			require.Equal(t, uint64(0), n64)
+		})
+	}
+
+	// Widths are not interchangeable
+	_, err := cipher.OpenInt32(cipher.SealUint64(1))
+	require.ErrorIs(t, err, ErrInvalidFormat)
+	_, err = cipher.OpenUint64(cipher.SealInt32(1))
+	require.ErrorIs(t, err, ErrInvalidFormat)
+}
+
 func TestWasNull(t *testing.T) {
 	cipher, _ := New(WithKey("v1", testKey("v1")))
 
@@ -263,6 +625,37 @@ func TestSealedValue_KeyIDSet(t *testing.T) {
 	require.Equal(t, "v2", sealed.KeyID)
 }
 
+func TestSealedValue_Columns(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	sealed := cipher.SealStringIndexed("alice@example.com")
+	require.False(t, sealed.IsNull())
+	require.Equal(t, map[string]any{
+		"email_encrypted": sealed.Ciphertext,
+		"email_idx":       sealed.BlindIndex,
+		"key_id":          "v1",
+	}, sealed.Columns("email"))
+
+	// NULL maps the data columns to nil
+	null := cipher.SealIndexed(nil)
+	require.True(t, null.IsNull())
+	cols := null.Columns("email")
+	require.Nil(t, cols["email_encrypted"])
+	require.Nil(t, cols["email_idx"])
+	require.Equal(t, "v1", cols["key_id"])
+
+	var missing *SealedValue
+	require.True(t, missing.IsNull())
+	require.Equal(t, map[string]any{
+		"email_encrypted": []byte(nil),
+		"email_idx":       []byte(nil),
+		"key_id":          "",
+	}, missing.Columns("email"))
+	require.Panics(t, func() { missing.Columns("email; DROP TABLE users") })
+
+	require.Panics(t, func() { sealed.Columns("email; DROP TABLE users") })
+}
+
 func TestSealJSON_MarshalError(t *testing.T) {
 	cipher, _ := New(WithKey("v1", testKey("v1")))
 
@@ -388,3 +781,268 @@ func TestOpenStringPtr_InvalidCiphertext(t *testing.T) {
 	require.ErrorIs(t, err, ErrInvalidFormat)
 	require.Nil(t, result)
 }
+
+func TestSealTime_OpenTime(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	tests := []struct {
+		name string
+		t    time.Time
+	}{
+		{"utc", time.Date(2024, 3, 15, 10, 30, 0, 123456789, time.UTC)},
+		{"positive offset", time.Date(2024, 3, 15, 10, 30, 0, 0, time.FixedZone("IST", 5*3600+1800))},
+		{"negative offset", time.Date(1999, 12, 31, 23, 59, 59, 999999999, time.FixedZone("EST", -5*3600))},
+		{"zero time", time.Time{}},
+		{"before unix epoch", time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"far future", time.Date(9999, 12, 31, 23, 59, 59, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ciphertext := cipher.SealTime(tt.t)
+			result, err := cipher.OpenTime(ciphertext)
+			require.NoError(t, err)
+			require.True(t, tt.t.Equal(result))
+
+			_, wantOffset := tt.t.Zone()
+			_, gotOffset := result.Zone()
+			require.Equal(t, wantOffset, gotOffset)
+		})
+	}
+}
+
+func TestSealTime_StripsMonotonic(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	now := time.Now()
+	ciphertext := cipher.SealTime(now)
+	result, err := cipher.OpenTime(ciphertext)
+	require.NoError(t, err)
+	require.Equal(t, now.Round(0).UnixNano(), result.UnixNano())
+	require.NotContains(t, result.String(), "m=")
+}
+
+func TestOpenTime_Null(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	result, err := cipher.OpenTime(nil)
+	require.ErrorIs(t, err, ErrWasNull)
+	require.True(t, result.IsZero())
+}
+
+func TestOpenTime_InvalidLength(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	ciphertext := cipher.Seal([]byte{0x01, 0x02, 0x03, 0x04})
+	_, err := cipher.OpenTime(ciphertext)
+	require.ErrorIs(t, err, ErrInvalidFormat)
+}
+
+func TestSealBool_OpenBool(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	for _, b := range []bool{true, false} {
+		ciphertext := cipher.SealBool(b)
+		result, err := cipher.OpenBool(ciphertext)
+		require.NoError(t, err)
+		require.Equal(t, b, result)
+	}
+}
+
+func TestOpenBool_Null(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	result, err := cipher.OpenBool(nil)
+	require.ErrorIs(t, err, ErrWasNull)
+	require.False(t, result)
+}
+
+func TestOpenBool_InvalidPayload(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	tests := []struct {
+		name      string
+		plaintext []byte
+	}{
+		{"empty", []byte{}},
+		{"two bytes", []byte{0x00, 0x01}},
+		{"value 2", []byte{0x02}},
+		{"string true", []byte("true")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ciphertext := cipher.Seal(tt.plaintext)
+			_, err := cipher.OpenBool(ciphertext)
+			require.ErrorIs(t, err, ErrInvalidFormat)
+		})
+	}
+}
+
+func TestSealUUID_OpenUUID(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	tests := []struct {
+		name string
+		u    [16]byte
+	}{
+		{"zero", [16]byte{}},
+		{"v4", [16]byte{0x55, 0x0e, 0x84, 0x00, 0xe2, 0x9b, 0x41, 0xd4, 0xa7, 0x16, 0x44, 0x66, 0x55, 0x44, 0x00, 0x00}},
+		{"max", [16]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ciphertext := cipher.SealUUID(tt.u)
+			result, err := cipher.OpenUUID(ciphertext)
+			require.NoError(t, err)
+			require.Equal(t, tt.u, result)
+		})
+	}
+}
+
+func TestSealUUID_SmallerThanString(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")), WithCompressionDisabled())
+
+	binary := cipher.SealUUID([16]byte{1})
+	text := cipher.SealString("01000000-0000-0000-0000-000000000000")
+	require.Equal(t, 20, len(text)-len(binary))
+}
+
+func TestOpenUUID_Null(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	result, err := cipher.OpenUUID(nil)
+	require.ErrorIs(t, err, ErrWasNull)
+	require.Equal(t, [16]byte{}, result)
+}
+
+func TestOpenUUID_InvalidLength(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	for _, n := range []int{0, 15, 17, 36} {
+		_, err := cipher.OpenUUID(cipher.Seal(make([]byte, n)))
+		require.ErrorIs(t, err, ErrInvalidFormat, "length %d", n)
+	}
+}
+
+func TestSealUUIDIndexed(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+	u := [16]byte{0x55, 0x0e, 0x84, 0x00}
+
+	sealed := cipher.SealUUIDIndexed(u)
+	require.Equal(t, "v1", sealed.KeyID)
+	require.Equal(t, cipher.BlindIndex(u[:]), sealed.BlindIndex)
+
+	result, err := cipher.OpenUUID(sealed.Ciphertext)
+	require.NoError(t, err)
+	require.Equal(t, u, result)
+
+	// Deterministic index, randomized ciphertext
+	again := cipher.SealUUIDIndexed(u)
+	require.Equal(t, sealed.BlindIndex, again.BlindIndex)
+	require.NotEqual(t, sealed.Ciphertext, again.Ciphertext)
+}
+
+func TestSealStringIndexedForColumn(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	sealed := cipher.SealStringIndexedForColumn("email", "alice@example.com")
+
+	result, err := cipher.OpenString(sealed.Ciphertext)
+	require.NoError(t, err)
+	require.Equal(t, "alice@example.com", result)
+	require.Equal(t, cipher.BlindIndexForColumn("email", []byte("alice@example.com")), sealed.BlindIndex)
+	require.Equal(t, "v1", sealed.KeyID)
+}
+
+func TestSealStringMultiIndexed(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")), WithKey("v2", testKey("v2")), WithDefaultKeyID("v2"))
+
+	ct, indexes, keyID := cipher.SealStringMultiIndexed("Alice@Example.com", map[string]Normalizer{
+		"email":       NormalizeEmail,
+		"email_exact": nil,
+	})
+	require.Equal(t, "v2", keyID)
+
+	result, err := cipher.OpenString(ct)
+	require.NoError(t, err)
+	require.Equal(t, "Alice@Example.com", result)
+
+	require.Len(t, indexes, 2)
+	require.Equal(t, cipher.BlindIndexForColumn("email", []byte("alice@example.com")), indexes["email"])
+	require.Equal(t, cipher.BlindIndexForColumn("email_exact", []byte("Alice@Example.com")), indexes["email_exact"])
+
+	// Each index is found by SearchConditionForColumn under its own name
+	cond := cipher.SearchConditionForColumn("email_exact", []byte("Alice@Example.com"), 1)
+	require.Contains(t, cond.Args, indexes["email_exact"])
+	cond = cipher.SearchConditionForColumn("email", []byte(NormalizeEmail("ALICE@example.com")), 1)
+	require.Contains(t, cond.Args, indexes["email"])
+
+	// Identical normalized input under different names yields unrelated indexes
+	_, indexes, _ = cipher.SealStringMultiIndexed("alice", map[string]Normalizer{"a": nil, "b": NormalizeLower})
+	require.NotEqual(t, indexes["a"], indexes["b"])
+}
+
+func TestSealStringMultiIndexed_Null(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")), WithEmptyStringAsNull())
+
+	ct, indexes, keyID := cipher.SealStringMultiIndexed("", map[string]Normalizer{"email": NormalizeEmail})
+	require.Nil(t, ct)
+	require.Contains(t, indexes, "email")
+	require.Nil(t, indexes["email"])
+	require.Equal(t, "v1", keyID)
+}
+
+func TestSealStringMultiIndexed_InvalidName(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	require.Panics(t, func() {
+		cipher.SealStringMultiIndexed("x", map[string]Normalizer{"bad name": nil})
+	})
+}
+
+func TestSealStringDualIndexed(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	ct, oldIdx, newIdx := cipher.SealStringDualIndexed("Stra\u00dfe@Example.com", NormalizeLower, NormalizeEmailCaseFold)
+
+	result, err := cipher.OpenString(ct)
+	require.NoError(t, err)
+	require.Equal(t, "Stra\u00dfe@Example.com", result)
+
+	// Each index matches searches with its own normalizer
+	oldCond := cipher.SearchConditionStringNormalized("email", "STRA\u00dfE@example.com", 1, NormalizeLower)
+	require.Equal(t, oldIdx, oldCond.Args[1])
+	newCond := cipher.SearchConditionStringNormalized("email_v2", "STRASSE@example.com", 1, NormalizeEmailCaseFold)
+	require.Equal(t, newIdx, newCond.Args[1])
+	require.NotEqual(t, oldIdx, newIdx)
+
+	// Same as separate SealStringIndexedNormalized calls
+	require.Equal(t, cipher.SealStringIndexedNormalized("Stra\u00dfe@Example.com", NormalizeLower).BlindIndex, oldIdx)
+}
+
+func TestSealStringDualIndexed_NilNormalizer(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	// A nil normalizer indexes the raw value, as in SealStringMultiIndexed
+	require.NotPanics(t, func() {
+		_, oldIdx, newIdx := cipher.SealStringDualIndexed("Alice@Example.com", nil, NormalizeEmail)
+		require.Equal(t, cipher.BlindIndexString("Alice@Example.com"), oldIdx)
+		require.Equal(t, cipher.BlindIndexString("alice@example.com"), newIdx)
+	})
+	require.NotPanics(t, func() {
+		_, oldIdx, newIdx := cipher.SealStringDualIndexed("Alice@Example.com", NormalizeLower, nil)
+		require.Equal(t, cipher.BlindIndexString("alice@example.com"), oldIdx)
+		require.Equal(t, cipher.BlindIndexString("Alice@Example.com"), newIdx)
+	})
+}
+
+func TestSealStringDualIndexed_Null(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")), WithEmptyStringAsNull())
+
+	ct, oldIdx, newIdx := cipher.SealStringDualIndexed("", NormalizeLower, NormalizeEmailCaseFold)
+	require.Nil(t, ct)
+	require.Nil(t, oldIdx)
+	require.Nil(t, newIdx)
+}
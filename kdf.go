@@ -1,55 +1,160 @@
 package encryptedcol
 
 import (
+	"crypto/cipher"
+	"crypto/rand"
 	"crypto/sha256"
+	"encoding/hex"
 	"io"
+	"strings"
 
+	"golang.org/x/crypto/chacha20poly1305"
 	"golang.org/x/crypto/hkdf"
 )
 
 // Info strings for HKDF derivation - distinct strings ensure separate keys
 const (
-	infoEncryption = "encryptedcol-encryption"
-	infoBlindIndex = "encryptedcol-blind-index"
+	infoEncryption          = "encryptedcol-encryption"
+	infoEncryptionAESGCM    = "encryptedcol-encryption-aes-256-gcm"
+	infoEncryptionAESGCMSIV = "encryptedcol-encryption-aes-256-gcm-siv"
+	infoEncryptionChaCha    = "encryptedcol-encryption-chacha20-poly1305"
+	infoBlindIndex          = "encryptedcol-blind-index"
+	infoDeterministic       = "encryptedcol-deterministic-nonce"
+	infoTenant              = "encryptedcol-tenant"
+	infoFingerprint         = "encryptedcol-fingerprint"
 )
 
+// masterKeySize is the required master key length in bytes.
+const masterKeySize = 32
+
+// GenerateKey returns a new random 32-byte master key from crypto/rand.
+func GenerateKey() ([]byte, error) {
+	key := make([]byte, masterKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// GenerateKeyHex returns a new random master key as 64 hex characters,
+// suitable for environment variables and secrets managers.
+func GenerateKeyHex() (string, error) {
+	key, err := GenerateKey()
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(key), nil
+}
+
+// ParseKeyHex decodes a hex-encoded master key, ignoring surrounding whitespace.
+// Returns ErrInvalidKeySize unless s decodes to exactly 32 bytes.
+func ParseKeyHex(s string) ([]byte, error) {
+	key, err := hex.DecodeString(strings.TrimSpace(s))
+	if err != nil || len(key) != masterKeySize {
+		return nil, ErrInvalidKeySize
+	}
+	return key, nil
+}
+
 // derivedKeys holds the encryption and HMAC keys derived from a master key.
 // These are cached at initialization to avoid repeated HKDF derivation.
 type derivedKeys struct {
-	encryption [32]byte // XSalsa20-Poly1305 key
-	hmac       [32]byte // HMAC-SHA256 key for blind indexes
+	encryption [32]byte    // XSalsa20-Poly1305 key
+	aesgcm     [32]byte    // AES-256-GCM key
+	gcm        cipher.AEAD // AES-256-GCM instance built from aesgcm
+	aesgcmsiv  [32]byte    // AES-256-GCM-SIV key-generating key
+	gcmsiv     cipher.AEAD // AES-256-GCM-SIV instance built from aesgcmsiv
+	chachaKey  [32]byte    // ChaCha20-Poly1305 key
+	chacha     cipher.AEAD // ChaCha20-Poly1305 instance built from chachaKey
+	hmac       [32]byte    // HMAC-SHA256 key for blind indexes
+	siv        [32]byte    // HMAC-SHA256 key for deterministic (synthetic) nonces
+	tenant     [32]byte    // HKDF input key for per-tenant master keys (ForTenant)
+	check      [32]byte    // public key-check value for Fingerprint; never used as a key
 }
 
-// deriveKeys derives encryption and HMAC keys from a master key using HKDF-SHA256.
+// deriveKeys derives encryption and HMAC keys from a master key using HKDF-SHA256
+// with the given salt (nil unless WithHKDFSalt is set).
 // The master key must be exactly 32 bytes.
 //
 // The derivation uses distinct info strings to ensure cryptographic separation:
 //   - Encryption key: HKDF(masterKey, info="encryptedcol-encryption")
+//   - AES-GCM key: HKDF(masterKey, info="encryptedcol-encryption-aes-256-gcm")
+//   - AES-GCM-SIV key: HKDF(masterKey, info="encryptedcol-encryption-aes-256-gcm-siv")
+//   - ChaCha20-Poly1305 key: HKDF(masterKey, info="encryptedcol-encryption-chacha20-poly1305")
 //   - HMAC key: HKDF(masterKey, info="encryptedcol-blind-index")
-func deriveKeys(masterKey []byte) (*derivedKeys, error) {
-	if len(masterKey) != 32 {
+//   - Synthetic nonce key: HKDF(masterKey, info="encryptedcol-deterministic-nonce")
+//   - Tenant root key: HKDF(masterKey, info="encryptedcol-tenant")
+//   - Key-check value: HKDF(masterKey, info="encryptedcol-fingerprint")
+func deriveKeys(masterKey, salt []byte) (*derivedKeys, error) {
+	if len(masterKey) != masterKeySize {
 		return nil, ErrInvalidKeySize
 	}
 
 	keys := &derivedKeys{}
 
 	// Derive encryption key
-	if err := hkdfDerive(masterKey, infoEncryption, keys.encryption[:]); err != nil {
+	if err := hkdfDerive(masterKey, salt, infoEncryption, keys.encryption[:]); err != nil {
+		return nil, err
+	}
+
+	// Derive a separate AES-256-GCM key so keys are never shared across AEADs
+	if err := hkdfDerive(masterKey, salt, infoEncryptionAESGCM, keys.aesgcm[:]); err != nil {
+		return nil, err
+	}
+	gcm, err := newAESGCM(&keys.aesgcm)
+	if err != nil {
+		return nil, err
+	}
+	keys.gcm = gcm
+
+	// Likewise for AES-256-GCM-SIV
+	if err := hkdfDerive(masterKey, salt, infoEncryptionAESGCMSIV, keys.aesgcmsiv[:]); err != nil {
+		return nil, err
+	}
+	gcmsiv, err := newAESGCMSIV(keys.aesgcmsiv[:])
+	if err != nil {
+		return nil, err
+	}
+	keys.gcmsiv = gcmsiv
+
+	// Likewise for ChaCha20-Poly1305
+	if err := hkdfDerive(masterKey, salt, infoEncryptionChaCha, keys.chachaKey[:]); err != nil {
+		return nil, err
+	}
+	chacha, err := chacha20poly1305.New(keys.chachaKey[:])
+	if err != nil {
 		return nil, err
 	}
+	keys.chacha = chacha
 
 	// Derive HMAC key for blind indexes
-	if err := hkdfDerive(masterKey, infoBlindIndex, keys.hmac[:]); err != nil {
+	if err := hkdfDerive(masterKey, salt, infoBlindIndex, keys.hmac[:]); err != nil {
+		return nil, err
+	}
+
+	// Derive synthetic nonce key for SealDeterministic
+	if err := hkdfDerive(masterKey, salt, infoDeterministic, keys.siv[:]); err != nil {
+		return nil, err
+	}
+
+	// Derive tenant root key for ForTenant
+	if err := hkdfDerive(masterKey, salt, infoTenant, keys.tenant[:]); err != nil {
+		return nil, err
+	}
+
+	// Derive key-check value for Fingerprint
+	if err := hkdfDerive(masterKey, salt, infoFingerprint, keys.check[:]); err != nil {
 		return nil, err
 	}
 
 	return keys, nil
 }
 
-// hkdfDerive performs HKDF-SHA256 key derivation with the given info string.
-// No salt is used (nil salt means HKDF uses a zero-filled salt of HashLen bytes).
-func hkdfDerive(masterKey []byte, info string, out []byte) error {
-	reader := hkdf.New(sha256.New, masterKey, nil, []byte(info))
+// hkdfDerive performs HKDF-SHA256 key derivation with the given salt and info
+// string. A nil salt (the default) means HKDF uses a zero-filled salt of
+// HashLen bytes.
+func hkdfDerive(masterKey, salt []byte, info string, out []byte) error {
+	reader := hkdf.New(sha256.New, masterKey, salt, []byte(info))
 	_, err := io.ReadFull(reader, out)
 	return err
 }
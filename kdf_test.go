@@ -2,6 +2,7 @@ package encryptedcol
 
 import (
 	"bytes"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -11,10 +12,10 @@ func TestDeriveKeys_Deterministic(t *testing.T) {
 	masterKey := []byte("01234567890123456789012345678901") // 32 bytes
 
 	// Derive keys twice
-	keys1, err := deriveKeys(masterKey)
+	keys1, err := deriveKeys(masterKey, nil)
 	require.NoError(t, err)
 
-	keys2, err := deriveKeys(masterKey)
+	keys2, err := deriveKeys(masterKey, nil)
 	require.NoError(t, err)
 
 	// Same master key should produce same derived keys
@@ -26,10 +27,10 @@ func TestDeriveKeys_DifferentMasterKeys(t *testing.T) {
 	masterKey1 := []byte("01234567890123456789012345678901")
 	masterKey2 := []byte("01234567890123456789012345678902") // One byte different
 
-	keys1, err := deriveKeys(masterKey1)
+	keys1, err := deriveKeys(masterKey1, nil)
 	require.NoError(t, err)
 
-	keys2, err := deriveKeys(masterKey2)
+	keys2, err := deriveKeys(masterKey2, nil)
 	require.NoError(t, err)
 
 	// Different master keys should produce different derived keys
@@ -40,7 +41,7 @@ func TestDeriveKeys_DifferentMasterKeys(t *testing.T) {
 func TestDeriveKeys_EncryptionAndHMACAreDifferent(t *testing.T) {
 	masterKey := []byte("01234567890123456789012345678901")
 
-	keys, err := deriveKeys(masterKey)
+	keys, err := deriveKeys(masterKey, nil)
 	require.NoError(t, err)
 
 	// Encryption and HMAC keys should be different (derived with different info strings)
@@ -63,7 +64,7 @@ func TestDeriveKeys_InvalidKeySize(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			key := make([]byte, tt.keySize)
-			_, err := deriveKeys(key)
+			_, err := deriveKeys(key, nil)
 			require.ErrorIs(t, err, ErrInvalidKeySize)
 		})
 	}
@@ -71,7 +72,7 @@ func TestDeriveKeys_InvalidKeySize(t *testing.T) {
 
 func TestDeriveKeys_32BytesExactly(t *testing.T) {
 	key := make([]byte, 32)
-	keys, err := deriveKeys(key)
+	keys, err := deriveKeys(key, nil)
 	require.NoError(t, err)
 	require.NotNil(t, keys)
 	require.Len(t, keys.encryption[:], 32)
@@ -82,7 +83,7 @@ func TestDeriveKeys_OutputIsNonZero(t *testing.T) {
 	// Even with a zero master key, HKDF should produce non-trivial output
 	masterKey := make([]byte, 32)
 
-	keys, err := deriveKeys(masterKey)
+	keys, err := deriveKeys(masterKey, nil)
 	require.NoError(t, err)
 
 	// Check encryption key is not all zeros
@@ -97,10 +98,10 @@ func TestHkdfDerive_DifferentInfoProducesDifferentKeys(t *testing.T) {
 	out1 := make([]byte, 32)
 	out2 := make([]byte, 32)
 
-	err := hkdfDerive(masterKey, "info1", out1)
+	err := hkdfDerive(masterKey, nil, "info1", out1)
 	require.NoError(t, err)
 
-	err = hkdfDerive(masterKey, "info2", out2)
+	err = hkdfDerive(masterKey, nil, "info2", out2)
 	require.NoError(t, err)
 
 	require.False(t, bytes.Equal(out1, out2), "different info strings should produce different keys")
@@ -112,10 +113,10 @@ func TestHkdfDerive_SameInfoProducesSameKey(t *testing.T) {
 	out1 := make([]byte, 32)
 	out2 := make([]byte, 32)
 
-	err := hkdfDerive(masterKey, "same-info", out1)
+	err := hkdfDerive(masterKey, nil, "same-info", out1)
 	require.NoError(t, err)
 
-	err = hkdfDerive(masterKey, "same-info", out2)
+	err = hkdfDerive(masterKey, nil, "same-info", out2)
 	require.NoError(t, err)
 
 	require.True(t, bytes.Equal(out1, out2), "same info string should produce same key")
@@ -127,7 +128,7 @@ func TestDeriveKeys_KnownVector(t *testing.T) {
 	// Fixed master key for reproducibility
 	masterKey := []byte("AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA") // 32 'A's
 
-	keys, err := deriveKeys(masterKey)
+	keys, err := deriveKeys(masterKey, nil)
 	require.NoError(t, err)
 
 	// These values were computed once and captured as test vectors.
@@ -140,3 +141,91 @@ func TestDeriveKeys_KnownVector(t *testing.T) {
 	require.Equal(t, expectedHMACFirst4, keys.hmac[:4],
 		"hmac key derivation changed - this breaks backward compatibility")
 }
+
+// TestDeriveKeys_KnownVectorSalted pins derivation with a WithHKDFSalt salt.
+func TestDeriveKeys_KnownVectorSalted(t *testing.T) {
+	masterKey := []byte("AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA") // 32 'A's
+
+	keys, err := deriveKeys(masterKey, []byte("example-deployment"))
+	require.NoError(t, err)
+
+	expectedEncFirst4 := []byte{0x96, 0x64, 0x65, 0x3a}
+	expectedHMACFirst4 := []byte{0x4f, 0x20, 0x0b, 0xd7}
+
+	require.Equal(t, expectedEncFirst4, keys.encryption[:4],
+		"salted encryption key derivation changed - this breaks backward compatibility")
+	require.Equal(t, expectedHMACFirst4, keys.hmac[:4],
+		"salted hmac key derivation changed - this breaks backward compatibility")
+}
+
+func TestDeriveKeys_AESGCMKeySeparated(t *testing.T) {
+	keys, err := deriveKeys(testKey("v1"), nil)
+	require.NoError(t, err)
+
+	require.NotEqual(t, keys.encryption, keys.aesgcm, "AEAD keys must differ")
+	require.NotEqual(t, keys.hmac, keys.aesgcm, "AES-GCM and HMAC keys must differ")
+	require.NotNil(t, keys.gcm)
+}
+
+func TestDeriveKeys_ChaChaKeySeparated(t *testing.T) {
+	keys, err := deriveKeys(testKey("v1"), nil)
+	require.NoError(t, err)
+
+	for _, other := range [][32]byte{keys.encryption, keys.aesgcm, keys.aesgcmsiv, keys.hmac} {
+		require.NotEqual(t, other, keys.chachaKey)
+	}
+	require.NotNil(t, keys.chacha)
+}
+
+func TestGenerateKey(t *testing.T) {
+	key1, err := GenerateKey()
+	require.NoError(t, err)
+	require.Len(t, key1, 32)
+
+	key2, err := GenerateKey()
+	require.NoError(t, err)
+	require.False(t, bytes.Equal(key1, key2), "generated keys should be distinct")
+
+	// Generated keys are usable master keys
+	_, err = New(WithKey("v1", key1))
+	require.NoError(t, err)
+}
+
+func TestGenerateKeyHex_RoundTrip(t *testing.T) {
+	s1, err := GenerateKeyHex()
+	require.NoError(t, err)
+	require.Len(t, s1, 64)
+
+	s2, err := GenerateKeyHex()
+	require.NoError(t, err)
+	require.NotEqual(t, s1, s2, "generated keys should be distinct")
+
+	key, err := ParseKeyHex(s1)
+	require.NoError(t, err)
+	require.Len(t, key, 32)
+
+	// Trailing newline from env files/secrets is tolerated
+	key2, err := ParseKeyHex(s1 + "\n")
+	require.NoError(t, err)
+	require.Equal(t, key, key2)
+}
+
+func TestParseKeyHex_Invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"empty", ""},
+		{"too short", strings.Repeat("ab", 31)},
+		{"too long", strings.Repeat("ab", 33)},
+		{"odd length", strings.Repeat("a", 63)},
+		{"not hex", strings.Repeat("zz", 32)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseKeyHex(tt.input)
+			require.ErrorIs(t, err, ErrInvalidKeySize)
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package encryptedcol
+
+import "time"
+
+// KeyMeta is informational metadata about a master key, registered via WithKeyEx.
+// It is never written to ciphertext and does not affect encryption or decryption.
+type KeyMeta struct {
+	CreatedAt time.Time // When the key was created
+	NotAfter  time.Time // Optional: stop using the key for new encryptions after this time (zero = no limit)
+}
+
+// Expired reports whether t is past NotAfter.
+// Always false when NotAfter is zero.
+func (m KeyMeta) Expired(t time.Time) bool {
+	return !m.NotAfter.IsZero() && t.After(m.NotAfter)
+}
+
+// KeyInfo returns the metadata registered for keyID via WithKeyEx.
+// Returns false if keyID is not an active key or was registered without metadata.
+func (c *Cipher) KeyInfo(keyID string) (KeyMeta, bool) {
+	meta, ok := c.config.keyMeta[keyID]
+	if !ok {
+		return KeyMeta{}, false
+	}
+	r, err := c.acquire()
+	if err != nil {
+		return KeyMeta{}, false
+	}
+	defer r.release()
+	if _, active := r.keys[keyID]; !active {
+		return KeyMeta{}, false // Removed by ReloadKeys
+	}
+	return meta, true
+}
@@ -0,0 +1,87 @@
+package encryptedcol
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithKeyEx_KeyInfo(t *testing.T) {
+	created := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	notAfter := created.AddDate(1, 0, 0)
+
+	cipher, err := New(
+		WithKeyEx("v1", testKey("v1"), KeyMeta{CreatedAt: created, NotAfter: notAfter}),
+		WithKey("v2", testKey("v2")),
+	)
+	require.NoError(t, err)
+	require.Equal(t, "v1", cipher.DefaultKeyID())
+
+	meta, ok := cipher.KeyInfo("v1")
+	require.True(t, ok)
+	require.Equal(t, created, meta.CreatedAt)
+	require.Equal(t, notAfter, meta.NotAfter)
+
+	// Registered without metadata
+	_, ok = cipher.KeyInfo("v2")
+	require.False(t, ok)
+
+	// Unknown key
+	_, ok = cipher.KeyInfo("v3")
+	require.False(t, ok)
+}
+
+func TestWithKeyEx_WireFormatUnchanged(t *testing.T) {
+	meta := KeyMeta{CreatedAt: time.Now(), NotAfter: time.Now().Add(-time.Hour)}
+	withMeta, err := New(WithKeyEx("v1", testKey("v1"), meta))
+	require.NoError(t, err)
+	plain, _ := New(WithKey("v1", testKey("v1")))
+
+	// Past NotAfter: Seal still works, and ciphertexts are interchangeable
+	ct := withMeta.Seal([]byte("secret"))
+	pt, err := plain.Open(ct)
+	require.NoError(t, err)
+	require.Equal(t, []byte("secret"), pt)
+
+	require.Equal(t, plain.SealDeterministic([]byte("x")), withMeta.SealDeterministic([]byte("x")))
+	require.Equal(t, plain.BlindIndex([]byte("x")), withMeta.BlindIndex([]byte("x")))
+}
+
+func TestKeyMeta_Expired(t *testing.T) {
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		meta     KeyMeta
+		expected bool
+	}{
+		{"no limit", KeyMeta{}, false},
+		{"future", KeyMeta{NotAfter: now.Add(time.Hour)}, false},
+		{"exactly now", KeyMeta{NotAfter: now}, false},
+		{"past", KeyMeta{NotAfter: now.Add(-time.Second)}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, tt.meta.Expired(now))
+		})
+	}
+}
+
+func TestKeyInfo_AfterReloadAndClose(t *testing.T) {
+	cipher, _ := New(
+		WithKeyEx("v1", testKey("v1"), KeyMeta{CreatedAt: time.Now()}),
+	)
+
+	// v1 dropped by the provider: metadata is no longer reported
+	provider := NewStaticKeyProvider("v2", map[string][]byte{"v2": testKey("v2")})
+	require.NoError(t, cipher.ReloadKeys(provider))
+	_, ok := cipher.KeyInfo("v1")
+	require.False(t, ok)
+
+	cipher2, _ := New(WithKeyEx("v1", testKey("v1"), KeyMeta{CreatedAt: time.Now()}))
+	cipher2.Close()
+	_, ok = cipher2.KeyInfo("v1")
+	require.False(t, ok)
+}
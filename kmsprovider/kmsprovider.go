@@ -0,0 +1,129 @@
+// Package kmsprovider implements encryptedcol.KeyProvider backed by AWS KMS.
+//
+// Master keys are stored as KMS-encrypted data keys (for example, the
+// CiphertextBlob returned by GenerateDataKey with KeySpec AES_256). The
+// provider decrypts every data key once at construction and caches the
+// plaintext keys in memory until Close is called.
+//
+// Example:
+//
+//	cfg, _ := config.LoadDefaultConfig(ctx)
+//	provider, err := kmsprovider.NewKMSKeyProvider(ctx, kms.NewFromConfig(cfg), "v2", map[string][]byte{
+//	    "v1": encryptedKeyV1,
+//	    "v2": encryptedKeyV2,
+//	})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer provider.Close()
+//
+//	cipher, err := encryptedcol.NewWithProvider(provider)
+package kmsprovider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/kms/types"
+
+	"github.com/ai8future/encryptedcol"
+)
+
+// ErrKeyDisabled indicates the KMS key protecting a data key is disabled.
+var ErrKeyDisabled = errors.New("kmsprovider: KMS key is disabled")
+
+// API is the subset of the AWS KMS client used by KMSKeyProvider.
+// *kms.Client satisfies this interface.
+type API interface {
+	Decrypt(ctx context.Context, params *kms.DecryptInput, optFns ...func(*kms.Options)) (*kms.DecryptOutput, error)
+}
+
+// KMSKeyProvider is an encryptedcol.KeyProvider whose keys are decrypted by AWS KMS.
+type KMSKeyProvider struct {
+	keys      map[string][]byte
+	defaultID string
+}
+
+// Compile-time interface check
+var _ encryptedcol.KeyProvider = (*KMSKeyProvider)(nil)
+
+// NewKMSKeyProvider decrypts each encrypted data key in keyIDMap
+// (encryptedcol key ID -> KMS CiphertextBlob) and returns a provider
+// serving the plaintext keys.
+//
+// All keys are decrypted eagerly, so a disabled or inaccessible KMS key
+// fails here rather than on first use. A disabled key returns an error
+// wrapping ErrKeyDisabled that names the affected key ID.
+func NewKMSKeyProvider(ctx context.Context, client API, defaultKeyID string, keyIDMap map[string][]byte) (*KMSKeyProvider, error) {
+	if len(keyIDMap) == 0 {
+		return nil, encryptedcol.ErrNoKeys
+	}
+	if _, ok := keyIDMap[defaultKeyID]; !ok {
+		return nil, encryptedcol.ErrDefaultKeyNotFound
+	}
+
+	p := &KMSKeyProvider{
+		keys:      make(map[string][]byte, len(keyIDMap)),
+		defaultID: defaultKeyID,
+	}
+
+	for keyID, blob := range keyIDMap {
+		out, err := client.Decrypt(ctx, &kms.DecryptInput{CiphertextBlob: blob})
+		if err != nil {
+			p.Close()
+			var disabled *types.DisabledException
+			if errors.As(err, &disabled) {
+				return nil, fmt.Errorf("%w: key_id %q: %v", ErrKeyDisabled, keyID, err)
+			}
+			return nil, fmt.Errorf("kmsprovider: decrypt key_id %q: %w", keyID, err)
+		}
+		if len(out.Plaintext) != 32 {
+			p.Close()
+			return nil, fmt.Errorf("kmsprovider: key_id %q: %w", keyID, encryptedcol.ErrInvalidKeySize)
+		}
+		p.keys[keyID] = out.Plaintext
+	}
+
+	return p, nil
+}
+
+// GetKey implements encryptedcol.KeyProvider.
+func (p *KMSKeyProvider) GetKey(keyID string) ([]byte, error) {
+	key, ok := p.keys[keyID]
+	if !ok {
+		return nil, encryptedcol.ErrKeyNotFound
+	}
+	// Return a copy to prevent external modification
+	keyCopy := make([]byte, len(key))
+	copy(keyCopy, key)
+	return keyCopy, nil
+}
+
+// DefaultKeyID implements encryptedcol.KeyProvider.
+func (p *KMSKeyProvider) DefaultKeyID() string {
+	return p.defaultID
+}
+
+// ActiveKeyIDs implements encryptedcol.KeyProvider.
+func (p *KMSKeyProvider) ActiveKeyIDs() []string {
+	ids := make([]string, 0, len(p.keys))
+	for id := range p.keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Close zeros out all key material from memory.
+// After calling Close, the provider should not be used.
+func (p *KMSKeyProvider) Close() {
+	for _, key := range p.keys {
+		for i := range key {
+			key[i] = 0
+		}
+	}
+	p.keys = nil
+}
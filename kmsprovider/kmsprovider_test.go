@@ -0,0 +1,134 @@
+package kmsprovider
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/kms/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ai8future/encryptedcol"
+)
+
+// fakeKMS "decrypts" a blob by looking it up in a table.
+type fakeKMS struct {
+	plaintexts map[string][]byte
+	errs       map[string]error
+	calls      int
+}
+
+func (f *fakeKMS) Decrypt(_ context.Context, in *kms.DecryptInput, _ ...func(*kms.Options)) (*kms.DecryptOutput, error) {
+	f.calls++
+	blob := string(in.CiphertextBlob)
+	if err, ok := f.errs[blob]; ok {
+		return nil, err
+	}
+	pt, ok := f.plaintexts[blob]
+	if !ok {
+		return nil, &types.InvalidCiphertextException{Message: aws.String("bad blob")}
+	}
+	out := make([]byte, len(pt))
+	copy(out, pt)
+	return &kms.DecryptOutput{Plaintext: out}, nil
+}
+
+func key32(b byte) []byte {
+	k := make([]byte, 32)
+	for i := range k {
+		k[i] = b
+	}
+	return k
+}
+
+func TestNewKMSKeyProvider(t *testing.T) {
+	client := &fakeKMS{plaintexts: map[string][]byte{
+		"blob-v1": key32(1),
+		"blob-v2": key32(2),
+	}}
+
+	p, err := NewKMSKeyProvider(context.Background(), client, "v2", map[string][]byte{
+		"v1": []byte("blob-v1"),
+		"v2": []byte("blob-v2"),
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, client.calls)
+	require.Equal(t, "v2", p.DefaultKeyID())
+	require.Equal(t, []string{"v1", "v2"}, p.ActiveKeyIDs())
+
+	key, err := p.GetKey("v1")
+	require.NoError(t, err)
+	require.Equal(t, key32(1), key)
+
+	// Cached: no further KMS calls
+	_, _ = p.GetKey("v2")
+	require.Equal(t, 2, client.calls)
+
+	_, err = p.GetKey("missing")
+	require.ErrorIs(t, err, encryptedcol.ErrKeyNotFound)
+}
+
+func TestNewKMSKeyProvider_WithCipher(t *testing.T) {
+	client := &fakeKMS{plaintexts: map[string][]byte{"blob": key32(7)}}
+
+	p, err := NewKMSKeyProvider(context.Background(), client, "v1", map[string][]byte{"v1": []byte("blob")})
+	require.NoError(t, err)
+
+	cipher, err := encryptedcol.NewWithProvider(p)
+	require.NoError(t, err)
+
+	s, err := cipher.OpenString(cipher.SealString("hello"))
+	require.NoError(t, err)
+	require.Equal(t, "hello", s)
+}
+
+func TestNewKMSKeyProvider_DisabledKey(t *testing.T) {
+	client := &fakeKMS{
+		plaintexts: map[string][]byte{"blob-v1": key32(1)},
+		errs:       map[string]error{"blob-v2": &types.DisabledException{Message: aws.String("key is disabled")}},
+	}
+
+	_, err := NewKMSKeyProvider(context.Background(), client, "v1", map[string][]byte{
+		"v1": []byte("blob-v1"),
+		"v2": []byte("blob-v2"),
+	})
+	require.ErrorIs(t, err, ErrKeyDisabled)
+	require.Contains(t, err.Error(), `"v2"`)
+}
+
+func TestNewKMSKeyProvider_Errors(t *testing.T) {
+	ctx := context.Background()
+	client := &fakeKMS{
+		plaintexts: map[string][]byte{"short": []byte("too short")},
+		errs:       map[string]error{"denied": errors.New("access denied")},
+	}
+
+	_, err := NewKMSKeyProvider(ctx, client, "v1", nil)
+	require.ErrorIs(t, err, encryptedcol.ErrNoKeys)
+
+	_, err = NewKMSKeyProvider(ctx, client, "v9", map[string][]byte{"v1": []byte("short")})
+	require.ErrorIs(t, err, encryptedcol.ErrDefaultKeyNotFound)
+
+	_, err = NewKMSKeyProvider(ctx, client, "v1", map[string][]byte{"v1": []byte("short")})
+	require.ErrorIs(t, err, encryptedcol.ErrInvalidKeySize)
+
+	_, err = NewKMSKeyProvider(ctx, client, "v1", map[string][]byte{"v1": []byte("denied")})
+	require.Error(t, err)
+	require.NotErrorIs(t, err, ErrKeyDisabled)
+}
+
+func TestKMSKeyProvider_Close(t *testing.T) {
+	client := &fakeKMS{plaintexts: map[string][]byte{"blob": key32(9)}}
+
+	p, err := NewKMSKeyProvider(context.Background(), client, "v1", map[string][]byte{"v1": []byte("blob")})
+	require.NoError(t, err)
+
+	internal := p.keys["v1"]
+	p.Close()
+
+	require.Equal(t, make([]byte, 32), internal)
+	_, err = p.GetKey("v1")
+	require.ErrorIs(t, err, encryptedcol.ErrKeyNotFound)
+}
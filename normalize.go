@@ -1,14 +1,46 @@
 package encryptedcol
 
-import "strings"
+import (
+	"strings"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
 
 // Normalizer transforms input strings into a canonical form before computing blind indexes.
 // This enables case-insensitive or format-agnostic searches.
 //
 // IMPORTANT: Use the SAME normalizer on both write and search.
 // Mixing normalizers breaks lookups.
+//
+// Configurable normalizers are factories that return a Normalizer, e.g.
+// phonenorm.E164("US"). Build them once and reuse the result so write and
+// search paths share one definition.
 type Normalizer func(string) string
 
+// ChainNormalizers returns a Normalizer that applies norms in order.
+// An empty chain is the identity (equivalent to NormalizeNone).
+//
+// Example:
+//
+//	var NormalizeLabel = encryptedcol.ChainNormalizers(
+//	    encryptedcol.NormalizeTrim,
+//	    encryptedcol.NormalizeLower,
+//	    encryptedcol.NormalizeNFKC,
+//	)
+//
+// Order matters: define the chain once and use the same value on write and search.
+func ChainNormalizers(norms ...Normalizer) Normalizer {
+	// Copy so later changes to the caller's slice cannot alter the chain
+	chain := append([]Normalizer(nil), norms...)
+	return func(s string) string {
+		for _, norm := range chain {
+			s = norm(s)
+		}
+		return s
+	}
+}
+
 // NormalizeEmail normalizes email addresses for case-insensitive lookup.
 // Applies: lowercase + trim whitespace.
 //
@@ -41,6 +73,30 @@ var NormalizePhone Normalizer = func(s string) string {
 	return digits.String()
 }
 
+// NormalizeEmailGmail normalizes email addresses for account deduplication.
+// For gmail.com and googlemail.com addresses it removes dots from the local
+// part and drops any "+tag" suffix; the domain is canonicalized to gmail.com
+// since both domains deliver to the same mailbox. All other addresses get
+// plain NormalizeEmail behavior (lowercase + trim).
+//
+// Example: " John.Doe+spam@GoogleMail.com " -> "johndoe@gmail.com"
+// Example: "John.Doe+spam@example.com" -> "john.doe+spam@example.com"
+var NormalizeEmailGmail Normalizer = func(s string) string {
+	s = NormalizeEmail(s)
+	at := strings.LastIndexByte(s, '@')
+	if at < 0 {
+		return s
+	}
+	local, domain := s[:at], s[at+1:]
+	if domain != "gmail.com" && domain != "googlemail.com" {
+		return s
+	}
+	if plus := strings.IndexByte(local, '+'); plus >= 0 {
+		local = local[:plus]
+	}
+	return strings.ReplaceAll(local, ".", "") + "@gmail.com"
+}
+
 // NormalizeNone is an identity normalizer that returns the input unchanged.
 // Use for exact-match (case-sensitive) searches.
 var NormalizeNone Normalizer = func(s string) string {
@@ -57,3 +113,76 @@ var NormalizeTrim Normalizer = func(s string) string {
 var NormalizeLower Normalizer = func(s string) string {
 	return strings.ToLower(s)
 }
+
+// NormalizeWhitespace trims and collapses each run of Unicode whitespace
+// (spaces, tabs, newlines, non-breaking spaces, ...) to a single ASCII space.
+// Preserves case.
+//
+// Example: "  John \t\u00a0Doe\n" -> "John Doe"
+var NormalizeWhitespace Normalizer = func(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// NormalizeName normalizes personal names for case-insensitive lookup.
+// Applies: collapse whitespace (see NormalizeWhitespace) + lowercase.
+//
+// Example: "  John   DOE " -> "john doe"
+var NormalizeName Normalizer = func(s string) string {
+	return strings.ToLower(NormalizeWhitespace(s))
+}
+
+// NormalizeNFC applies Unicode canonical composition (NFC).
+// Canonically equivalent strings, such as "é" as one code point and "e" plus a
+// combining acute accent, normalize identically. Case is preserved.
+var NormalizeNFC Normalizer = func(s string) string {
+	return norm.NFC.String(s)
+}
+
+// NormalizeNFKC applies Unicode compatibility composition (NFKC).
+// In addition to NFC, compatibility variants fold to their plain form,
+// e.g. full-width "ＡＢＣ１２３" -> "ABC123" and the ligature "ﬁ" -> "fi".
+// Case is preserved.
+var NormalizeNFKC Normalizer = func(s string) string {
+	return norm.NFKC.String(s)
+}
+
+// NormalizeCaseFold applies locale-independent Unicode full case folding.
+// Unlike NormalizeLower, it matches strings that differ only by case even when
+// case mapping changes length: "STRASSE", "Straße" and "strasse" all fold to
+// "strasse", and Greek final sigma "ς" folds like "σ".
+//
+// Turkish caveat: folding is not locale-aware. "I" folds to "i", "İ" (dotted
+// capital) folds to "i" plus a combining dot, and dotless "ı" is unchanged, so
+// Turkish "ISTANBUL" and "ıstanbul" do NOT match. Use a locale-specific
+// normalizer (e.g. cases.Lower(language.Turkish)) for Turkish-only data.
+var NormalizeCaseFold Normalizer = func(s string) string {
+	// cases.Caser is stateful and not safe for concurrent use, so build one per call
+	return cases.Fold().String(s)
+}
+
+// NormalizeEmailCaseFold is NormalizeEmail with Unicode case folding instead of lowercasing.
+// Applies: case fold + trim whitespace. See NormalizeCaseFold for the Turkish-i caveat.
+//
+// Example: " Strauß@Example.COM " -> "strauss@example.com"
+var NormalizeEmailCaseFold Normalizer = func(s string) string {
+	return strings.TrimSpace(NormalizeCaseFold(s))
+}
+
+// NormalizeUsernameCaseFold is NormalizeUsername with Unicode case folding instead of lowercasing.
+// Applies: case fold + trim whitespace. See NormalizeCaseFold for the Turkish-i caveat.
+//
+// Example: " GROẞMEISTER " -> "grossmeister"
+var NormalizeUsernameCaseFold Normalizer = func(s string) string {
+	return strings.TrimSpace(NormalizeCaseFold(s))
+}
+
+// NormalizeEmailUnicode normalizes email addresses containing non-ASCII characters.
+// Applies: NFKC + Unicode case folding + trim whitespace.
+//
+// Example: " Café@Example.COM " (with a combining accent) -> "café@example.com"
+//
+// Produces different output than NormalizeEmail for some inputs (e.g. "ß" folds
+// to "ss"), so switching an existing column requires reindexing.
+var NormalizeEmailUnicode Normalizer = func(s string) string {
+	return strings.TrimSpace(NormalizeCaseFold(norm.NFKC.String(s)))
+}
@@ -50,6 +50,47 @@ func TestNormalizeUsername(t *testing.T) {
 	}
 }
 
+func TestNormalizeEmailGmail(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"plain", "johndoe@gmail.com", "johndoe@gmail.com"},
+		{"dots", "john.doe@gmail.com", "johndoe@gmail.com"},
+		{"many dots", "j.o.h.n.d.o.e@gmail.com", "johndoe@gmail.com"},
+		{"plus tag", "johndoe+spam@gmail.com", "johndoe@gmail.com"},
+		{"dots and plus tag", "john.doe+spam@gmail.com", "johndoe@gmail.com"},
+		{"dots after plus", "johndoe+news.letter@gmail.com", "johndoe@gmail.com"},
+		{"mixed case and whitespace", " John.Doe+Spam@GMAIL.COM ", "johndoe@gmail.com"},
+		{"googlemail", "John.Doe+x@googlemail.com", "johndoe@gmail.com"},
+		{"other domain keeps dots and tag", "John.Doe+spam@Example.com", "john.doe+spam@example.com"},
+		{"gmail subdomain is not gmail", "john.doe@mail.gmail.com.evil", "john.doe@mail.gmail.com.evil"},
+		{"no at sign", " John.Doe ", "john.doe"},
+		{"empty", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, NormalizeEmailGmail(tt.input))
+		})
+	}
+}
+
+func TestNormalizeEmailGmail_WithBlindIndex(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	want := cipher.BlindIndexString(NormalizeEmailGmail("johndoe@gmail.com"))
+	for _, variant := range []string{
+		"john.doe@gmail.com",
+		"John.Doe+spam@gmail.com",
+		"JOHNDOE+a+b@GoogleMail.com",
+		" j.ohndoe@gmail.com\t",
+	} {
+		require.Equal(t, want, cipher.BlindIndexString(NormalizeEmailGmail(variant)), variant)
+	}
+}
+
 func TestNormalizePhone(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -136,6 +177,48 @@ func TestNormalizeLower(t *testing.T) {
 	}
 }
 
+func TestNormalizeWhitespace(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"single spaces", "John Doe", "John Doe"},
+		{"doubled spaces", "John  Doe", "John Doe"},
+		{"many spaces", "John     Doe", "John Doe"},
+		{"leading and trailing", "  John Doe  ", "John Doe"},
+		{"tabs and newlines", "\tJohn\t\tDoe\r\n", "John Doe"},
+		{"non-breaking space", "John\u00a0Doe", "John Doe"},
+		{"mixed run", "John \u00a0\t\u2003 Doe", "John Doe"},
+		{"three parts", "Mary  Ann\tSmith", "Mary Ann Smith"},
+		{"case preserved", "JOHN  doe", "JOHN doe"},
+		{"whitespace only", " \t\n ", ""},
+		{"empty", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, NormalizeWhitespace(tt.input))
+		})
+	}
+}
+
+func TestNormalizeName(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	want := cipher.BlindIndexString(NormalizeName("john doe"))
+	for _, variant := range []string{
+		"John Doe",
+		"John   Doe",
+		"  JOHN DOE  ",
+		"John\tDoe",
+		"John\u00a0Doe\n",
+	} {
+		require.Equal(t, "john doe", NormalizeName(variant), variant)
+		require.Equal(t, want, cipher.BlindIndexString(NormalizeName(variant)), variant)
+	}
+}
+
 func TestNormalizer_WithBlindIndex(t *testing.T) {
 	cipher, _ := New(WithKey("v1", testKey("v1")))
 
@@ -190,3 +273,196 @@ func TestNormalizeEmail_Unicode(t *testing.T) {
 		})
 	}
 }
+
+func TestNormalizeNFC(t *testing.T) {
+	precomposed := "caf\u00e9" // é as a single code point
+	decomposed := "cafe\u0301" // e + combining acute accent
+	require.NotEqual(t, precomposed, decomposed)
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"precomposed", precomposed, precomposed},
+		{"decomposed", decomposed, precomposed},
+		{"case preserved", "CAFE\u0301", "CAF\u00c9"},
+		{"full-width unchanged", "\uff21\uff22\uff23", "\uff21\uff22\uff23"},
+		{"empty", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, NormalizeNFC(tt.input))
+		})
+	}
+}
+
+func TestNormalizeNFKC(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"decomposed", "cafe\u0301", "caf\u00e9"},
+		{"full-width letters and digits", "\uff21\uff22\uff23\uff11\uff12\uff13", "ABC123"},
+		{"full-width email", "\uff41\uff4c\uff49\uff43\uff45\uff20example.com", "alice@example.com"},
+		{"ligature", "\ufb01le", "file"},
+		{"case preserved", "Alice", "Alice"},
+		{"empty", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, NormalizeNFKC(tt.input))
+		})
+	}
+}
+
+func TestNormalizeEmailUnicode(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"ascii", " Alice@Example.COM ", "alice@example.com"},
+		{"decomposed accent", "Cafe\u0301@example.com", "caf\u00e9@example.com"},
+		{"precomposed accent", "CAF\u00c9@example.com", "caf\u00e9@example.com"},
+		{"full-width", "\uff21\uff4c\uff49\uff43\uff45@example.com", "alice@example.com"},
+		{"ideographic space trimmed", "\u3000alice@example.com\u3000", "alice@example.com"},
+		{"empty", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, NormalizeEmailUnicode(tt.input))
+		})
+	}
+}
+
+func TestNormalizeNFC_WithBlindIndex(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	precomposed := "caf\u00e9"
+	decomposed := "cafe\u0301"
+
+	require.NotEqual(t, cipher.BlindIndexString(precomposed), cipher.BlindIndexString(decomposed))
+	require.Equal(t,
+		cipher.BlindIndexString(NormalizeNFC(precomposed)),
+		cipher.BlindIndexString(NormalizeNFC(decomposed)),
+	)
+}
+
+func TestNormalizeCaseFold(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"ascii", "Alice", "alice"},
+		{"sharp s upper", "STRASSE", "strasse"},
+		{"sharp s", "Stra\u00dfe", "strasse"},
+		{"capital sharp s", "GRO\u1e9eMEISTER", "grossmeister"},
+		{"greek final sigma", "\u03bf\u03b4\u03bf\u03c2", "\u03bf\u03b4\u03bf\u03c3"},
+		{"greek upper", "\u039f\u0394\u039f\u03a3", "\u03bf\u03b4\u03bf\u03c3"},
+		{"dotless i unchanged", "\u0131", "\u0131"},
+		{"dotted capital I", "\u0130", "i\u0307"},
+		{"whitespace preserved", " A ", " a "},
+		{"empty", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, NormalizeCaseFold(tt.input))
+		})
+	}
+}
+
+func TestNormalizeCaseFold_MatchesWhereLowerDoesNot(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	// strings.ToLower keeps ß, so these differ under NormalizeLower
+	require.NotEqual(t, NormalizeLower("STRASSE"), NormalizeLower("stra\u00dfe"))
+
+	require.Equal(t,
+		cipher.BlindIndexString(NormalizeCaseFold("STRASSE")),
+		cipher.BlindIndexString(NormalizeCaseFold("strasse")),
+	)
+	require.Equal(t,
+		cipher.BlindIndexString(NormalizeCaseFold("STRASSE")),
+		cipher.BlindIndexString(NormalizeCaseFold("stra\u00dfe")),
+	)
+}
+
+func TestNormalizeEmailCaseFold(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{" Alice@Example.COM ", "alice@example.com"},
+		{"Strau\u00df@example.com", "strauss@example.com"},
+		{"STRAUSS@EXAMPLE.COM", "strauss@example.com"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			require.Equal(t, tt.expected, NormalizeEmailCaseFold(tt.input))
+		})
+	}
+}
+
+func TestNormalizeUsernameCaseFold(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{" JohnDoe ", "johndoe"},
+		{"GRO\u1e9eMEISTER", "grossmeister"},
+		{"Gro\u00dfmeister", "grossmeister"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			require.Equal(t, tt.expected, NormalizeUsernameCaseFold(tt.input))
+		})
+	}
+}
+
+func TestChainNormalizers(t *testing.T) {
+	chain := ChainNormalizers(NormalizeTrim, NormalizeLower, NormalizeNFKC)
+
+	require.Equal(t, "abc123", chain("  \uff21\uff22\uff23\uff11\uff12\uff13  "))
+	require.Equal(t, "caf\u00e9", chain(" CAFE\u0301 "))
+}
+
+func TestChainNormalizers_Empty(t *testing.T) {
+	chain := ChainNormalizers()
+
+	for _, s := range []string{"", " Alice ", "CAFE\u0301"} {
+		require.Equal(t, NormalizeNone(s), chain(s))
+	}
+}
+
+func TestChainNormalizers_OrderMatters(t *testing.T) {
+	appendX := func(s string) string { return s + "x" }
+
+	trimFirst := ChainNormalizers(NormalizeTrim, appendX)
+	appendFirst := ChainNormalizers(appendX, NormalizeTrim)
+	require.Equal(t, "ax", trimFirst(" a "))
+	require.Equal(t, "a x", appendFirst(" a "))
+
+	// Gmail rules only apply once NFKC has turned the full-width domain into ASCII
+	input := "j.doe@\uff47\uff4d\uff41\uff49\uff4c.com"
+	require.Equal(t, "jdoe@gmail.com", ChainNormalizers(NormalizeNFKC, NormalizeEmailGmail)(input))
+	require.Equal(t, "j.doe@gmail.com", ChainNormalizers(NormalizeEmailGmail, NormalizeNFKC)(input))
+}
+
+func TestChainNormalizers_CopiesInput(t *testing.T) {
+	norms := []Normalizer{NormalizeLower}
+	chain := ChainNormalizers(norms...)
+	norms[0] = NormalizeNone
+
+	require.Equal(t, "abc", chain("ABC"))
+}
@@ -0,0 +1,62 @@
+package encryptedcol
+
+import "time"
+
+// Observer receives metadata about Cipher operations, for metrics and audit logs.
+// Register one with WithObserver. Observers never see plaintext, ciphertext
+// contents, or key material: only key IDs, sizes, and errors.
+//
+// Methods are called synchronously on the calling goroutine, so they must be
+// cheap and safe for concurrent use. When no observer is registered, the only
+// cost is a nil check.
+//
+// Envelope operations report the seal/open of their wrapped 32-byte data key.
+type Observer interface {
+	// OnSeal is called after a value is encrypted.
+	OnSeal(keyID string, plaintextLen, ciphertextLen int)
+
+	// OnOpen is called after a decryption attempt (NULL inputs are not reported).
+	// keyID is the key ID embedded in the ciphertext, or empty if it could not be parsed.
+	OnOpen(keyID string, err error)
+
+	// OnRotate is called after a value is successfully re-encrypted by a Rotate* method.
+	OnRotate(fromKeyID, toKeyID string)
+}
+
+// KeyExpiryObserver is an optional interface for observers that want to be
+// told when a key past its KeyMeta.NotAfter is used for a new encryption.
+// Seal never fails because of key expiry; this is the only signal.
+type KeyExpiryObserver interface {
+	OnKeyExpired(keyID string, notAfter time.Time)
+}
+
+// observeSeal reports a completed seal. Callers check for a nil observer first.
+func (c *Cipher) observeSeal(keyID string, plaintextLen, ciphertextLen int) {
+	obs := c.config.observer
+	obs.OnSeal(keyID, plaintextLen, ciphertextLen)
+
+	if eo, ok := obs.(KeyExpiryObserver); ok {
+		if meta, ok := c.config.keyMeta[keyID]; ok && meta.Expired(time.Now()) {
+			eo.OnKeyExpired(keyID, meta.NotAfter)
+		}
+	}
+}
+
+// observeOpen reports a decryption attempt if an observer is registered.
+func (c *Cipher) observeOpen(keyID []byte, err error) {
+	if obs := c.config.observer; obs != nil {
+		obs.OnOpen(string(keyID), err)
+	}
+}
+
+// observeRotate reports a successful rotation from oldCiphertext to
+// newCiphertext if an observer is registered.
+func (c *Cipher) observeRotate(oldCiphertext, newCiphertext []byte) {
+	obs := c.config.observer
+	if obs == nil {
+		return
+	}
+	_, fromKeyID, _, _, _ := parseFormatBytes(oldCiphertext)
+	_, toKeyID, _, _, _ := parseFormatBytes(newCiphertext)
+	obs.OnRotate(string(fromKeyID), string(toKeyID))
+}
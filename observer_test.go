@@ -0,0 +1,182 @@
+package encryptedcol
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type sealEvent struct {
+	keyID                       string
+	plaintextLen, ciphertextLen int
+}
+
+type openEvent struct {
+	keyID string
+	err   error
+}
+
+type rotateEvent struct {
+	from, to string
+}
+
+type expiredEvent struct {
+	keyID    string
+	notAfter time.Time
+}
+
+// recordingObserver records events and implements KeyExpiryObserver.
+type recordingObserver struct {
+	mu      sync.Mutex
+	seals   []sealEvent
+	opens   []openEvent
+	rotates []rotateEvent
+	expired []expiredEvent
+}
+
+func (o *recordingObserver) OnSeal(keyID string, plaintextLen, ciphertextLen int) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.seals = append(o.seals, sealEvent{keyID, plaintextLen, ciphertextLen})
+}
+
+func (o *recordingObserver) OnOpen(keyID string, err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.opens = append(o.opens, openEvent{keyID, err})
+}
+
+func (o *recordingObserver) OnRotate(fromKeyID, toKeyID string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.rotates = append(o.rotates, rotateEvent{fromKeyID, toKeyID})
+}
+
+func (o *recordingObserver) OnKeyExpired(keyID string, notAfter time.Time) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.expired = append(o.expired, expiredEvent{keyID, notAfter})
+}
+
+func TestObserver_SealAndOpen(t *testing.T) {
+	obs := &recordingObserver{}
+	cipher, _ := New(WithKey("v1", testKey("v1")), WithObserver(obs))
+
+	ct := cipher.Seal([]byte("hello"))
+	require.Equal(t, []sealEvent{{"v1", 5, len(ct)}}, obs.seals)
+
+	_, err := cipher.Open(ct)
+	require.NoError(t, err)
+	require.Equal(t, []openEvent{{"v1", nil}}, obs.opens)
+
+	// NULL is not reported
+	require.Nil(t, cipher.Seal(nil))
+	_, _ = cipher.Open(nil)
+	require.Len(t, obs.seals, 1)
+	require.Len(t, obs.opens, 1)
+}
+
+func TestObserver_OpenFailures(t *testing.T) {
+	obs := &recordingObserver{}
+	cipher, _ := New(WithKey("v1", testKey("v1")), WithObserver(obs))
+	other, _ := New(WithKey("v2", testKey("v2")))
+
+	tampered := cipher.Seal([]byte("hello"))
+	tampered[len(tampered)-1] ^= 0x01
+
+	_, _ = cipher.Open([]byte{0x00})
+	_, _ = cipher.Open(other.Seal([]byte("x")))
+	_, _ = cipher.Open(tampered)
+	_, _ = cipher.OpenWithKey("v1", tampered)
+
+	require.Equal(t, []openEvent{
+		{"", ErrInvalidFormat},
+		{"v2", keyNotFoundError("v2")},
+		{"v1", ErrDecryptionFailed},
+		{"v1", ErrDecryptionFailed},
+	}, obs.opens)
+	// The other cipher's seal is not observed
+	require.Len(t, obs.seals, 1)
+}
+
+func TestObserver_Rotate(t *testing.T) {
+	obs := &recordingObserver{}
+	cipher, _ := New(
+		WithKey("v1", testKey("v1")),
+		WithKey("v2", testKey("v2")),
+		WithDefaultKeyID("v2"),
+		WithObserver(obs),
+	)
+	old, _ := cipher.SealWithKey("v1", []byte("alice"))
+
+	_, err := cipher.RotateValue(old)
+	require.NoError(t, err)
+	_, err = cipher.RotateStringIndexed(old)
+	require.NoError(t, err)
+	_, err = cipher.RotateBatch([][]byte{old, nil})
+	require.NoError(t, err)
+
+	require.Equal(t, []rotateEvent{{"v1", "v2"}, {"v1", "v2"}, {"v1", "v2"}}, obs.rotates)
+
+	// Failed rotations are reported as failed opens, not rotations
+	_, err = cipher.RotateValue([]byte{0x00})
+	require.Error(t, err)
+	require.Len(t, obs.rotates, 3)
+}
+
+func TestObserver_KeyExpired(t *testing.T) {
+	notAfter := time.Now().Add(-time.Hour)
+	obs := &recordingObserver{}
+	cipher, _ := New(
+		WithKeyEx("old", testKey("old"), KeyMeta{NotAfter: notAfter}),
+		WithKeyEx("new", testKey("new"), KeyMeta{NotAfter: time.Now().Add(time.Hour)}),
+		WithObserver(obs),
+	)
+
+	// Seal still succeeds with the expired default key
+	ct := cipher.Seal([]byte("x"))
+	require.NotNil(t, ct)
+	require.Equal(t, []expiredEvent{{"old", notAfter}}, obs.expired)
+
+	_, err := cipher.SealWithKey("new", []byte("x"))
+	require.NoError(t, err)
+	require.Len(t, obs.expired, 1)
+}
+
+func TestObserver_Stream(t *testing.T) {
+	obs := &recordingObserver{}
+	cipher, _ := New(WithKey("v1", testKey("v1")), WithObserver(obs))
+
+	plaintext := bytes.Repeat([]byte("a"), streamFrameSize+10)
+	var buf bytes.Buffer
+	require.NoError(t, cipher.SealStream(&buf, bytes.NewReader(plaintext)))
+	require.Equal(t, []sealEvent{{"v1", len(plaintext), buf.Len()}}, obs.seals)
+
+	require.NoError(t, cipher.OpenStream(&bytes.Buffer{}, bytes.NewReader(buf.Bytes())))
+	err := cipher.OpenStream(&bytes.Buffer{}, bytes.NewReader(buf.Bytes()[:buf.Len()-1]))
+	require.ErrorIs(t, err, ErrDecryptionFailed)
+	require.Equal(t, []openEvent{{"v1", nil}, {"v1", ErrDecryptionFailed}}, obs.opens)
+}
+
+func TestObserver_Concurrent(t *testing.T) {
+	obs := &recordingObserver{}
+	cipher, _ := New(WithKey("v1", testKey("v1")), WithObserver(obs))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				_, _ = cipher.Open(cipher.Seal([]byte("x")))
+			}
+		}()
+	}
+	wg.Wait()
+
+	require.Len(t, obs.seals, 400)
+	require.Len(t, obs.opens, 400)
+}
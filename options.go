@@ -1,17 +1,27 @@
 package encryptedcol
 
+import "bytes"
+
 // Option is a functional option for configuring a Cipher.
 type Option func(*config)
 
 // WithKey registers a master key with the given key ID.
 // The master key must be exactly 32 bytes.
-// Multiple keys can be registered for key rotation support.
+// Multiple keys can be registered for key rotation support; registering the
+// same key ID twice makes New fail with ErrDuplicateKeyID.
 // The key is copied internally; the caller may zero the original after calling New().
 func WithKey(keyID string, masterKey []byte) Option {
 	return func(c *config) {
 		if c.keys == nil {
 			c.keys = make(map[string][]byte)
 		}
+		// A repeated key ID fails New; the earlier copy is dropped, so zero it
+		if prev, ok := c.keys[keyID]; ok {
+			clear(prev)
+			if c.duplicateKeyID == "" {
+				c.duplicateKeyID = keyID
+			}
+		}
 		// Copy the key so we control its lifecycle
 		keyCopy := make([]byte, len(masterKey))
 		copy(keyCopy, masterKey)
@@ -23,11 +33,62 @@ func WithKey(keyID string, masterKey []byte) Option {
 	}
 }
 
+// WithKeyEx is WithKey with informational key metadata, such as creation
+// time and an optional NotAfter limit for new encryptions. Retrieve it with
+// Cipher.KeyInfo. The metadata never affects the ciphertext format; Seal keeps
+// working with a default key that is past NotAfter.
+func WithKeyEx(keyID string, masterKey []byte, meta KeyMeta) Option {
+	return func(c *config) {
+		WithKey(keyID, masterKey)(c)
+		if c.keyMeta == nil {
+			c.keyMeta = make(map[string]KeyMeta)
+		}
+		c.keyMeta[keyID] = meta
+	}
+}
+
+// WithStrictKeyIDs restricts key IDs to [A-Za-z0-9._-]. New and ReloadKeys
+// return ErrInvalidKeyIDChars for any other byte (control characters,
+// whitespace, non-ASCII), keeping ciphertext headers and logs readable.
+//
+// Opt-in so existing deployments with other key IDs keep working; it only
+// affects key registration, not decryption of existing ciphertext.
+func WithStrictKeyIDs() Option {
+	return func(c *config) {
+		c.strictKeyIDs = true
+	}
+}
+
+// WithRejectWeakKeys makes New and ReloadKeys return ErrWeakKey for a master
+// key whose bytes are all identical, such as an all-zero key left behind by
+// an unset secret or a zeroed buffer.
+//
+// This is a cheap guard against misconfiguration, not an entropy estimate:
+// generate master keys with crypto/rand.
+func WithRejectWeakKeys() Option {
+	return func(c *config) {
+		c.rejectWeakKeys = true
+	}
+}
+
 // WithDefaultKeyID sets the default key ID for new encryptions.
 // The key must be registered via WithKey.
 func WithDefaultKeyID(keyID string) Option {
 	return func(c *config) {
 		c.defaultKeyID = keyID
+		c.defaultKeyIDSet = true
+	}
+}
+
+// WithStrictDefault makes WithDefaultKeyID mandatory: without it, New fails
+// with ErrNoDefaultKey instead of using the first key registered. Reordering
+// WithKey options then can never change which key seals new data.
+//
+// NewWithProvider always sets the provider's default, and NewFromEnv sets
+// {prefix}DEFAULT_KEY_ID or the only key, so both satisfy this option.
+func WithStrictDefault() Option {
+	return func(c *config) {
+		c.strictDefault = true
 	}
 }
 
@@ -41,14 +102,58 @@ func WithCompressionThreshold(bytes int) Option {
 }
 
 // WithCompressionAlgorithm sets the compression algorithm to use.
-// Currently only "zstd" (default) is supported.
-// "snappy" is reserved for future implementation.
+// Supported values are "zstd" (default), "snappy" and "auto".
+// Snappy trades compression ratio for lower CPU cost on latency-sensitive paths.
+// Auto compresses each value above the threshold with both and keeps the
+// smaller result (ties go to zstd), at the cost of running both encoders.
+// Existing ciphertext remains readable regardless of the configured algorithm.
 func WithCompressionAlgorithm(algo string) Option {
 	return func(c *config) {
 		c.compressionAlgorithm = algo
 	}
 }
 
+// WithMaxDecompressedSize sets the largest inner plaintext, in bytes, that
+// will be compressed on seal or decompressed on open. Default is 64MB.
+// Larger values are sealed uncompressed, so the seal and open limits always
+// agree and no value becomes un-openable.
+//
+// The limit guards against decompression bombs. Lowering it makes existing
+// compressed values above the new limit fail with ErrDecompressionFailed.
+func WithMaxDecompressedSize(bytes int) Option {
+	return func(c *config) {
+		c.maxDecompressedSize = bytes
+	}
+}
+
+// WithMinCompressionSavings sets the fraction of its size that compression
+// must save for a value to be stored compressed. Default is 0.10 (10%).
+// Must be strictly between 0 and 1.
+//
+// Lower it (e.g. 0.03) to keep marginal wins when storage cost dominates;
+// raise it (e.g. 0.30) to skip the decompression cost on reads unless
+// compression pays off well. Existing ciphertext is unaffected.
+func WithMinCompressionSavings(ratio float64) Option {
+	return func(c *config) {
+		c.minCompressionSavings = ratio
+	}
+}
+
+// WithCompressionLevel sets the zstd encoder level for new encryptions.
+// Levels map to zstd.EncoderLevel:
+//   - 1: fastest (zstd.SpeedFastest), for hot paths
+//   - 2: default (zstd.SpeedDefault)
+//   - 3: better compression (zstd.SpeedBetterCompression)
+//   - 4: best compression (zstd.SpeedBestCompression), for archival columns
+//
+// The level does not affect decompression; values outside 1-4 cause New to
+// return ErrInvalidCompressionLevel. Ignored for snappy.
+func WithCompressionLevel(level int) Option {
+	return func(c *config) {
+		c.compressionLevel = level
+	}
+}
+
 // WithCompressionDisabled disables compression entirely.
 // Use this for data that is already compressed or won't benefit from compression.
 func WithCompressionDisabled() Option {
@@ -57,6 +162,212 @@ func WithCompressionDisabled() Option {
 	}
 }
 
+// WithAEAD sets the authenticated encryption primitive for new encryptions.
+// Default is AEADSecretbox (XSalsa20-Poly1305).
+//
+// Open always uses the AEAD recorded in each ciphertext, so existing data
+// sealed with a different AEAD remains readable after switching.
+// Blind indexes are unaffected by this option.
+func WithAEAD(aead AEAD) Option {
+	return func(c *config) {
+		c.aead = aead
+	}
+}
+
+// WithBlindIndexBytes truncates blind indexes to the first n bytes of the
+// HMAC-SHA256 output (4 <= n <= 32). Default is 32 (no truncation).
+//
+// Shorter indexes reduce index storage and, for low-cardinality fields,
+// introduce deliberate collisions (k-anonymity). Collisions mean a search may
+// return false positives; decrypt the candidate rows and compare plaintext
+// to filter them out.
+//
+// Changing this value is a breaking change: existing _idx columns will no
+// longer match and must be reindexed. All blind index functions, including
+// SearchCondition and its variants, use the same truncation.
+func WithBlindIndexBytes(n int) Option {
+	return func(c *config) {
+		c.blindIndexBytes = n
+	}
+}
+
+// WithBlindIndexStretch sets the PBKDF2 iteration count for stretched blind
+// indexes (BlindIndexStretched, SearchConditionStretched). Default is 10000.
+// Must be >= 1.
+//
+// Only stretched indexes are affected; regular blind indexes stay a single
+// HMAC. Changing the count changes every stretched index value, so existing
+// stretched _idx columns must be reindexed. Each stretched index costs this
+// many HMAC computations, on every write and for every key version in a search.
+func WithBlindIndexStretch(iterations int) Option {
+	return func(c *config) {
+		c.blindIndexStretch = iterations
+	}
+}
+
+// WithBlindIndexPepper mixes a secret pepper (at least 16 bytes) into every
+// blind index: the HMAC input becomes pepper || value. The pepper is not
+// derived from the master keys, so an attacker who obtains the derived HMAC
+// key (or the master key) still cannot recompute indexes, e.g. to test every
+// value of a low-cardinality column such as gender or marital status.
+//
+// The protection only holds if the pepper is stored apart from the database
+// and its backups (a secrets manager, not a config table). Like a key, it must
+// stay the same for the life of the data: changing or removing it changes
+// every blind index, so all _idx columns must be recomputed. Ciphertext is
+// unaffected. SealWith/OpenWith do not compute indexes.
+func WithBlindIndexPepper(pepper []byte) Option {
+	return func(c *config) {
+		c.blindIndexPepper = bytes.Clone(pepper)
+	}
+}
+
+// WithPlaceholderStyle sets how SearchCondition and its variants render SQL
+// parameter placeholders. Default is PlaceholderDollar ($1, $2, ...).
+// Use PlaceholderQuestion (?) for MySQL and SQLite drivers.
+func WithPlaceholderStyle(style PlaceholderStyle) Option {
+	return func(c *config) {
+		c.placeholderStyle = style
+	}
+}
+
+// WithKeyIDPredicate controls whether SearchCondition and its variants bind
+// each key version's blind index to its key_id. Default is true:
+//
+//	(key_id = $1 AND email_idx = $2) OR (key_id = $3 AND email_idx = $4)
+//
+// With false, the key_id check is omitted and each key version costs one
+// parameter instead of two:
+//
+//	email_idx = $1 OR email_idx = $2
+//
+// Matching still works across rotation since each key yields a distinct index.
+// Use this when key_id is filtered elsewhere in the query or only {column}_idx
+// is indexed. With short indexes (WithBlindIndexBytes), the false positive rate
+// grows with the number of active keys.
+func WithKeyIDPredicate(enabled bool) Option {
+	return func(c *config) {
+		c.omitKeyIDPredicate = !enabled
+	}
+}
+
+// WithObserver registers an Observer that is notified of seal, open and
+// rotate operations (key IDs, sizes and errors only; never plaintext).
+// If obs also implements KeyExpiryObserver, it is told when a key past its
+// KeyMeta.NotAfter is used for a new encryption.
+func WithObserver(obs Observer) Option {
+	return func(c *config) {
+		c.observer = obs
+	}
+}
+
+// WithNonceSource replaces the random nonce generator with src.
+//
+// TEST ONLY. INSECURE IN PRODUCTION: reusing a nonce under the same key breaks
+// the confidentiality of secretbox and AES-GCM and lets an attacker forge
+// ciphertext. Never use it outside tests.
+//
+// It exists so tests can inject a fixed nonce and compare ciphertext against
+// golden bytes. src is called once per nonce (Seal, SealBatch items, rotation,
+// envelopes and streams); AEADs with 12-byte nonces use its first 12 bytes.
+// SealDeterministic and envelope data keys do not use it.
+func WithNonceSource(src func() [24]byte) Option {
+	return func(c *config) {
+		c.nonceSource = src
+	}
+}
+
+// WithHKDFSalt sets a salt for the HKDF derivation of all subkeys, giving
+// domain separation between deployments or products that might (by accident)
+// share a master key. The default is no salt.
+//
+// The salt is a deploy-time constant, not a secret: changing or removing it
+// changes every derived key, so existing ciphertext must be re-encrypted and
+// blind indexes recomputed. SealWith and OpenWith never use a salt.
+func WithHKDFSalt(salt []byte) Option {
+	return func(c *config) {
+		c.hkdfSalt = bytes.Clone(salt)
+	}
+}
+
+// WithDerivedNonce makes Seal take its nonce from derive instead of
+// crypto/rand. It is never the default and is for advanced use only.
+//
+// DANGER: the AEADs used here (secretbox, AES-GCM) fail catastrophically if a
+// nonce is ever reused under the same key: an attacker who sees two such
+// ciphertexts can recover the XOR of their plaintexts and, for AES-GCM, forge
+// new ciphertexts. derive must return a distinct nonce for every seal under a
+// key, including re-seals of the same row with a new value, retries and
+// rotation. Deriving from the plaintext alone leaks equal values, and deriving
+// from a row key alone reuses the nonce when the row is updated. If in doubt,
+// do not use this option; AES-GCM-SIV (WithAEAD(AEADGCMSIV)) limits the damage
+// of an accidental repeat to revealing that two values are equal.
+//
+// The nonce is still stored in every ciphertext, so ciphertext size and Open
+// are unchanged. AEADs with 12-byte nonces use the first 12 bytes. derive is
+// called by Seal and its variants, SealBatch and RotateBatch, and takes
+// precedence over WithNonceSource there. SealDeterministic, SealEnvelope
+// (whose per-record data keys are random) and SealStream are not affected.
+func WithDerivedNonce(derive func(keyID string, plaintext []byte) [24]byte) Option {
+	return func(c *config) {
+		c.derivedNonce = derive
+	}
+}
+
+// WithReadOnly builds a Cipher that can decrypt but never creates ciphertext,
+// for services such as reporting replicas that should only read.
+//
+// Methods that seal return ErrReadOnly where they return an error (TrySeal,
+// SealWithKey, SealWithOptions, SealJSON, Reseal, the Rotate* helpers, RewrapEnvelope, SealStream,
+// SealStruct, RotateColumn unless DryRun, and the database/sql Valuers) and
+// panic otherwise (Seal, SealString, SealIndexed, SealBatch, ...), as they do
+// after Close.
+//
+// Open and its variants, blind indexes and search conditions (needed to
+// query), NeedsRotation, RotationStats, VerifyDecryptable and ActiveKeyIDs
+// work as usual.
+func WithReadOnly() Option {
+	return func(c *config) {
+		c.readOnly = true
+	}
+}
+
+// WithTimestampBinding records the date each value was sealed inside its
+// encrypted payload, where it is authenticated along with the plaintext.
+// SealedAt reads it back, e.g. to find values last written before an
+// incident. The date has day precision (UTC) and costs 4 bytes per value.
+//
+// Seal and its variants, SealBatch, Reseal and the Rotate* helpers record the
+// date; SealDeterministic, SealEnvelope and SealStream do not. Open accepts
+// values with or without a date, so the option can be turned on at any time,
+// but versions of this package without it reject dated values with
+// ErrInvalidFormat. New returns ErrIncompatibleOptions if WithDerivedNonce is
+// also set.
+func WithTimestampBinding() Option {
+	return func(c *config) {
+		c.timestampBinding = true
+	}
+}
+
+// WithPadding pads every value to a multiple of blockSize (2-255) bytes
+// before encryption, so ciphertext length reveals only a length class instead
+// of the exact plaintext length: with WithPadding(32), "bob" and "alexander"
+// seal to the same size. The padding (PKCS#7) is inside the authenticated
+// payload and Open removes it; values sealed with or without padding open
+// alike.
+//
+// Each value grows by 3 to blockSize+2 bytes, so larger blocks hide more and
+// cost more. Padding is applied before compression, which is independent of
+// it: compressed sizes still depend on content, so use WithCompressionDisabled
+// (or SealNoCompress) for columns whose length should stay hidden. Blind
+// indexes are fixed-size and unaffected. SealEnvelope and SealStream are not
+// padded.
+func WithPadding(blockSize int) Option {
+	return func(c *config) {
+		c.paddingBlockSize = blockSize
+	}
+}
+
 // WithEmptyStringAsNull configures the cipher to treat empty strings as NULL.
 // By default, empty strings are preserved (encrypted to ciphertext).
 // With this option, SealString("") returns nil instead of ciphertext.
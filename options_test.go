@@ -1,11 +1,52 @@
 package encryptedcol
 
 import (
+	"bytes"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
 
+func TestWithKey_Duplicate(t *testing.T) {
+	first := testKey("v1")
+	_, err := New(
+		WithKey("v1", first),
+		WithKey("v2", testKey("v2")),
+		WithKey("v1", testKey("pasted by mistake")),
+	)
+	require.ErrorIs(t, err, ErrDuplicateKeyID)
+	require.Contains(t, err.Error(), `"v1"`)
+
+	// Even the same key twice is reported
+	_, err = New(WithKey("v1", first), WithKey("v1", first))
+	require.ErrorIs(t, err, ErrDuplicateKeyID)
+
+	// WithKeyEx registers keys too
+	_, err = New(WithKey("v1", first), WithKeyEx("v1", first, KeyMeta{}))
+	require.ErrorIs(t, err, ErrDuplicateKeyID)
+}
+
+func TestWithStrictDefault(t *testing.T) {
+	_, err := New(WithKey("v1", testKey("v1")), WithKey("v2", testKey("v2")), WithStrictDefault())
+	require.ErrorIs(t, err, ErrNoDefaultKey)
+
+	// Even a single key must be named
+	_, err = New(WithKey("v1", testKey("v1")), WithStrictDefault())
+	require.ErrorIs(t, err, ErrNoDefaultKey)
+
+	// Option order does not matter
+	cipher, err := New(WithStrictDefault(), WithDefaultKeyID("v2"), WithKey("v1", testKey("v1")), WithKey("v2", testKey("v2")))
+	require.NoError(t, err)
+	require.Equal(t, "v2", cipher.DefaultKeyID())
+
+	// Without the option, the first key is still the default
+	cipher, err = New(WithKey("v1", testKey("v1")), WithKey("v2", testKey("v2")))
+	require.NoError(t, err)
+	require.Equal(t, "v1", cipher.DefaultKeyID())
+}
+
 func TestWithKey(t *testing.T) {
 	key := testKey("v1")
 
@@ -70,10 +111,49 @@ func TestWithCompressionAlgorithm(t *testing.T) {
 	require.Equal(t, "zstd", cipher.config.compressionAlgorithm)
 }
 
+func TestWithCompressionAlgorithm_Snappy(t *testing.T) {
+	cipher, err := New(
+		WithKey("v1", testKey("v1")),
+		WithCompressionAlgorithm("snappy"),
+	)
+	require.NoError(t, err)
+	require.Equal(t, "snappy", cipher.config.compressionAlgorithm)
+}
+
+func TestWithCompressionLevel(t *testing.T) {
+	data := []byte(strings.Repeat("archival column data ", 500))
+
+	for _, level := range []int{1, 2, 3, 4} {
+		cipher, err := New(
+			WithKey("v1", testKey("v1")),
+			WithCompressionLevel(level),
+		)
+		require.NoError(t, err)
+		require.Equal(t, level, cipher.config.compressionLevel)
+
+		ciphertext := cipher.Seal(data)
+		require.Equal(t, flagZstd, ciphertext[0])
+
+		decrypted, err := cipher.Open(ciphertext)
+		require.NoError(t, err)
+		require.Equal(t, data, decrypted)
+	}
+}
+
+func TestWithCompressionLevel_Invalid(t *testing.T) {
+	for _, level := range []int{0, 5, -1} {
+		_, err := New(
+			WithKey("v1", testKey("v1")),
+			WithCompressionLevel(level),
+		)
+		require.ErrorIs(t, err, ErrInvalidCompressionLevel)
+	}
+}
+
 func TestWithCompressionAlgorithm_Unsupported(t *testing.T) {
 	_, err := New(
 		WithKey("v1", testKey("v1")),
-		WithCompressionAlgorithm("snappy"),
+		WithCompressionAlgorithm("lz4"),
 	)
 	require.ErrorIs(t, err, ErrUnsupportedCompression)
 }
@@ -122,3 +202,190 @@ func TestOptions_ChainedCorrectly(t *testing.T) {
 	require.Equal(t, 2048, cipher.config.compressionThreshold)
 	require.Equal(t, "zstd", cipher.config.compressionAlgorithm)
 }
+
+func TestWithHKDFSalt(t *testing.T) {
+	salt := []byte("deployment-a")
+	salted, err := New(WithKey("v1", testKey("v1")), WithHKDFSalt(salt))
+	require.NoError(t, err)
+	salt[0] = 'X' // the option keeps its own copy
+
+	again, err := New(WithKey("v1", testKey("v1")), WithHKDFSalt([]byte("deployment-a")))
+	require.NoError(t, err)
+	unsalted, err := New(WithKey("v1", testKey("v1")))
+	require.NoError(t, err)
+
+	ct := salted.SealString("hello")
+	pt, err := again.OpenString(ct)
+	require.NoError(t, err)
+	require.Equal(t, "hello", pt)
+
+	_, err = unsalted.OpenString(ct)
+	require.ErrorIs(t, err, ErrDecryptionFailed)
+	require.NotEqual(t, salted.BlindIndexString("hello"), unsalted.BlindIndexString("hello"))
+	require.Equal(t, salted.BlindIndexString("hello"), again.BlindIndexString("hello"))
+}
+
+func TestWithHKDFSalt_ReloadKeys(t *testing.T) {
+	cipher, err := New(WithKey("v1", testKey("v1")), WithHKDFSalt([]byte("deployment-a")))
+	require.NoError(t, err)
+	ct := cipher.SealString("hello")
+
+	provider := NewStaticKeyProvider("v1", map[string][]byte{"v1": testKey("v1")})
+	require.NoError(t, cipher.ReloadKeys(provider))
+
+	pt, err := cipher.OpenString(ct)
+	require.NoError(t, err)
+	require.Equal(t, "hello", pt)
+}
+
+func TestWithReadOnly(t *testing.T) {
+	writer, err := New(WithKey("v1", testKey("v1")), WithKey("v2", testKey("v2")), WithDefaultKeyID("v2"))
+	require.NoError(t, err)
+	reader, err := New(WithKey("v1", testKey("v1")), WithKey("v2", testKey("v2")), WithDefaultKeyID("v2"), WithReadOnly())
+	require.NoError(t, err)
+
+	ct := writer.SealString("hello")
+	onV1, err := writer.SealWithKey("v1", []byte("old"))
+	require.NoError(t, err)
+
+	// Reads, indexes and search keep working
+	pt, err := reader.OpenString(ct)
+	require.NoError(t, err)
+	require.Equal(t, "hello", pt)
+	require.Equal(t, writer.BlindIndexString("hello"), reader.BlindIndexString("hello"))
+	require.NotNil(t, reader.SearchCondition("email", []byte("hello"), 1))
+	require.True(t, reader.NeedsRotation(onV1))
+	require.Equal(t, []error{nil}, reader.VerifyDecryptable([][]byte{onV1}))
+	require.Equal(t, []string{"v1", "v2"}, reader.ActiveKeyIDs())
+
+	// Error-returning seals fail with ErrReadOnly
+	_, err = reader.TrySeal([]byte("x"))
+	require.ErrorIs(t, err, ErrReadOnly)
+	_, err = reader.SealWithKey("v1", []byte("x"))
+	require.ErrorIs(t, err, ErrReadOnly)
+	_, err = SealJSON(reader, "x")
+	require.ErrorIs(t, err, ErrReadOnly)
+	_, err = reader.RotateValue(onV1)
+	require.ErrorIs(t, err, ErrReadOnly)
+	_, err = reader.RotateBatch([][]byte{onV1})
+	require.ErrorIs(t, err, ErrReadOnly)
+	_, err = reader.RewrapEnvelope(writer.SealEnvelope([]byte("x")))
+	require.ErrorIs(t, err, ErrReadOnly)
+	require.ErrorIs(t, reader.SealStream(&bytes.Buffer{}, strings.NewReader("x")), ErrReadOnly)
+	s := "x"
+	_, err = reader.EncryptedString(new(*string)).Value()
+	require.NoError(t, err) // NULL writes nothing
+	p := &s
+	_, err = reader.EncryptedString(&p).Value()
+	require.ErrorIs(t, err, ErrReadOnly)
+
+	// Panicking seals panic
+	require.Panics(t, func() { reader.Seal([]byte("x")) })
+	require.Panics(t, func() { reader.SealString("x") })
+	require.Panics(t, func() { reader.SealStringIndexed("x") })
+	require.Panics(t, func() { reader.SealBatch([][]byte{[]byte("x")}) })
+	require.Panics(t, func() { reader.SealDeterministic([]byte("x")) })
+	require.Panics(t, func() { reader.SealEnvelope([]byte("x")) })
+
+	// Tenant Ciphers inherit the restriction
+	require.Panics(t, func() { reader.ForTenant("acme").Seal([]byte("x")) })
+}
+
+func TestWithTimestampBinding(t *testing.T) {
+	cipher, err := New(WithKey("v1", testKey("v1")), WithKey("v2", testKey("v2")), WithDefaultKeyID("v2"), WithTimestampBinding())
+	require.NoError(t, err)
+	cipher.config.clock = func() time.Time { return time.Date(2026, time.October, 16, 13, 45, 0, 0, time.FixedZone("", 2*3600)) }
+	day := time.Date(2026, time.October, 16, 0, 0, 0, 0, time.UTC)
+	plain, _ := New(WithKey("v1", testKey("v1")), WithKey("v2", testKey("v2")))
+
+	// Every seal path records the date, and Open accepts dated and undated values
+	rotated, err := cipher.RotateBatch([][]byte{plain.Seal([]byte("old"))})
+	require.NoError(t, err)
+	resealed, err := cipher.Reseal(plain.Seal([]byte("old")))
+	require.NoError(t, err)
+	for _, ct := range [][]byte{
+		cipher.Seal([]byte("hello")),
+		cipher.Seal([]byte(strings.Repeat("compressible ", 100))),
+		cipher.SealNoCompress([]byte("hello")),
+		cipher.SealBatch([][]byte{[]byte("hello")})[0],
+		rotated[0].Ciphertext,
+		resealed,
+	} {
+		sealedAt, err := cipher.SealedAt(ct)
+		require.NoError(t, err)
+		require.Equal(t, day, sealedAt)
+		_, err = cipher.Open(ct)
+		require.NoError(t, err)
+	}
+	require.Len(t, cipher.SealNoCompress([]byte("hello")), 5+cipher.SealOverhead())
+
+	// Undated values and NULL have no date
+	sealedAt, err := cipher.SealedAt(plain.Seal([]byte("old")))
+	require.NoError(t, err)
+	require.True(t, sealedAt.IsZero())
+	sealedAt, err = cipher.SealedAt(nil)
+	require.NoError(t, err)
+	require.True(t, sealedAt.IsZero())
+
+	// SealDeterministic stays deterministic
+	require.Equal(t, cipher.SealDeterministic([]byte("x")), cipher.SealDeterministic([]byte("x")))
+
+	// Readers without the option open dated values too
+	pt, err := plain.Open(cipher.Seal([]byte("hello")))
+	require.NoError(t, err)
+	require.Equal(t, []byte("hello"), pt)
+
+	// Errors
+	_, err = cipher.SealedAt([]byte{0x00})
+	require.ErrorIs(t, err, ErrInvalidFormat)
+	other, _ := New(WithKey("v9", testKey("v9")))
+	_, err = cipher.SealedAt(other.Seal([]byte("x")))
+	require.ErrorIs(t, err, ErrKeyNotFound)
+
+	_, err = New(WithKey("v1", testKey("v1")), WithTimestampBinding(),
+		WithDerivedNonce(func(string, []byte) [24]byte { return [24]byte{} }))
+	require.ErrorIs(t, err, ErrIncompatibleOptions)
+}
+
+func TestWithPadding(t *testing.T) {
+	obs := &recordingObserver{}
+	cipher, err := New(WithKey("v1", testKey("v1")), WithPadding(32), WithCompressionDisabled(), WithObserver(obs))
+	require.NoError(t, err)
+	plain, _ := New(WithKey("v1", testKey("v1")))
+
+	// Values bucket into 32-byte length classes and open unchanged
+	short, long := cipher.SealString("bob"), cipher.SealString("alexander")
+	require.Len(t, long, len(short))
+	require.Len(t, cipher.SealString(strings.Repeat("x", 40)), len(short)+32)
+	outer := sealOverhead("v1", AEADSecretbox) - 1 - len("v1") // Header, nonce and tag
+	for _, s := range []string{"", "bob", strings.Repeat("x", 26), strings.Repeat("x", 27), strings.Repeat("\x01", 40)} {
+		ct := cipher.SealString(s)
+		require.Zero(t, (len(ct)-outer)%32)
+		pt, err := plain.OpenString(ct)
+		require.NoError(t, err)
+		require.Equal(t, s, pt)
+	}
+	require.Equal(t, 3, obs.seals[0].plaintextLen)
+
+	// Unpadded values still open
+	pt, err := cipher.OpenString(plain.SealString("bob"))
+	require.NoError(t, err)
+	require.Equal(t, "bob", pt)
+
+	// SealDeterministic pads and stays deterministic; the seal date combines with padding
+	require.Equal(t, cipher.SealDeterministic([]byte("bob")), cipher.SealDeterministic([]byte("bob")))
+	require.Len(t, cipher.SealDeterministic([]byte("bob")), len(cipher.SealDeterministic([]byte("alexander"))))
+	dated, _ := New(WithKey("v1", testKey("v1")), WithPadding(16), WithTimestampBinding())
+	ct := dated.Seal([]byte("bob"))
+	sealedAt, err := dated.SealedAt(ct)
+	require.NoError(t, err)
+	require.False(t, sealedAt.IsZero())
+	pt, err = plain.OpenString(ct)
+	require.NoError(t, err)
+	require.Equal(t, "bob", pt)
+
+	for _, size := range []int{-1, 1, 256} {
+		_, err := New(WithKey("v1", testKey("v1")), WithPadding(size))
+		require.ErrorIs(t, err, ErrInvalidPaddingBlockSize)
+	}
+}
@@ -0,0 +1,43 @@
+package encryptedcol
+
+// CiphertextInfo describes a ciphertext's header, as reported by Peek.
+type CiphertextInfo struct {
+	KeyID         string // Key version embedded in the header
+	AEAD          AEAD   // AEAD used to seal the value
+	Compressed    bool   // true if the plaintext was compressed before sealing
+	Algorithm     string // Compression algorithm ("zstd" or "snappy"; empty if uncompressed)
+	AADBound      bool   // true if sealed with associated data (SealWithAAD)
+	NonceLen      int    // Nonce length in bytes
+	CiphertextLen int    // Length of the AEAD payload after the nonce, including the tag
+}
+
+// Peek reports the metadata in a ciphertext's header without decrypting it.
+// No key is needed, so it suits admin tooling and dashboards over encrypted
+// columns. Nothing in the header is authenticated until the value is opened.
+//
+// Returns ErrInvalidFormat for nil or malformed input, including an unknown
+// compression flag.
+func (c *Cipher) Peek(ciphertext []byte) (CiphertextInfo, error) {
+	flag, keyID, nonce, encrypted, err := parseFormat(ciphertext)
+	if err != nil {
+		return CiphertextInfo{}, err
+	}
+
+	info := CiphertextInfo{
+		KeyID:         keyID,
+		AEAD:          aeadFromFlag(flag),
+		AADBound:      hasAAD(flag),
+		NonceLen:      len(nonce),
+		CiphertextLen: len(encrypted),
+	}
+	switch compressionFromFlag(flag) {
+	case flagNoCompression:
+	case flagZstd:
+		info.Compressed, info.Algorithm = true, compressionAlgorithmZstd
+	case flagSnappy:
+		info.Compressed, info.Algorithm = true, compressionAlgorithmSnappy
+	default:
+		return CiphertextInfo{}, ErrInvalidFormat
+	}
+	return info, nil
+}
@@ -0,0 +1,88 @@
+package encryptedcol
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPeek(t *testing.T) {
+	compressible := []byte(strings.Repeat("hello world ", 200))
+
+	tests := []struct {
+		name string
+		opts []Option
+		seal func(c *Cipher) []byte
+		want CiphertextInfo
+	}{
+		{
+			name: "secretbox uncompressed",
+			seal: func(c *Cipher) []byte { return c.Seal([]byte("hello")) },
+			want: CiphertextInfo{KeyID: "v1", AEAD: AEADSecretbox, NonceLen: 24, CiphertextLen: 1 + 2 + 5 + aeadOverhead},
+		},
+		{
+			name: "zstd",
+			seal: func(c *Cipher) []byte { return c.Seal(compressible) },
+			want: CiphertextInfo{KeyID: "v1", AEAD: AEADSecretbox, Compressed: true, Algorithm: "zstd", NonceLen: 24},
+		},
+		{
+			name: "snappy with AES-GCM",
+			opts: []Option{WithCompressionAlgorithm("snappy"), WithAEAD(AEADAESGCM)},
+			seal: func(c *Cipher) []byte { return c.Seal(compressible) },
+			want: CiphertextInfo{KeyID: "v1", AEAD: AEADAESGCM, Compressed: true, Algorithm: "snappy", NonceLen: 12},
+		},
+		{
+			name: "AAD bound",
+			seal: func(c *Cipher) []byte { return c.SealWithAAD([]byte("hello"), []byte("row-1")) },
+			want: CiphertextInfo{KeyID: "v1", AEAD: AEADSecretbox, AADBound: true, NonceLen: 24, CiphertextLen: 1 + 2 + 5 + aeadOverhead},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cipher, err := New(append([]Option{WithKey("v1", testKey("v1"))}, tt.opts...)...)
+			require.NoError(t, err)
+
+			ct := tt.seal(cipher)
+			info, err := cipher.Peek(ct)
+			require.NoError(t, err)
+
+			if tt.want.CiphertextLen == 0 {
+				tt.want.CiphertextLen = len(ct) - headerSize("v1", tt.want.NonceLen)
+			}
+			require.Equal(t, tt.want, info)
+		})
+	}
+}
+
+func TestPeek_WithoutKey(t *testing.T) {
+	sealer, _ := New(WithKey("v1", testKey("v1")))
+	ct := sealer.Seal([]byte("hello"))
+
+	other, _ := New(WithKey("v2", testKey("v2")))
+	info, err := other.Peek(ct)
+	require.NoError(t, err)
+	require.Equal(t, "v1", info.KeyID)
+}
+
+func TestPeek_Invalid(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	ct := cipher.Seal([]byte("hello"))
+	unknownCompression := append([]byte{}, ct...)
+	unknownCompression[0] |= 0x07
+
+	for name, data := range map[string][]byte{
+		"nil":                 nil,
+		"empty":               {},
+		"truncated":           ct[:10],
+		"unknown AEAD":        append([]byte{0xF0}, ct[1:]...),
+		"unknown compression": unknownCompression,
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := cipher.Peek(data)
+			require.ErrorIs(t, err, ErrInvalidFormat)
+		})
+	}
+}
@@ -0,0 +1,24 @@
+module github.com/ai8future/encryptedcol/phonenorm
+
+go 1.24.0
+
+require (
+	github.com/ai8future/encryptedcol v1.100.10
+	github.com/nyaruka/phonenumbers v1.8.1
+	github.com/stretchr/testify v1.11.1
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/golang/snappy v1.0.0 // indirect
+	github.com/klauspost/compress v1.18.3 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/crypto v0.47.0 // indirect
+	golang.org/x/sys v0.40.0 // indirect
+	golang.org/x/text v0.33.0 // indirect
+	google.golang.org/protobuf v1.36.11 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+// Develop against the parent checkout. Importers ignore replace and use the require above.
+replace github.com/ai8future/encryptedcol => ../
@@ -0,0 +1,44 @@
+// Package phonenorm provides an E.164 phone number normalizer for
+// encryptedcol blind indexes.
+//
+// It lives in its own package because it depends on
+// github.com/nyaruka/phonenumbers and its numbering-plan metadata, which would
+// otherwise be linked into every binary that uses encryptedcol.
+//
+// Example:
+//
+//	var normPhone = phonenorm.E164("US")
+//	sealed := cipher.SealStringIndexedNormalized(phone, normPhone)
+//	cond := cipher.SearchConditionStringNormalized("phone", phone, 1, normPhone)
+package phonenorm
+
+import (
+	"strings"
+
+	"github.com/nyaruka/phonenumbers"
+
+	"github.com/ai8future/encryptedcol"
+)
+
+// E164 returns a Normalizer that formats phone numbers as E.164
+// (e.g. "+15551234567"). Numbers without a leading "+" are parsed as belonging
+// to defaultRegion, a CLDR region code such as "US" or "GB".
+//
+// Example (region "US"): "(555) 123-4567" -> "+15551234567"
+// Example (region "US"): "+1 555 123 4567" -> "+15551234567"
+//
+// Input that cannot be parsed, or whose length is not possible for its
+// region, falls back to encryptedcol.NormalizePhone (ASCII digits only). Only
+// the length-based possibility check is applied, not full validity: validity
+// depends on numbering-plan metadata that changes between library releases,
+// which would silently change existing blind indexes.
+func E164(defaultRegion string) encryptedcol.Normalizer {
+	region := strings.ToUpper(strings.TrimSpace(defaultRegion))
+	return func(s string) string {
+		num, err := phonenumbers.Parse(s, region)
+		if err != nil || !phonenumbers.IsPossibleNumber(num) {
+			return encryptedcol.NormalizePhone(s)
+		}
+		return phonenumbers.Format(num, phonenumbers.E164)
+	}
+}
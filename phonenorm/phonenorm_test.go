@@ -0,0 +1,53 @@
+package phonenorm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ai8future/encryptedcol"
+)
+
+func TestE164(t *testing.T) {
+	us := E164("US")
+	gb := E164("gb")
+
+	tests := []struct {
+		name     string
+		norm     encryptedcol.Normalizer
+		input    string
+		expected string
+	}{
+		{"us national", us, "5551234567", "+15551234567"},
+		{"us formatted", us, "(555) 123-4567", "+15551234567"},
+		{"us international", us, "+1 555 123 4567", "+15551234567"},
+		{"us with trunk prefix", us, "1-555-123-4567", "+15551234567"},
+		{"gb national", gb, "020 7946 0958", "+442079460958"},
+		{"gb international", gb, "+44 20 7946 0958", "+442079460958"},
+		{"foreign number under us", us, "+44 20 7946 0958", "+442079460958"},
+		{"too short falls back", us, "12-34", "1234"},
+		{"unparseable falls back", us, "call me", ""},
+		{"unknown region falls back", E164("ZZ"), "(555) 123-4567", "5551234567"},
+		{"empty", us, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, tt.norm(tt.input))
+		})
+	}
+}
+
+func TestE164_WithBlindIndex(t *testing.T) {
+	cipher, err := encryptedcol.New(encryptedcol.WithKey("v1", []byte("0123456789abcdef0123456789abcdef")))
+	require.NoError(t, err)
+	norm := E164("US")
+
+	// Digit stripping alone does not unify these
+	require.NotEqual(t, encryptedcol.NormalizePhone("5551234567"), encryptedcol.NormalizePhone("+1 555 123 4567"))
+
+	require.Equal(t,
+		cipher.BlindIndexString(norm("5551234567")),
+		cipher.BlindIndexString(norm("+1 555 123 4567")),
+	)
+}
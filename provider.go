@@ -19,35 +19,91 @@ type KeyProvider interface {
 
 // NewWithProvider creates a new Cipher using a KeyProvider.
 // Keys are fetched from the provider at initialization time and cached.
+// Use ReloadKeys to pick up later changes from the provider.
 func NewWithProvider(provider KeyProvider) (*Cipher, error) {
+	keys, defaultID, err := fetchProviderKeys(provider)
+	if err != nil {
+		return nil, err
+	}
+
+	// Build options from fetched keys
+	opts := make([]Option, 0, len(keys)+1)
+	for keyID, key := range keys {
+		opts = append(opts, WithKey(keyID, key))
+	}
+	opts = append(opts, WithDefaultKeyID(defaultID))
+
+	return New(opts...)
+}
+
+// ReloadKeys fetches the current keys from provider and atomically replaces
+// the Cipher's key set and default key ID. Configuration options are kept.
+//
+// Operations already in progress finish with the previous keys; the previous
+// derived keys are zeroed once they complete. On error, the existing keys
+// remain in use.
+func (c *Cipher) ReloadKeys(provider KeyProvider) error {
+	keys, defaultID, err := fetchProviderKeys(provider)
+	if err != nil {
+		return err
+	}
+	// Zero fetched master keys once derived
+	defer func() {
+		for _, key := range keys {
+			for i := range key {
+				key[i] = 0
+			}
+		}
+	}()
+
+	for keyID := range keys {
+		if err := c.config.validateKeyID(keyID); err != nil {
+			return err
+		}
+	}
+	if err := c.config.checkWeakKeys(keys); err != nil {
+		return err
+	}
+
+	ring, err := newKeyring(keys, defaultID, c.config.hkdfSalt)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed.Load() {
+		ring.retire()
+		return ErrCipherClosed
+	}
+	c.ring.Swap(ring).retire()
+	return nil
+}
+
+// fetchProviderKeys fetches all active keys from provider and verifies
+// that the default key is among them.
+func fetchProviderKeys(provider KeyProvider) (map[string][]byte, string, error) {
 	activeIDs := provider.ActiveKeyIDs()
 	if len(activeIDs) == 0 {
-		return nil, ErrNoKeys
+		return nil, "", ErrNoKeys
 	}
 
 	// Fetch all active keys from provider
-	keys := make(map[string][]byte)
+	keys := make(map[string][]byte, len(activeIDs))
 	for _, keyID := range activeIDs {
 		key, err := provider.GetKey(keyID)
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
 		keys[keyID] = key
 	}
 
 	defaultID := provider.DefaultKeyID()
 	if _, ok := keys[defaultID]; !ok {
-		return nil, ErrDefaultKeyNotFound
+		return nil, "", ErrDefaultKeyNotFound
 	}
 
-	// Build options from fetched keys
-	opts := make([]Option, 0, len(keys)+1)
-	for keyID, key := range keys {
-		opts = append(opts, WithKey(keyID, key))
-	}
-	opts = append(opts, WithDefaultKeyID(defaultID))
-
-	return New(opts...)
+	return keys, defaultID, nil
 }
 
 // StaticKeyProvider is a simple in-memory implementation of KeyProvider.
@@ -2,6 +2,8 @@ package encryptedcol
 
 import (
 	"bytes"
+	"fmt"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -189,3 +191,132 @@ func TestStaticKeyProvider_GetKey_ReturnsCopy(t *testing.T) {
 
 	require.NotEqual(t, key1[0], key2[0], "GetKey should return a copy, not internal reference")
 }
+
+func TestReloadKeys(t *testing.T) {
+	cipher, err := NewWithProvider(NewStaticKeyProvider("v1", map[string][]byte{
+		"v1": testKey("v1"),
+	}))
+	require.NoError(t, err)
+
+	oldCT := cipher.Seal([]byte("before reload"))
+	oldKeys := cipher.ring.Load().keys["v1"]
+
+	err = cipher.ReloadKeys(NewStaticKeyProvider("v2", map[string][]byte{
+		"v1": testKey("v1"),
+		"v2": testKey("v2"),
+	}))
+	require.NoError(t, err)
+
+	require.Equal(t, "v2", cipher.DefaultKeyID())
+	require.Equal(t, []string{"v1", "v2"}, cipher.ActiveKeyIDs())
+
+	// Old derived keys are zeroed
+	require.Equal(t, [32]byte{}, oldKeys.encryption)
+	require.Equal(t, [32]byte{}, oldKeys.hmac)
+
+	// Old data still decrypts; new data uses v2
+	pt, err := cipher.Open(oldCT)
+	require.NoError(t, err)
+	require.Equal(t, []byte("before reload"), pt)
+
+	keyID, err := cipher.ExtractKeyID(cipher.Seal([]byte("after")))
+	require.NoError(t, err)
+	require.Equal(t, "v2", keyID)
+
+	sealed := cipher.SealStringIndexed("x")
+	require.Equal(t, "v2", sealed.KeyID)
+}
+
+func TestReloadKeys_KeepsConfig(t *testing.T) {
+	cipher, err := New(WithKey("v1", testKey("v1")), WithAEAD(AEADAESGCM), WithBlindIndexBytes(16))
+	require.NoError(t, err)
+
+	require.NoError(t, cipher.ReloadKeys(NewStaticKeyProvider("v2", map[string][]byte{"v2": testKey("v2")})))
+
+	ct := cipher.Seal([]byte("x"))
+	require.Equal(t, AEADAESGCM, aeadFromFlag(ct[0]))
+	require.Len(t, cipher.BlindIndex([]byte("x")), 16)
+}
+
+func TestReloadKeys_Errors(t *testing.T) {
+	cipher, err := New(WithKey("v1", testKey("v1")))
+	require.NoError(t, err)
+
+	err = cipher.ReloadKeys(NewStaticKeyProvider("v1", nil))
+	require.ErrorIs(t, err, ErrNoKeys)
+
+	err = cipher.ReloadKeys(NewStaticKeyProvider("v9", map[string][]byte{"v1": testKey("v1")}))
+	require.ErrorIs(t, err, ErrDefaultKeyNotFound)
+
+	err = cipher.ReloadKeys(NewStaticKeyProvider("v1", map[string][]byte{"v1": []byte("short")}))
+	require.ErrorIs(t, err, ErrInvalidKeySize)
+
+	// Failed reloads leave the existing keys in place
+	require.Equal(t, "v1", cipher.DefaultKeyID())
+	pt, err := cipher.Open(cipher.Seal([]byte("still works")))
+	require.NoError(t, err)
+	require.Equal(t, []byte("still works"), pt)
+
+	cipher.Close()
+	err = cipher.ReloadKeys(NewStaticKeyProvider("v1", map[string][]byte{"v1": testKey("v1")}))
+	require.ErrorIs(t, err, ErrCipherClosed)
+}
+
+func TestReloadKeys_Concurrent(t *testing.T) {
+	keys := map[string][]byte{"v1": testKey("v1"), "v2": testKey("v2")}
+	cipher, err := NewWithProvider(NewStaticKeyProvider("v1", keys))
+	require.NoError(t, err)
+
+	stop := make(chan struct{})
+	var reloads sync.WaitGroup
+	reloads.Add(1)
+	go func() {
+		defer reloads.Done()
+		defaults := []string{"v1", "v2"}
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			if err := cipher.ReloadKeys(NewStaticKeyProvider(defaults[i%2], keys)); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 100)
+	for g := 0; g < 100; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				plaintext := []byte(fmt.Sprintf("goroutine %d value %d", g, i))
+				got, err := cipher.Open(cipher.Seal(plaintext))
+				if err != nil {
+					errs <- err
+					return
+				}
+				if !bytes.Equal(got, plaintext) {
+					errs <- fmt.Errorf("round trip mismatch: %q", got)
+					return
+				}
+				sealed := cipher.SealStringIndexed(string(plaintext))
+				if keyID, _ := cipher.ExtractKeyID(sealed.Ciphertext); keyID != sealed.KeyID {
+					errs <- fmt.Errorf("KeyID %q does not match ciphertext key %q", sealed.KeyID, keyID)
+					return
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	close(stop)
+	reloads.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+}
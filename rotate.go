@@ -1,11 +1,20 @@
 package encryptedcol
 
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
 // RotateValue re-encrypts a ciphertext with the current default key.
 // Use this during key rotation to migrate existing encrypted data.
 //
 // Returns nil if oldCiphertext is nil (NULL stays NULL).
 // Returns error if decryption fails.
 func (c *Cipher) RotateValue(oldCiphertext []byte) ([]byte, error) {
+	if err := c.checkWritable(); err != nil {
+		return nil, err
+	}
 	if oldCiphertext == nil {
 		return nil, nil
 	}
@@ -15,7 +24,97 @@ func (c *Cipher) RotateValue(oldCiphertext []byte) ([]byte, error) {
 		return nil, err
 	}
 
-	return c.Seal(plaintext), nil
+	newCiphertext := c.Seal(plaintext)
+	c.observeRotate(oldCiphertext, newCiphertext)
+	return newCiphertext, nil
+}
+
+// RotateValueFrom decrypts oldCiphertext with src and re-encrypts it with
+// the receiver's default key. Use this when legacy keys live in a separate
+// Cipher (e.g. one backed by a retiring key store) that should not be merged
+// into the current one.
+//
+// Returns nil if oldCiphertext is nil (NULL stays NULL).
+// Returns error if decryption with src fails.
+func (c *Cipher) RotateValueFrom(src *Cipher, oldCiphertext []byte) ([]byte, error) {
+	if err := c.checkWritable(); err != nil {
+		return nil, err
+	}
+	if oldCiphertext == nil {
+		return nil, nil
+	}
+
+	plaintext, err := src.Open(oldCiphertext)
+	if err != nil {
+		return nil, err
+	}
+	defer clear(plaintext)
+
+	newCiphertext := c.Seal(plaintext)
+	c.observeRotate(oldCiphertext, newCiphertext)
+	return newCiphertext, nil
+}
+
+// Reseal decrypts ciphertext and encrypts it again under the same key ID with
+// a fresh nonce, so the result differs byte for byte from the input (and from
+// copies of it in old backups) without moving the value to another key, as
+// RotateValue would. The AEAD and compression follow the current options.
+//
+// With WithDerivedNonce, the nonce depends only on the key ID and plaintext,
+// so Reseal returns the same bytes it would for a fresh Seal under that key.
+//
+// Returns nil if ciphertext is nil (NULL stays NULL).
+// Returns error if decryption fails.
+func (c *Cipher) Reseal(ciphertext []byte) ([]byte, error) {
+	r, err := c.acquireWritable()
+	if err != nil {
+		return nil, err
+	}
+	defer r.release()
+	if ciphertext == nil {
+		return nil, nil
+	}
+
+	keyID, plaintext, err := c.openOuter(r, nil, ciphertext, nil)
+	c.observeOpen(keyID, err)
+	if err != nil {
+		return nil, err
+	}
+	defer clear(plaintext)
+
+	return c.sealWithKeyID(r, nil, string(keyID), plaintext, nil), nil
+}
+
+// OpenChain decrypts ciphertext with the first of ciphers that succeeds, trying
+// them in order. Use it while data sealed under separate key sets (e.g. a
+// legacy Cipher alongside the current one) is being migrated, so one read
+// path handles both populations.
+//
+// Returns nil, nil if ciphertext is nil (NULL preservation). A ciphertext that
+// is not in the encryptedcol format fails with ErrInvalidFormat before any
+// cipher is tried. If every cipher fails (or none is given), the error wraps
+// ErrNoCipherCouldDecrypt together with each cipher's error, so errors.Is
+// still matches ErrKeyNotFound, ErrDecryptionFailed and the like.
+func OpenChain(ciphertext []byte, ciphers ...*Cipher) ([]byte, error) {
+	if ciphertext == nil {
+		return nil, nil
+	}
+	if _, _, _, _, err := parseFormatBytes(ciphertext); err != nil {
+		return nil, err
+	}
+
+	errs := make([]error, 0, len(ciphers))
+	for _, c := range ciphers {
+		plaintext, err := c.Open(ciphertext)
+		if err == nil {
+			return plaintext, nil
+		}
+		errs = append(errs, err)
+	}
+	if len(errs) == 0 {
+		return nil, ErrNoCipherCouldDecrypt
+	}
+	return nil, fmt.Errorf("%w: %w", ErrNoCipherCouldDecrypt, errors.Join(errs...))
 }
 
 // RotateBlindIndex recomputes a blind index with the current default key.
@@ -34,6 +133,9 @@ func (c *Cipher) RotateBlindIndex(plaintext []byte) []byte {
 //
 // Returns nil values if ciphertext is nil (NULL stays NULL).
 func (c *Cipher) RotateStringIndexed(oldCiphertext []byte) (*SealedValue, error) {
+	if err := c.checkWritable(); err != nil {
+		return nil, err
+	}
 	if oldCiphertext == nil {
 		return c.nullSealedValue(), nil
 	}
@@ -43,11 +145,50 @@ func (c *Cipher) RotateStringIndexed(oldCiphertext []byte) (*SealedValue, error)
 		return nil, err
 	}
 
-	return &SealedValue{
-		Ciphertext: c.Seal(plaintext),
-		BlindIndex: c.BlindIndex(plaintext),
-		KeyID:      c.defaultID,
-	}, nil
+	sealed := c.sealIndexed(plaintext, plaintext)
+	c.observeRotate(oldCiphertext, sealed.Ciphertext)
+	return sealed, nil
+}
+
+// RotateIndexed re-encrypts a byte value and recomputes its blind index over
+// the raw plaintext bytes. This is the rotation counterpart of SealIndexed.
+//
+// Returns nil values if ciphertext is nil (NULL stays NULL).
+func (c *Cipher) RotateIndexed(oldCiphertext []byte) (*SealedValue, error) {
+	// Strings and bytes share one blind index computation.
+	return c.RotateStringIndexed(oldCiphertext)
+}
+
+// RotateJSON re-encrypts a JSON value sealed by SealJSON with the current default key.
+// The plaintext must unmarshal into T, so a value of the wrong type is
+// reported rather than silently carried forward. The original bytes are
+// re-sealed unchanged: fields T does not declare, number precision and
+// formatting are preserved.
+//
+// Returns nil if oldCiphertext is nil (NULL stays NULL).
+// Returns error if decryption or unmarshaling fails.
+func RotateJSON[T any](c *Cipher, oldCiphertext []byte) ([]byte, error) {
+	if err := c.checkWritable(); err != nil {
+		return nil, err
+	}
+	if oldCiphertext == nil {
+		return nil, nil
+	}
+
+	plaintext, err := c.Open(oldCiphertext)
+	if err != nil {
+		return nil, err
+	}
+	defer clear(plaintext)
+
+	var value T
+	if err := json.Unmarshal(plaintext, &value); err != nil {
+		return nil, err
+	}
+
+	newCiphertext := c.Seal(plaintext)
+	c.observeRotate(oldCiphertext, newCiphertext)
+	return newCiphertext, nil
 }
 
 // RotateStringIndexedNormalized re-encrypts and recomputes normalized blind index.
@@ -55,6 +196,9 @@ func (c *Cipher) RotateStringIndexed(oldCiphertext []byte) (*SealedValue, error)
 //
 // IMPORTANT: Use the same normalizer that was used originally.
 func (c *Cipher) RotateStringIndexedNormalized(oldCiphertext []byte, norm Normalizer) (*SealedValue, error) {
+	if err := c.checkWritable(); err != nil {
+		return nil, err
+	}
 	if oldCiphertext == nil {
 		return c.nullSealedValue(), nil
 	}
@@ -67,11 +211,140 @@ func (c *Cipher) RotateStringIndexedNormalized(oldCiphertext []byte, norm Normal
 	// Normalize for blind index
 	normalized := norm(string(plaintext))
 
-	return &SealedValue{
-		Ciphertext: c.Seal(plaintext),
-		BlindIndex: c.BlindIndex([]byte(normalized)),
-		KeyID:      c.defaultID,
-	}, nil
+	sealed := c.sealIndexed(plaintext, []byte(normalized))
+	c.observeRotate(oldCiphertext, sealed.Ciphertext)
+	return sealed, nil
+}
+
+// ReindexString decrypts ciphertext and returns the blind index of its
+// plaintext under norm (raw bytes if nil), computed with the key version that
+// sealed it rather than the default key. Use it to backfill {column}_idx after
+// a normalizer change: the _encrypted column and key_id stay as they are, so
+// nothing is re-encrypted or rewritten except the index.
+//
+// Returns nil if ciphertext is nil (NULL stays NULL).
+// Returns error if decryption fails.
+func (c *Cipher) ReindexString(ciphertext []byte, norm Normalizer) ([]byte, error) {
+	r, err := c.acquire()
+	if err != nil {
+		return nil, err
+	}
+	defer r.release()
+	if ciphertext == nil {
+		return nil, nil
+	}
+
+	keyID, plaintext, err := c.openOuter(r, nil, ciphertext, nil)
+	c.observeOpen(keyID, err)
+	if err != nil {
+		return nil, err
+	}
+	defer clear(plaintext)
+
+	if norm != nil {
+		return c.computeHMAC(r, string(keyID), []byte(norm(string(plaintext)))), nil
+	}
+	return c.computeHMAC(r, string(keyID), plaintext), nil
+}
+
+// RotateBlindIndexFromCiphertext decrypts oldCiphertext with whatever key
+// sealed it and returns the blind index of its raw plaintext bytes under the
+// default key, as RotateIndexed would, while leaving the ciphertext alone.
+// Use it to move {column}_idx to a new key at once and re-encrypt lazily on
+// the next write.
+//
+// The row's key_id still names the old key, so searches built with the
+// key_id predicate would look for the old key's index there. Search such
+// columns with WithKeyIDPredicate(false) until the ciphertext is rotated too.
+//
+// Returns nil if oldCiphertext is nil (NULL stays NULL).
+// Returns error if decryption fails.
+func (c *Cipher) RotateBlindIndexFromCiphertext(oldCiphertext []byte) ([]byte, error) {
+	r, err := c.acquire()
+	if err != nil {
+		return nil, err
+	}
+	defer r.release()
+	if oldCiphertext == nil {
+		return nil, nil
+	}
+
+	plaintext, err := c.openWithRing(r, nil, oldCiphertext, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer clear(plaintext)
+
+	return c.computeHMAC(r, r.defaultID, plaintext), nil
+}
+
+// RotatedResult is the outcome of rotating one item in RotateBatch.
+type RotatedResult struct {
+	Ciphertext []byte // Re-encrypted data (nil unless Rotated)
+	BlindIndex []byte // Blind index of the raw plaintext under the default key (nil unless Rotated)
+	KeyID      string // Key version of Ciphertext (empty unless Rotated)
+	Rotated    bool   // true if the item was re-encrypted
+	Err        error  // Per-item failure (malformed ciphertext, unknown key, decryption failure)
+}
+
+// RotateBatch re-encrypts each ciphertext with the current default key and
+// recomputes its blind index over the raw plaintext bytes, like RotateIndexed.
+// The results are parallel to ciphertexts.
+//
+// Items that need no work are skipped (Rotated == false, Err == nil): NULL
+// values and ciphertexts already under the default key. A failure on one item
+// is recorded in its Err and does not affect the others, so callers can commit
+// the successes and quarantine the failures. Unlike NeedsRotation, malformed
+// ciphertexts are reported as errors rather than skipped.
+//
+// The whole batch is rotated to a single default key, even if ReloadKeys runs
+// concurrently. The returned error is non-nil only if the Cipher is closed.
+func (c *Cipher) RotateBatch(ciphertexts [][]byte) ([]RotatedResult, error) {
+	r, err := c.acquireWritable()
+	if err != nil {
+		return nil, err
+	}
+	defer r.release()
+
+	results := make([]RotatedResult, len(ciphertexts))
+	keyID := r.defaultID
+
+	var scratch, inner []byte
+	for i, ct := range ciphertexts {
+		if ct == nil {
+			continue // NULL stays NULL
+		}
+
+		_, oldKeyID, _, _, err := parseFormatBytes(ct)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		if string(oldKeyID) == keyID {
+			continue // Already on the default key
+		}
+
+		plaintext, err := c.openWithRing(r, scratch[:0], ct, nil)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+
+		inner = c.appendInner(inner[:0], keyID, plaintext)
+		results[i] = RotatedResult{
+			Ciphertext: c.sealInner(r, nil, keyID, inner, c.sealNonce(keyID, plaintext), nil),
+			BlindIndex: c.computeHMAC(r, keyID, plaintext),
+			KeyID:      keyID,
+			Rotated:    true,
+		}
+		if obs := c.config.observer; obs != nil {
+			obs.OnRotate(string(oldKeyID), keyID)
+		}
+		clear(plaintext)
+		scratch = plaintext
+	}
+	clear(inner)
+	return results, nil
 }
 
 // NeedsRotation checks if a ciphertext was encrypted with an old key.
@@ -90,7 +363,107 @@ func (c *Cipher) NeedsRotation(ciphertext []byte) bool {
 		return false // Can't determine, assume doesn't need rotation
 	}
 
-	return keyID != c.defaultID
+	return keyID != c.DefaultKeyID()
+}
+
+// NeedsRotationBatch reports NeedsRotation for each ciphertext.
+// The result is parallel to ciphertexts and is computed against a single
+// default key, even if ReloadKeys runs concurrently.
+func (c *Cipher) NeedsRotationBatch(ciphertexts [][]byte) []bool {
+	defaultID := c.DefaultKeyID()
+	results := make([]bool, len(ciphertexts))
+	for i, ct := range ciphertexts {
+		if ct == nil {
+			continue
+		}
+		_, keyID, _, _, err := parseFormatBytes(ct)
+		results[i] = err == nil && string(keyID) != defaultID
+	}
+	return results
+}
+
+// VerifyDecryptable opens each ciphertext with the current key set and
+// discards the plaintext, returning a slice of errors parallel to ciphertexts:
+// nil for values that decrypt (and for NULL), the Open error otherwise.
+//
+// Run it over a column before a rotation or before retiring a key version to
+// catch values sealed under a key that is no longer configured. Nothing is
+// re-encrypted. After Close, every entry is ErrCipherClosed.
+func (c *Cipher) VerifyDecryptable(ciphertexts [][]byte) []error {
+	errs := make([]error, len(ciphertexts))
+	r, err := c.acquire()
+	if err != nil {
+		for i := range errs {
+			errs[i] = err
+		}
+		return errs
+	}
+	defer r.release()
+
+	var scratch []byte
+	for i, ct := range ciphertexts {
+		if ct == nil {
+			continue // NULL is always readable
+		}
+		plaintext, err := c.openWithRing(r, scratch[:0], ct, nil)
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+		clear(plaintext)
+		scratch = plaintext
+	}
+	return errs
+}
+
+// RotationStats summarizes the key versions used by a set of ciphertexts.
+type RotationStats struct {
+	Total         int            // Number of values surveyed
+	Null          int            // nil (NULL) values
+	Malformed     int            // Values whose key_id could not be parsed
+	ByKeyID       map[string]int // Count of values per key_id
+	NeedsRotation int            // Values on a key other than the default key
+}
+
+// RotationStats surveys ciphertexts without decrypting them, counting values
+// per key_id along with NULL and malformed values.
+// Use it to size a rotation job before running RotateBatch.
+func (c *Cipher) RotationStats(ciphertexts [][]byte) RotationStats {
+	defaultID := c.DefaultKeyID()
+	stats := RotationStats{
+		Total:   len(ciphertexts),
+		ByKeyID: make(map[string]int),
+	}
+	for _, ct := range ciphertexts {
+		if ct == nil {
+			stats.Null++
+			continue
+		}
+		keyID, err := c.ExtractKeyID(ct)
+		if err != nil {
+			stats.Malformed++
+			continue
+		}
+		stats.ByKeyID[keyID]++
+		if keyID != defaultID {
+			stats.NeedsRotation++
+		}
+	}
+	return stats
+}
+
+// KeyIDsInUse counts how many ciphertexts reference each key_id, without
+// decrypting them. NULL values are ignored; malformed values are counted under
+// the empty key "", which is never a valid key ID. Before retiring a key
+// version, check that its count is zero across every column that may hold it.
+//
+// RotationStats reports the same counts along with NULL and pending-rotation totals.
+func (c *Cipher) KeyIDsInUse(ciphertexts [][]byte) map[string]int {
+	stats := c.RotationStats(ciphertexts)
+	if stats.Malformed > 0 {
+		stats.ByKeyID[""] = stats.Malformed
+	}
+	return stats.ByKeyID
 }
 
 // ExtractKeyID extracts the key_id from a ciphertext without decrypting.
@@ -0,0 +1,288 @@
+package encryptedcol
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// defaultRotateBatchSize is the RotateColumnOpts.BatchSize used when none is set.
+const defaultRotateBatchSize = 500
+
+// RotateColumnOpts configures RotateColumn.
+type RotateColumnOpts struct {
+	Table     string // Table to rotate
+	Column    string // Base column name: {Column}_encrypted (and {Column}_idx if Indexed)
+	PKColumn  string // Unique, ordered primary key column used for paging
+	BatchSize int    // Rows per transaction (default 500)
+
+	// DryRun only decrypts: rows that would be rotated are counted in Rotated,
+	// failures are reported as usual, and nothing is written.
+	DryRun bool
+
+	// Indexed also rewrites {Column}_idx, over the plaintext passed through
+	// Normalizer (raw bytes if nil). Use the normalizer the index was built with.
+	Indexed    bool
+	Normalizer Normalizer
+
+	// Progress, if set, is called after each batch with running totals.
+	Progress func(RotateColumnStats)
+
+	// Log, if set, receives one JSON line per batch with the running totals
+	// and the number of rows examined per stored key ID, for operators to
+	// tail. Lines never contain plaintext, ciphertext or key material. Write
+	// errors are ignored so logging cannot stop a migration.
+	Log io.Writer
+
+	// OnError, if set, is called for each row whose value cannot be decrypted.
+	// The row is left unchanged and counted in Failed.
+	OnError func(pk any, err error)
+}
+
+// RotateColumnStats reports RotateColumn progress.
+type RotateColumnStats struct {
+	Rotated int // Rows re-encrypted under the default key
+	Skipped int // Rows already on the default key or changed concurrently, left as they are
+	Failed  int // Rows that could not be decrypted (see OnError)
+	LastPK  any // Primary key of the last row examined
+}
+
+// RotateColumn re-encrypts every row of an encrypted column whose key_id is not
+// the default key, writing the new ciphertext, blind index and key_id back in
+// batches of BatchSize, one transaction per batch.
+//
+// Rows are read in primary key order, {Column}_encrypted IS NOT NULL and
+// key_id <> default, and each update only applies if {Column}_encrypted still
+// holds the value that was read. A crashed or cancelled job can therefore be
+// rerun from the start: rows already rotated are no longer selected, and rows
+// written concurrently by the application are skipped, never overwritten.
+//
+// key_id is per row (see WriteColumns). On tables with several encrypted
+// columns, rotating one of them moves the row's key_id, so the others must be
+// rotated to the same key before they are searched again.
+//
+// Rows that fail to decrypt are reported through OnError and left in place;
+// they do not stop the job. Run with DryRun first to find them without writing
+// anything, e.g. before retiring a key version. The returned error is non-nil only for database
+// errors, context cancellation or a closed Cipher; stats cover the batches
+// committed until then.
+//
+// Panics if Table, Column or PKColumn is not a valid identifier.
+//
+// Example:
+//
+//	stats, err := cipher.RotateColumn(ctx, db, encryptedcol.RotateColumnOpts{
+//	    Table: "users", Column: "email", PKColumn: "id",
+//	    Indexed: true, Normalizer: encryptedcol.NormalizeEmail,
+//	    Progress: func(s encryptedcol.RotateColumnStats) { log.Printf("%+v", s) },
+//	    Log:      os.Stderr, // JSON progress line per batch
+//	})
+func (c *Cipher) RotateColumn(ctx context.Context, db *sql.DB, opts RotateColumnOpts) (RotateColumnStats, error) {
+	for _, name := range []string{opts.Table, opts.Column, opts.PKColumn} {
+		if !isValidColumnName(name) {
+			panic("encryptedcol: invalid table or column name (must start with letter/underscore, contain only alphanumeric/underscore)")
+		}
+	}
+	if !opts.DryRun {
+		if err := c.checkWritable(); err != nil {
+			return RotateColumnStats{}, err
+		}
+	}
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultRotateBatchSize
+	}
+
+	var stats RotateColumnStats
+	var keyCounts map[string]int
+	if opts.Log != nil {
+		keyCounts = make(map[string]int)
+	}
+	for {
+		if c.closed.Load() {
+			return stats, ErrCipherClosed
+		}
+		n, err := c.rotateColumnBatch(ctx, db, &opts, batchSize, &stats, keyCounts)
+		if err != nil || n == 0 {
+			return stats, err
+		}
+		if opts.Progress != nil {
+			opts.Progress(stats)
+		}
+		if opts.Log != nil {
+			writeRotateColumnLog(opts.Log, &opts, stats, keyCounts)
+		}
+		if n < batchSize {
+			return stats, nil
+		}
+	}
+}
+
+// rotateColumnRow is a row selected by rotateColumnBatch.
+type rotateColumnRow struct {
+	pk         any
+	ciphertext []byte
+}
+
+// rotateColumnBatch rotates the next batch of rows after stats.LastPK (from
+// the start if nil) in one transaction, then adds its counts to stats and,
+// if keyCounts is not nil, the rows examined per stored key ID to keyCounts.
+// Returns the number of rows selected.
+func (c *Cipher) rotateColumnBatch(ctx context.Context, db *sql.DB, opts *RotateColumnOpts, batchSize int, stats *RotateColumnStats, keyCounts map[string]int) (int, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback() // no-op after Commit
+
+	query := fmt.Sprintf("SELECT %s, %s_encrypted FROM %s WHERE %s_encrypted IS NOT NULL AND key_id <> %s",
+		opts.PKColumn, opts.Column, opts.Table, opts.Column, c.placeholder(1))
+	defaultID := c.DefaultKeyID()
+	args := []any{defaultID}
+	if stats.LastPK != nil {
+		query += fmt.Sprintf(" AND %s > %s", opts.PKColumn, c.placeholder(2))
+		args = append(args, stats.LastPK)
+	}
+	query += fmt.Sprintf(" ORDER BY %s LIMIT %d", opts.PKColumn, batchSize)
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	var batch []rotateColumnRow
+	for rows.Next() {
+		var row rotateColumnRow
+		if err := rows.Scan(&row.pk, &row.ciphertext); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		batch = append(batch, row)
+	}
+	if err := rows.Close(); err != nil {
+		return 0, err
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	if len(batch) == 0 {
+		return 0, nil
+	}
+
+	var update string
+	if opts.Indexed {
+		update = fmt.Sprintf("UPDATE %s SET %s_encrypted = %s, %s_idx = %s, key_id = %s WHERE %s = %s AND %s_encrypted = %s",
+			opts.Table, opts.Column, c.placeholder(1), opts.Column, c.placeholder(2), c.placeholder(3),
+			opts.PKColumn, c.placeholder(4), opts.Column, c.placeholder(5))
+	} else {
+		update = fmt.Sprintf("UPDATE %s SET %s_encrypted = %s, key_id = %s WHERE %s = %s AND %s_encrypted = %s",
+			opts.Table, opts.Column, c.placeholder(1), c.placeholder(2),
+			opts.PKColumn, c.placeholder(3), opts.Column, c.placeholder(4))
+	}
+
+	var batchStats RotateColumnStats
+	var batchKeys []string
+	for _, row := range batch {
+		keyID, err := c.ExtractKeyID(row.ciphertext)
+		if keyCounts != nil && err == nil {
+			batchKeys = append(batchKeys, keyID)
+		}
+		if err == nil && keyID == defaultID {
+			batchStats.Skipped++ // only the row's key_id is stale
+			continue
+		}
+
+		var sealed *SealedValue
+		switch {
+		case err != nil:
+		case opts.DryRun:
+			var plaintext []byte
+			plaintext, err = c.Open(row.ciphertext)
+			clear(plaintext)
+		case opts.Normalizer != nil:
+			sealed, err = c.RotateStringIndexedNormalized(row.ciphertext, opts.Normalizer)
+		default:
+			sealed, err = c.RotateIndexed(row.ciphertext)
+		}
+		if err != nil {
+			if errors.Is(err, ErrCipherClosed) {
+				return 0, err
+			}
+			batchStats.Failed++
+			if opts.OnError != nil {
+				opts.OnError(row.pk, err)
+			}
+			continue
+		}
+		if opts.DryRun {
+			batchStats.Rotated++
+			continue
+		}
+
+		var res sql.Result
+		if opts.Indexed {
+			res, err = tx.ExecContext(ctx, update, sealed.Ciphertext, sealed.BlindIndex, sealed.KeyID, row.pk, row.ciphertext)
+		} else {
+			res, err = tx.ExecContext(ctx, update, sealed.Ciphertext, sealed.KeyID, row.pk, row.ciphertext)
+		}
+		if err != nil {
+			return 0, err
+		}
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return 0, err
+		}
+		if affected == 0 {
+			batchStats.Skipped++ // changed since it was read
+		} else {
+			batchStats.Rotated++
+		}
+	}
+
+	if !opts.DryRun {
+		if err := tx.Commit(); err != nil {
+			return 0, err
+		}
+	}
+	stats.Rotated += batchStats.Rotated
+	stats.Skipped += batchStats.Skipped
+	stats.Failed += batchStats.Failed
+	stats.LastPK = batch[len(batch)-1].pk
+	for _, keyID := range batchKeys {
+		keyCounts[keyID]++
+	}
+	return len(batch), nil
+}
+
+// rotateColumnLogLine is the JSON line RotateColumn writes to opts.Log.
+type rotateColumnLogLine struct {
+	Time      string         `json:"time"`
+	Table     string         `json:"table"`
+	Column    string         `json:"column"`
+	DryRun    bool           `json:"dry_run,omitempty"`
+	Processed int            `json:"processed"`
+	Rotated   int            `json:"rotated"`
+	Skipped   int            `json:"skipped"`
+	Failed    int            `json:"failed"`
+	LastPK    any            `json:"last_pk"`
+	KeyIDs    map[string]int `json:"key_ids"`
+}
+
+// writeRotateColumnLog writes one progress line to w, ignoring write errors.
+func writeRotateColumnLog(w io.Writer, opts *RotateColumnOpts, stats RotateColumnStats, keyCounts map[string]int) {
+	json.NewEncoder(w).Encode(rotateColumnLogLine{
+		Time:      time.Now().UTC().Format(time.RFC3339),
+		Table:     opts.Table,
+		Column:    opts.Column,
+		DryRun:    opts.DryRun,
+		Processed: stats.Rotated + stats.Skipped + stats.Failed,
+		Rotated:   stats.Rotated,
+		Skipped:   stats.Skipped,
+		Failed:    stats.Failed,
+		LastPK:    stats.LastPK,
+		KeyIDs:    keyCounts,
+	})
+}
@@ -0,0 +1,327 @@
+package encryptedcol
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTable is an in-memory users(id, email_encrypted, email_idx, key_id)
+// table that understands exactly the statements RotateColumn issues.
+type fakeTable struct {
+	mu      sync.Mutex
+	rows    map[int64]*fakeRow
+	commits int
+	// beforeUpdate, if set, runs before each UPDATE (to simulate concurrent writes)
+	beforeUpdate func(id int64)
+}
+
+type fakeRow struct {
+	ct, idx []byte
+	keyID   string
+}
+
+var (
+	fakeTablesMu sync.Mutex
+	fakeTables   = map[string]*fakeTable{}
+)
+
+func init() {
+	sql.Register("encryptedcol-fake", fakeDriver{})
+}
+
+// openFakeDB returns a database backed by table.
+func openFakeDB(t *testing.T, table *fakeTable) *sql.DB {
+	fakeTablesMu.Lock()
+	fakeTables[t.Name()] = table
+	fakeTablesMu.Unlock()
+	db, err := sql.Open("encryptedcol-fake", t.Name())
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) {
+	fakeTablesMu.Lock()
+	defer fakeTablesMu.Unlock()
+	return &fakeConn{table: fakeTables[name]}, nil
+}
+
+type fakeConn struct{ table *fakeTable }
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{table: c.table, query: query}, nil
+}
+func (c *fakeConn) Close() error              { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error) { return fakeTx{c.table}, nil }
+
+type fakeTx struct{ table *fakeTable }
+
+func (tx fakeTx) Commit() error {
+	tx.table.mu.Lock()
+	tx.table.commits++
+	tx.table.mu.Unlock()
+	return nil
+}
+func (fakeTx) Rollback() error { return nil }
+
+type fakeStmt struct {
+	table *fakeTable
+	query string
+}
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	if !strings.HasPrefix(s.query, "UPDATE users SET email_encrypted = $1, ") {
+		return nil, fmt.Errorf("unexpected statement: %s", s.query)
+	}
+	indexed := strings.Contains(s.query, "email_idx")
+	if !indexed {
+		// Normalize to the indexed argument layout
+		args = []driver.Value{args[0], nil, args[1], args[2], args[3]}
+	}
+	id := args[3].(int64)
+	if s.table.beforeUpdate != nil {
+		s.table.beforeUpdate(id)
+	}
+
+	s.table.mu.Lock()
+	defer s.table.mu.Unlock()
+	row, ok := s.table.rows[id]
+	if !ok || !bytes.Equal(row.ct, args[4].([]byte)) {
+		return driver.RowsAffected(0), nil
+	}
+	row.ct = args[0].([]byte)
+	if indexed {
+		row.idx = args[1].([]byte)
+	}
+	row.keyID = args[2].(string)
+	return driver.RowsAffected(1), nil
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	var limit int
+	if _, err := fmt.Sscanf(s.query[strings.LastIndex(s.query, "LIMIT"):], "LIMIT %d", &limit); err != nil {
+		return nil, err
+	}
+	defaultID := args[0].(string)
+	after := int64(-1 << 63)
+	if len(args) > 1 {
+		after = args[1].(int64)
+	}
+
+	s.table.mu.Lock()
+	defer s.table.mu.Unlock()
+	var ids []int64
+	for id, row := range s.table.rows {
+		if row.ct != nil && row.keyID != defaultID && id > after {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	if len(ids) > limit {
+		ids = ids[:limit]
+	}
+	out := &fakeRows{}
+	for _, id := range ids {
+		out.rows = append(out.rows, []driver.Value{id, bytes.Clone(s.table.rows[id].ct)})
+	}
+	return out, nil
+}
+
+type fakeRows struct{ rows [][]driver.Value }
+
+func (r *fakeRows) Columns() []string { return []string{"id", "email_encrypted"} }
+func (r *fakeRows) Close() error      { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+// newRotationTable seals count (at least 5) emails under old, with row 3 NULL
+// and row 5 corrupted.
+func newRotationTable(old *Cipher, count int) *fakeTable {
+	table := &fakeTable{rows: map[int64]*fakeRow{}}
+	for i := 1; i <= count; i++ {
+		sealed := old.SealStringIndexedNormalized(fmt.Sprintf("User%d@Example.com", i), NormalizeEmail)
+		table.rows[int64(i)] = &fakeRow{ct: sealed.Ciphertext, idx: sealed.BlindIndex, keyID: sealed.KeyID}
+	}
+	table.rows[3].ct = nil
+	table.rows[5].ct = append(bytes.Clone(table.rows[5].ct[:len(table.rows[5].ct)-1]), table.rows[5].ct[len(table.rows[5].ct)-1]^1)
+	return table
+}
+
+func rotationCiphers(t *testing.T) (old, cipher *Cipher) {
+	old, err := New(WithKey("v1", testKey("v1")))
+	require.NoError(t, err)
+	cipher, err = New(WithKey("v1", testKey("v1")), WithKey("v2", testKey("v2")), WithDefaultKeyID("v2"))
+	require.NoError(t, err)
+	return old, cipher
+}
+
+func TestRotateColumn(t *testing.T) {
+	old, cipher := rotationCiphers(t)
+	table := newRotationTable(old, 10)
+	db := openFakeDB(t, table)
+
+	var progress []RotateColumnStats
+	var failed []any
+	stats, err := cipher.RotateColumn(context.Background(), db, RotateColumnOpts{
+		Table: "users", Column: "email", PKColumn: "id", BatchSize: 4,
+		Indexed: true, Normalizer: NormalizeEmail,
+		Progress: func(s RotateColumnStats) { progress = append(progress, s) },
+		OnError:  func(pk any, err error) { failed = append(failed, pk) },
+	})
+	require.NoError(t, err)
+	require.Equal(t, RotateColumnStats{Rotated: 8, Failed: 1, LastPK: int64(10)}, stats)
+	require.Equal(t, []any{int64(5)}, failed)
+	require.Len(t, progress, 3) // 4 + 4 + 1 non-NULL rows
+	require.Equal(t, 3, table.commits)
+
+	for id, row := range table.rows {
+		if id == 3 || id == 5 {
+			continue
+		}
+		require.Equal(t, "v2", row.keyID)
+		email, err := cipher.OpenString(row.ct)
+		require.NoError(t, err)
+		require.Equal(t, fmt.Sprintf("User%d@Example.com", id), email)
+		wantIdx, err := cipher.BlindIndexWithKey("v2", []byte(NormalizeEmail(email)))
+		require.NoError(t, err)
+		require.Equal(t, wantIdx, row.idx)
+	}
+	require.Nil(t, table.rows[3].ct)
+	require.Equal(t, "v1", table.rows[5].keyID)
+
+	// Rerunning only revisits the failed row
+	stats, err = cipher.RotateColumn(context.Background(), db, RotateColumnOpts{
+		Table: "users", Column: "email", PKColumn: "id", Indexed: true, Normalizer: NormalizeEmail,
+	})
+	require.NoError(t, err)
+	require.Equal(t, RotateColumnStats{Failed: 1, LastPK: int64(5)}, stats)
+}
+
+func TestRotateColumn_DryRun(t *testing.T) {
+	old, cipher := rotationCiphers(t)
+	table := newRotationTable(old, 10)
+	before := bytes.Clone(table.rows[1].ct)
+	db := openFakeDB(t, table)
+
+	var failed []any
+	stats, err := cipher.RotateColumn(context.Background(), db, RotateColumnOpts{
+		Table: "users", Column: "email", PKColumn: "id", BatchSize: 4, DryRun: true,
+		OnError: func(pk any, err error) {
+			require.ErrorIs(t, err, ErrDecryptionFailed)
+			failed = append(failed, pk)
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, RotateColumnStats{Rotated: 8, Failed: 1, LastPK: int64(10)}, stats)
+	require.Equal(t, []any{int64(5)}, failed)
+	require.Zero(t, table.commits)
+	require.Equal(t, before, table.rows[1].ct)
+	require.Equal(t, "v1", table.rows[1].keyID)
+}
+
+func TestRotateColumn_NotIndexed(t *testing.T) {
+	old, cipher := rotationCiphers(t)
+	table := newRotationTable(old, 6)
+	oldIdx := bytes.Clone(table.rows[1].idx)
+	db := openFakeDB(t, table)
+
+	stats, err := cipher.RotateColumn(context.Background(), db, RotateColumnOpts{
+		Table: "users", Column: "email", PKColumn: "id",
+	})
+	require.NoError(t, err)
+	require.Equal(t, 4, stats.Rotated)
+	require.Equal(t, "v2", table.rows[1].keyID)
+	require.Equal(t, oldIdx, table.rows[1].idx)
+}
+
+func TestRotateColumn_ConcurrentWriteNotOverwritten(t *testing.T) {
+	old, cipher := rotationCiphers(t)
+	table := newRotationTable(old, 6)
+	db := openFakeDB(t, table)
+
+	// The application rewrites row 2 between RotateColumn's read and write
+	fresh := cipher.SealString("new@example.com")
+	table.beforeUpdate = func(id int64) {
+		if id == 2 {
+			table.mu.Lock()
+			table.rows[2].ct = fresh
+			table.mu.Unlock()
+		}
+	}
+
+	stats, err := cipher.RotateColumn(context.Background(), db, RotateColumnOpts{
+		Table: "users", Column: "email", PKColumn: "id",
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3, stats.Rotated)
+	require.Equal(t, 1, stats.Skipped)
+	require.Equal(t, fresh, table.rows[2].ct)
+}
+
+func TestRotateColumn_Errors(t *testing.T) {
+	old, cipher := rotationCiphers(t)
+	db := openFakeDB(t, newRotationTable(old, 6))
+
+	require.Panics(t, func() {
+		cipher.RotateColumn(context.Background(), db, RotateColumnOpts{Table: "users; DROP", Column: "email", PKColumn: "id"})
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := cipher.RotateColumn(ctx, db, RotateColumnOpts{Table: "users", Column: "email", PKColumn: "id"})
+	require.True(t, errors.Is(err, context.Canceled))
+
+	cipher.Close()
+	_, err = cipher.RotateColumn(context.Background(), db, RotateColumnOpts{Table: "users", Column: "email", PKColumn: "id"})
+	require.ErrorIs(t, err, ErrCipherClosed)
+}
+
+func TestRotateColumn_Log(t *testing.T) {
+	old, cipher := rotationCiphers(t)
+	table := newRotationTable(old, 10)
+	db := openFakeDB(t, table)
+
+	var log bytes.Buffer
+	_, err := cipher.RotateColumn(context.Background(), db, RotateColumnOpts{
+		Table: "users", Column: "email", PKColumn: "id", BatchSize: 4,
+		Indexed: true, Normalizer: NormalizeEmail, Log: &log,
+	})
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(log.String()), "\n")
+	require.Len(t, lines, 3) // one per batch
+	var last map[string]any
+	require.NoError(t, json.Unmarshal([]byte(lines[2]), &last))
+	require.NotEmpty(t, last["time"])
+	delete(last, "time")
+	require.Equal(t, map[string]any{
+		"table": "users", "column": "email",
+		"processed": 9.0, "rotated": 8.0, "skipped": 0.0, "failed": 1.0,
+		"last_pk": 10.0, "key_ids": map[string]any{"v1": 9.0},
+	}, last)
+
+	require.NotContains(t, log.String(), "xample") // no plaintext
+}
@@ -2,6 +2,7 @@ package encryptedcol
 
 import (
 	"bytes"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -42,6 +43,103 @@ func TestRotateValue_Null(t *testing.T) {
 	require.Nil(t, result)
 }
 
+func TestRotateValueFrom(t *testing.T) {
+	legacy, _ := New(WithKey("v1", testKey("v1")))
+	current, _ := New(WithKey("v2", testKey("v2")))
+
+	oldCiphertext := legacy.Seal([]byte("secret data"))
+
+	newCiphertext, err := current.RotateValueFrom(legacy, oldCiphertext)
+	require.NoError(t, err)
+
+	// The current cipher can open the result without holding v1
+	result, err := current.Open(newCiphertext)
+	require.NoError(t, err)
+	require.Equal(t, []byte("secret data"), result)
+
+	keyID, _ := current.ExtractKeyID(newCiphertext)
+	require.Equal(t, "v2", keyID)
+
+	// The legacy cipher cannot
+	_, err = legacy.Open(newCiphertext)
+	require.ErrorIs(t, err, ErrKeyNotFound)
+}
+
+func TestRotateValueFrom_Null(t *testing.T) {
+	legacy, _ := New(WithKey("v1", testKey("v1")))
+	current, _ := New(WithKey("v2", testKey("v2")))
+
+	result, err := current.RotateValueFrom(legacy, nil)
+	require.NoError(t, err)
+	require.Nil(t, result)
+}
+
+func TestRotateValueFrom_DecryptionError(t *testing.T) {
+	legacy, _ := New(WithKey("v1", testKey("v1")))
+	current, _ := New(WithKey("v2", testKey("v2")))
+
+	// Ciphertext under a key the source doesn't hold
+	_, err := current.RotateValueFrom(legacy, current.Seal([]byte("test")))
+	require.ErrorIs(t, err, ErrKeyNotFound)
+
+	// Closed source
+	ct := legacy.Seal([]byte("test"))
+	legacy.Close()
+	_, err = current.RotateValueFrom(legacy, ct)
+	require.ErrorIs(t, err, ErrCipherClosed)
+}
+
+func TestReseal(t *testing.T) {
+	cipher, _ := New(
+		WithKey("v1", testKey("v1")),
+		WithKey("v2", testKey("v2")),
+		WithDefaultKeyID("v2"),
+	)
+
+	old, err := cipher.SealWithKey("v1", []byte("secret data"))
+	require.NoError(t, err)
+
+	resealed, err := cipher.Reseal(old)
+	require.NoError(t, err)
+	require.NotEqual(t, old, resealed)
+
+	// Still on v1, not the default key
+	keyID, _ := cipher.ExtractKeyID(resealed)
+	require.Equal(t, "v1", keyID)
+
+	result, err := cipher.Open(resealed)
+	require.NoError(t, err)
+	require.Equal(t, []byte("secret data"), result)
+}
+
+func TestReseal_Null(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	result, err := cipher.Reseal(nil)
+	require.NoError(t, err)
+	require.Nil(t, result)
+}
+
+func TestReseal_Errors(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+	other, _ := New(WithKey("v2", testKey("v2")))
+
+	_, err := cipher.Reseal(other.Seal([]byte("test")))
+	require.ErrorIs(t, err, ErrKeyNotFound)
+
+	ct := cipher.Seal([]byte("test"))
+	ct[len(ct)-1] ^= 1
+	_, err = cipher.Reseal(ct)
+	require.ErrorIs(t, err, ErrDecryptionFailed)
+
+	_, err = cipher.Reseal([]byte{0x01})
+	require.ErrorIs(t, err, ErrInvalidFormat)
+
+	cipher.Close()
+	_, err = cipher.Reseal(other.Seal([]byte("test")))
+	require.ErrorIs(t, err, ErrCipherClosed)
+}
+
 func TestRotateBlindIndex(t *testing.T) {
 	cipher, _ := New(
 		WithKey("v1", testKey("v1")),
@@ -108,6 +206,121 @@ func TestRotateStringIndexed_Null(t *testing.T) {
 	require.Nil(t, sealed.BlindIndex)
 }
 
+func TestRotateIndexed(t *testing.T) {
+	cipher, _ := New(
+		WithKey("v1", testKey("v1")),
+		WithKey("v2", testKey("v2")),
+		WithDefaultKeyID("v2"),
+	)
+
+	raw := []byte{0x00, 0xff, 0x10, 0x80}
+	oldCiphertext, _ := cipher.SealWithKey("v1", raw)
+
+	sealed, err := cipher.RotateIndexed(oldCiphertext)
+	require.NoError(t, err)
+	require.Equal(t, "v2", sealed.KeyID)
+
+	result, err := cipher.Open(sealed.Ciphertext)
+	require.NoError(t, err)
+	require.Equal(t, raw, result)
+
+	// Blind index is over the raw bytes, matching SealIndexed
+	require.Equal(t, cipher.SealIndexed(raw).BlindIndex, sealed.BlindIndex)
+}
+
+func TestRotateIndexed_Null(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	sealed, err := cipher.RotateIndexed(nil)
+	require.NoError(t, err)
+	require.Nil(t, sealed.Ciphertext)
+	require.Nil(t, sealed.BlindIndex)
+}
+
+func TestRotateJSON(t *testing.T) {
+	type profile struct {
+		Name string   `json:"name"`
+		Tags []string `json:"tags"`
+	}
+
+	cipher, _ := New(
+		WithKey("v1", testKey("v1")),
+		WithKey("v2", testKey("v2")),
+		WithDefaultKeyID("v1"),
+	)
+	want := profile{Name: "Alice", Tags: []string{"admin"}}
+	oldCiphertext, err := SealJSON(cipher, want)
+	require.NoError(t, err)
+
+	rotated, _ := New(
+		WithKey("v1", testKey("v1")),
+		WithKey("v2", testKey("v2")),
+		WithDefaultKeyID("v2"),
+	)
+	newCiphertext, err := RotateJSON[profile](rotated, oldCiphertext)
+	require.NoError(t, err)
+
+	keyID, _ := rotated.ExtractKeyID(newCiphertext)
+	require.Equal(t, "v2", keyID)
+
+	got, err := OpenJSON[profile](rotated, newCiphertext)
+	require.NoError(t, err)
+	require.Equal(t, want, got)
+}
+
+func TestRotateJSON_PreservesPlaintext(t *testing.T) {
+	type profile struct {
+		Name string `json:"name"`
+	}
+
+	cipher, _ := New(
+		WithKey("v1", testKey("v1")),
+		WithKey("v2", testKey("v2")),
+		WithDefaultKeyID("v1"),
+	)
+	// "legacy" is not a field of profile and the id exceeds float64 precision
+	original := []byte(`{"name":"Alice", "legacy":true, "id":9007199254740993}`)
+	oldCiphertext := cipher.Seal(original)
+
+	rotated, _ := New(
+		WithKey("v1", testKey("v1")),
+		WithKey("v2", testKey("v2")),
+		WithDefaultKeyID("v2"),
+	)
+	newCiphertext, err := RotateJSON[profile](rotated, oldCiphertext)
+	require.NoError(t, err)
+	got, err := rotated.Open(newCiphertext)
+	require.NoError(t, err)
+	require.Equal(t, original, got)
+
+	newCiphertext, err = RotateJSON[map[string]any](rotated, oldCiphertext)
+	require.NoError(t, err)
+	got, err = rotated.Open(newCiphertext)
+	require.NoError(t, err)
+	require.Equal(t, original, got)
+}
+
+func TestRotateJSON_Null(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	result, err := RotateJSON[map[string]any](cipher, nil)
+	require.NoError(t, err)
+	require.Nil(t, result)
+}
+
+func TestRotateJSON_Errors(t *testing.T) {
+	cipher1, _ := New(WithKey("v1", testKey("v1")))
+	cipher2, _ := New(WithKey("v2", testKey("v2")))
+
+	ct, _ := SealJSON(cipher1, map[string]int{"a": 1})
+	_, err := RotateJSON[map[string]int](cipher2, ct)
+	require.ErrorIs(t, err, ErrKeyNotFound)
+
+	// Plaintext that does not unmarshal into T
+	_, err = RotateJSON[int](cipher1, ct)
+	require.Error(t, err)
+}
+
 func TestRotateStringIndexedNormalized(t *testing.T) {
 	cipher, _ := New(
 		WithKey("v1", testKey("v1")),
@@ -140,6 +353,87 @@ func TestRotateStringIndexedNormalized_Null(t *testing.T) {
 	require.Nil(t, sealed.BlindIndex)
 }
 
+func TestReindexString(t *testing.T) {
+	cipher, _ := New(
+		WithKey("v1", testKey("v1")),
+		WithKey("v2", testKey("v2")),
+		WithDefaultKeyID("v2"),
+	)
+
+	// Sealed under v1 when the index was built without normalization
+	old, err := cipher.SealWithKey("v1", []byte("  Alice@Example.COM "))
+	require.NoError(t, err)
+
+	idx, err := cipher.ReindexString(old, NormalizeEmail)
+	require.NoError(t, err)
+	want, _ := cipher.BlindIndexWithKey("v1", []byte("alice@example.com"))
+	require.Equal(t, want, idx)
+
+	// Without a normalizer, the raw plaintext is indexed
+	idx, err = cipher.ReindexString(old, nil)
+	require.NoError(t, err)
+	want, _ = cipher.BlindIndexWithKey("v1", []byte("  Alice@Example.COM "))
+	require.Equal(t, want, idx)
+
+	// Read-only Ciphers can backfill indexes too
+	reader, _ := New(WithKey("v1", testKey("v1")), WithReadOnly())
+	readerIdx, err := reader.ReindexString(old, nil)
+	require.NoError(t, err)
+	require.Equal(t, idx, readerIdx)
+}
+
+func TestReindexString_NullAndErrors(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	idx, err := cipher.ReindexString(nil, NormalizeEmail)
+	require.NoError(t, err)
+	require.Nil(t, idx)
+
+	other, _ := New(WithKey("v2", testKey("v2")))
+	_, err = cipher.ReindexString(other.SealString("x"), nil)
+	require.ErrorIs(t, err, ErrKeyNotFound)
+
+	cipher.Close()
+	_, err = cipher.ReindexString(other.SealString("x"), nil)
+	require.ErrorIs(t, err, ErrCipherClosed)
+}
+
+func TestRotateBlindIndexFromCiphertext(t *testing.T) {
+	cipher, _ := New(
+		WithKey("v1", testKey("v1")),
+		WithKey("v2", testKey("v2")),
+		WithDefaultKeyID("v2"),
+		WithKeyIDPredicate(false),
+	)
+	old, err := cipher.SealWithKey("v1", []byte("alice@example.com"))
+	require.NoError(t, err)
+
+	idx, err := cipher.RotateBlindIndexFromCiphertext(old)
+	require.NoError(t, err)
+	require.Equal(t, cipher.BlindIndex([]byte("alice@example.com")), idx)
+
+	// The index matches what RotateIndexed computes; the ciphertext is untouched
+	sealed, err := cipher.RotateIndexed(old)
+	require.NoError(t, err)
+	require.Equal(t, sealed.BlindIndex, idx)
+	require.True(t, cipher.NeedsRotation(old))
+
+	// Without the key_id predicate, search finds the new index
+	cond := cipher.SearchCondition("email", []byte("alice@example.com"), 1)
+	require.Contains(t, cond.Args, any(idx))
+
+	// NULL and errors
+	idx, err = cipher.RotateBlindIndexFromCiphertext(nil)
+	require.NoError(t, err)
+	require.Nil(t, idx)
+	other, _ := New(WithKey("v9", testKey("v9")))
+	_, err = cipher.RotateBlindIndexFromCiphertext(other.SealString("x"))
+	require.ErrorIs(t, err, ErrKeyNotFound)
+	cipher.Close()
+	_, err = cipher.RotateBlindIndexFromCiphertext(old)
+	require.ErrorIs(t, err, ErrCipherClosed)
+}
+
 func TestNeedsRotation(t *testing.T) {
 	cipher, _ := New(
 		WithKey("v1", testKey("v1")),
@@ -222,6 +516,182 @@ func TestRotateStringIndexedNormalized_DecryptionError(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestRotateBatch(t *testing.T) {
+	cipher, _ := New(
+		WithKey("v1", testKey("v1")),
+		WithKey("v2", testKey("v2")),
+		WithDefaultKeyID("v2"),
+	)
+	other, _ := New(WithKey("v3", testKey("v3")))
+
+	onV1, _ := cipher.SealWithKey("v1", []byte("alice@example.com"))
+	onV2 := cipher.Seal([]byte("bob@example.com"))
+	large, _ := cipher.SealWithKey("v1", []byte(strings.Repeat("compressible ", 200)))
+
+	results, err := cipher.RotateBatch([][]byte{
+		onV1,
+		nil,
+		onV2,
+		{0x00},
+		other.Seal([]byte("carol")),
+		large,
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 6)
+
+	// Rotated item
+	require.True(t, results[0].Rotated)
+	require.NoError(t, results[0].Err)
+	require.Equal(t, "v2", results[0].KeyID)
+	pt, err := cipher.Open(results[0].Ciphertext)
+	require.NoError(t, err)
+	require.Equal(t, []byte("alice@example.com"), pt)
+	require.Equal(t, cipher.BlindIndex([]byte("alice@example.com")), results[0].BlindIndex)
+
+	// NULL and already-current items are skipped
+	for _, i := range []int{1, 2} {
+		require.False(t, results[i].Rotated)
+		require.NoError(t, results[i].Err)
+		require.Nil(t, results[i].Ciphertext)
+	}
+
+	// Failures are per item
+	require.ErrorIs(t, results[3].Err, ErrInvalidFormat)
+	require.ErrorIs(t, results[4].Err, ErrKeyNotFound)
+	require.False(t, results[3].Rotated)
+
+	// Compressed payloads round-trip
+	require.True(t, results[5].Rotated)
+	pt, err = cipher.Open(results[5].Ciphertext)
+	require.NoError(t, err)
+	require.Equal(t, strings.Repeat("compressible ", 200), string(pt))
+}
+
+func TestRotateBatch_MatchesRotateStringIndexed(t *testing.T) {
+	cipher, _ := New(
+		WithKey("v1", testKey("v1")),
+		WithKey("v2", testKey("v2")),
+		WithDefaultKeyID("v2"),
+	)
+	old, _ := cipher.SealWithKey("v1", []byte("alice"))
+
+	results, err := cipher.RotateBatch([][]byte{old})
+	require.NoError(t, err)
+	single, err := cipher.RotateStringIndexed(old)
+	require.NoError(t, err)
+	require.Equal(t, single.BlindIndex, results[0].BlindIndex)
+	require.Equal(t, single.KeyID, results[0].KeyID)
+}
+
+func TestRotateBatch_Closed(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+	cipher.Close()
+
+	_, err := cipher.RotateBatch([][]byte{nil})
+	require.ErrorIs(t, err, ErrCipherClosed)
+}
+
+func TestNeedsRotationBatch(t *testing.T) {
+	cipher, _ := New(
+		WithKey("v1", testKey("v1")),
+		WithKey("v2", testKey("v2")),
+		WithDefaultKeyID("v2"),
+	)
+	onV1, _ := cipher.SealWithKey("v1", []byte("a"))
+	onV2 := cipher.Seal([]byte("b"))
+
+	got := cipher.NeedsRotationBatch([][]byte{onV1, onV2, nil, {0x00}})
+	require.Equal(t, []bool{true, false, false, false}, got)
+	require.Empty(t, cipher.NeedsRotationBatch(nil))
+}
+
+func TestVerifyDecryptable(t *testing.T) {
+	retired, _ := New(WithKey("v0", testKey("v0")))
+	cipher, _ := New(
+		WithKey("v1", testKey("v1")),
+		WithKey("v2", testKey("v2")),
+		WithDefaultKeyID("v2"),
+	)
+	onV1, _ := cipher.SealWithKey("v1", []byte("a"))
+	tampered := cipher.Seal([]byte("b"))
+	tampered[len(tampered)-1] ^= 1
+
+	errs := cipher.VerifyDecryptable([][]byte{onV1, cipher.Seal([]byte("c")), nil, retired.Seal([]byte("d")), tampered, {0x00}})
+	require.Len(t, errs, 6)
+	require.NoError(t, errs[0])
+	require.NoError(t, errs[1])
+	require.NoError(t, errs[2])
+	require.ErrorIs(t, errs[3], ErrKeyNotFound)
+	require.ErrorIs(t, errs[4], ErrDecryptionFailed)
+	require.ErrorIs(t, errs[5], ErrInvalidFormat)
+
+	cipher.Close()
+	require.Equal(t, []error{ErrCipherClosed}, cipher.VerifyDecryptable([][]byte{onV1}))
+}
+
+func TestRotationStats(t *testing.T) {
+	cipher, _ := New(
+		WithKey("v1", testKey("v1")),
+		WithKey("v2", testKey("v2")),
+		WithDefaultKeyID("v2"),
+	)
+	other, _ := New(WithKey("legacy", testKey("legacy")))
+
+	var ciphertexts [][]byte
+	for i := 0; i < 3; i++ {
+		ct, _ := cipher.SealWithKey("v1", []byte("a"))
+		ciphertexts = append(ciphertexts, ct)
+	}
+	ciphertexts = append(ciphertexts,
+		cipher.Seal([]byte("b")),
+		other.Seal([]byte("c")),
+		nil,
+		nil,
+		[]byte{0x00},
+		[]byte{0xff, 0x01, 'x'},
+	)
+
+	stats := cipher.RotationStats(ciphertexts)
+	require.Equal(t, RotationStats{
+		Total:         9,
+		Null:          2,
+		Malformed:     2,
+		ByKeyID:       map[string]int{"v1": 3, "v2": 1, "legacy": 1},
+		NeedsRotation: 4,
+	}, stats)
+}
+
+func TestKeyIDsInUse(t *testing.T) {
+	cipher, _ := New(
+		WithKey("v1", testKey("v1")),
+		WithKey("v2", testKey("v2")),
+		WithDefaultKeyID("v2"),
+	)
+	other, _ := New(WithKey("legacy", testKey("legacy")))
+
+	onV1, _ := cipher.SealWithKey("v1", []byte("a"))
+	inUse := cipher.KeyIDsInUse([][]byte{
+		onV1,
+		onV1,
+		cipher.Seal([]byte("b")),
+		other.Seal([]byte("c")), // keys the cipher doesn't hold are still counted
+		nil,
+		[]byte{0x00},
+	})
+	require.Equal(t, map[string]int{"v1": 2, "v2": 1, "legacy": 1, "": 1}, inUse)
+
+	require.Empty(t, cipher.KeyIDsInUse([][]byte{nil, nil}))
+}
+
+func TestRotationStats_Empty(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	stats := cipher.RotationStats(nil)
+	require.Zero(t, stats.Total)
+	require.NotNil(t, stats.ByKeyID)
+	require.Empty(t, stats.ByKeyID)
+}
+
 func TestNeedsRotation_InvalidFormat(t *testing.T) {
 	cipher, _ := New(WithKey("v1", testKey("v1")))
 
@@ -275,3 +745,48 @@ func TestRotation_CompleteWorkflow(t *testing.T) {
 	// New index matches rotated data
 	require.True(t, bytes.Equal(newSealed.BlindIndex, idx3))
 }
+
+func TestOpenChain(t *testing.T) {
+	legacy, _ := New(WithKey("v1", testKey("legacy")))
+	current, _ := New(WithKey("v2", testKey("v2")))
+	unrelated, _ := New(WithKey("v1", testKey("other")))
+
+	for _, tt := range []struct {
+		name string
+		ct   []byte
+		want string
+	}{
+		{"current", current.SealString("new"), "new"},
+		{"legacy", legacy.SealString("old"), "old"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			pt, err := OpenChain(tt.ct, current, unrelated, legacy)
+			require.NoError(t, err)
+			require.Equal(t, []byte(tt.want), pt)
+		})
+	}
+
+	pt, err := OpenChain(nil, current)
+	require.NoError(t, err)
+	require.Nil(t, pt)
+}
+
+func TestOpenChain_Errors(t *testing.T) {
+	current, _ := New(WithKey("v2", testKey("v2")))
+	unrelated, _ := New(WithKey("v1", testKey("other")))
+	orphan, _ := New(WithKey("v1", testKey("v1")))
+	ct := orphan.SealString("x")
+
+	// Malformed input is reported as such, not as a chain failure
+	_, err := OpenChain([]byte{0x00}, current)
+	require.ErrorIs(t, err, ErrInvalidFormat)
+	require.NotErrorIs(t, err, ErrNoCipherCouldDecrypt)
+
+	_, err = OpenChain(ct, current, unrelated)
+	require.ErrorIs(t, err, ErrNoCipherCouldDecrypt)
+	require.ErrorIs(t, err, ErrKeyNotFound)
+	require.ErrorIs(t, err, ErrDecryptionFailed)
+
+	_, err = OpenChain(ct)
+	require.Equal(t, ErrNoCipherCouldDecrypt, err)
+}
@@ -0,0 +1,73 @@
+package encryptedcol
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ColumnDDL returns PostgreSQL migration statements for an encrypted column:
+// a {column}_encrypted BYTEA column and, if searchable, a {column}_idx BYTEA
+// column with a composite index on (key_id, {column}_idx), the order that
+// SearchCondition queries can use.
+//
+// The table's key_id column is shared by all encrypted columns and is not
+// created here (see the README's Database Schema section).
+//
+// Panics if table or column is not a valid identifier (letters, digits and
+// underscores, not starting with a digit).
+//
+// Example:
+//
+//	fmt.Println(encryptedcol.ColumnDDL("users", "email", true))
+//	// ALTER TABLE users ADD COLUMN email_encrypted BYTEA;
+//	// ALTER TABLE users ADD COLUMN email_idx BYTEA;
+//	// CREATE INDEX idx_users_email ON users (key_id, email_idx);
+func ColumnDDL(table, column string, searchable bool) string {
+	if !isValidColumnName(table) {
+		panic("encryptedcol: invalid table name (must start with letter/underscore, contain only alphanumeric/underscore)")
+	}
+	if !isValidColumnName(column) {
+		panic("encryptedcol: invalid column name (must start with letter/underscore, contain only alphanumeric/underscore)")
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "ALTER TABLE %s ADD COLUMN %s_encrypted BYTEA;\n", table, column)
+	if searchable {
+		fmt.Fprintf(&b, "ALTER TABLE %s ADD COLUMN %s_idx BYTEA;\n", table, column)
+		fmt.Fprintf(&b, "CREATE INDEX idx_%s_%s ON %s (key_id, %s_idx);\n", table, column, table, column)
+	}
+	return b.String()
+}
+
+// WriteColumns returns the SET fragment for writing a sealed value to the
+// columns ColumnDDL creates, together with the table's key_id:
+//
+//	email_encrypted = $1, email_idx = $2, key_id = $3
+//
+// Args are the ciphertext, blind index and key ID, in that order. Writing all
+// three together keeps a row's key_id consistent with its ciphertext and index.
+// A NULL SealedValue writes NULL to both data columns.
+//
+// key_id is per row: when a row has several encrypted columns, write all of
+// them with values sealed under the same key, or searches on the others will
+// look under the wrong key_id.
+//
+// paramOffset is the first parameter number, as for SearchCondition; it is
+// ignored with PlaceholderQuestion. Panics if column is not a valid identifier.
+//
+// Example:
+//
+//	set, args := cipher.WriteColumns("email", cipher.SealStringIndexed(email), 1)
+//	db.Exec("UPDATE users SET "+set+" WHERE id = $4", append(args, id)...)
+func (c *Cipher) WriteColumns(column string, sealed *SealedValue, paramOffset int) (setSQL string, args []interface{}) {
+	c.validateSearchParams(column, paramOffset)
+	if c.config.placeholderStyle == PlaceholderDollar && paramOffset+2 > maxParamNumber {
+		panic(fmt.Sprintf("encryptedcol: invalid paramOffset (must be 1-%d)", maxParamNumber-2))
+	}
+
+	setSQL = fmt.Sprintf("%s_encrypted = %s, %s_idx = %s, key_id = %s",
+		column, c.placeholder(paramOffset),
+		column, c.placeholder(paramOffset+1),
+		c.placeholder(paramOffset+2))
+	return setSQL, []interface{}{sealed.Ciphertext, sealed.BlindIndex, sealed.KeyID}
+}
@@ -0,0 +1,76 @@
+package encryptedcol
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestColumnDDL(t *testing.T) {
+	require.Equal(t,
+		"ALTER TABLE users ADD COLUMN email_encrypted BYTEA;\n"+
+			"ALTER TABLE users ADD COLUMN email_idx BYTEA;\n"+
+			"CREATE INDEX idx_users_email ON users (key_id, email_idx);\n",
+		ColumnDDL("users", "email", true))
+
+	require.Equal(t,
+		"ALTER TABLE users ADD COLUMN notes_encrypted BYTEA;\n",
+		ColumnDDL("users", "notes", false))
+}
+
+func TestColumnDDL_InvalidIdentifiers(t *testing.T) {
+	tests := []struct {
+		name   string
+		table  string
+		column string
+	}{
+		{"empty table", "", "email"},
+		{"empty column", "users", ""},
+		{"injection in table", "users; DROP TABLE users", "email"},
+		{"injection in column", "users", "email--"},
+		{"schema-qualified table", "public.users", "email"},
+		{"leading digit", "1users", "email"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Panics(t, func() {
+				ColumnDDL(tt.table, tt.column, true)
+			})
+		})
+	}
+}
+
+func TestWriteColumns(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+	sealed := cipher.SealStringIndexed("alice@example.com")
+
+	set, args := cipher.WriteColumns("email", sealed, 4)
+	require.Equal(t, "email_encrypted = $4, email_idx = $5, key_id = $6", set)
+	require.Equal(t, []interface{}{sealed.Ciphertext, sealed.BlindIndex, "v1"}, args)
+
+	// NULL clears both data columns
+	null := &SealedValue{KeyID: "v1"}
+	_, args = cipher.WriteColumns("email", null, 1)
+	require.Nil(t, args[0])
+	require.Nil(t, args[1])
+	require.Equal(t, "v1", args[2])
+}
+
+func TestWriteColumns_QuestionPlaceholders(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")), WithPlaceholderStyle(PlaceholderQuestion))
+
+	set, args := cipher.WriteColumns("email", cipher.SealStringIndexed("a"), 1)
+	require.Equal(t, "email_encrypted = ?, email_idx = ?, key_id = ?", set)
+	require.Len(t, args, 3)
+}
+
+func TestWriteColumns_Invalid(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+	sealed := cipher.SealStringIndexed("a")
+
+	require.Panics(t, func() { cipher.WriteColumns("email = NULL --", sealed, 1) })
+	require.Panics(t, func() { cipher.WriteColumns("email", sealed, 0) })
+	require.Panics(t, func() { cipher.WriteColumns("email", sealed, maxParamNumber-1) })
+	require.NotPanics(t, func() { cipher.WriteColumns("email", sealed, maxParamNumber-2) })
+}
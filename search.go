@@ -1,6 +1,7 @@
 package encryptedcol
 
 import (
+	"encoding/hex"
 	"fmt"
 	"strings"
 )
@@ -31,11 +32,89 @@ func isValidColumnName(s string) bool {
 	return true
 }
 
+// PlaceholderStyle selects how SQL parameter placeholders are rendered.
+type PlaceholderStyle int
+
+const (
+	// PlaceholderDollar renders numbered placeholders ($1, $2, ...) for PostgreSQL.
+	// This is the default.
+	PlaceholderDollar PlaceholderStyle = iota
+
+	// PlaceholderQuestion renders positional placeholders (?) for MySQL and SQLite.
+	// paramOffset is ignored since ? placeholders are not numbered.
+	PlaceholderQuestion
+)
+
+// valid reports whether s is a known placeholder style.
+func (s PlaceholderStyle) valid() bool {
+	return s == PlaceholderDollar || s == PlaceholderQuestion
+}
+
+// placeholder renders the placeholder for parameter number n.
+func (c *Cipher) placeholder(n int) string {
+	if c.config.placeholderStyle == PlaceholderQuestion {
+		return "?"
+	}
+	return fmt.Sprintf("$%d", n)
+}
+
+// validateSearchParams panics if column is not a safe identifier or
+// paramOffset is outside the PostgreSQL parameter range (Dollar style only).
+func (c *Cipher) validateSearchParams(column string, paramOffset int) {
+	if !isValidColumnName(column) {
+		panic("encryptedcol: invalid column name (must start with letter/underscore, contain only alphanumeric/underscore)")
+	}
+
+	if c.config.placeholderStyle != PlaceholderDollar {
+		return
+	}
+
+	if paramOffset < 1 || paramOffset > maxParamNumber {
+		panic(fmt.Sprintf("encryptedcol: invalid paramOffset (must be 1-%d)", maxParamNumber))
+	}
+}
+
+// validateParamLimit panics if a condition with two parameters per key (one
+// with WithKeyIDPredicate(false)) would exceed the PostgreSQL parameter limit.
+// Only applies in Dollar style.
+func (c *Cipher) validateParamLimit(paramOffset int, keyCount int) {
+	c.validateParamLimitN(paramOffset, keyCount, 1)
+}
+
+// validateParamLimitN is validateParamLimit for conditions with indexCount
+// index parameters per key.
+func (c *Cipher) validateParamLimitN(paramOffset int, keyCount int, indexCount int) {
+	if c.config.placeholderStyle != PlaceholderDollar {
+		return
+	}
+	perKey := 1 + indexCount
+	if c.config.omitKeyIDPredicate {
+		perKey = indexCount
+	}
+	maxParam := paramOffset + (keyCount * perKey) - 1
+	if maxParam > maxParamNumber {
+		panic(fmt.Sprintf("encryptedcol: too many keys (%d) would exceed PostgreSQL parameter limit", keyCount))
+	}
+}
+
+// keyCondition renders one key version's condition as
+// "(key_id = $n AND <match>)" and appends keyID to args, or returns the bare
+// match with args unchanged under WithKeyIDPredicate(false). match renders the
+// index predicate given its parameter number. Returns the condition, args and
+// the parameter number for the index argument(s).
+func (c *Cipher) keyCondition(keyID string, args []interface{}, paramOffset int, match func(n int) string) (string, []interface{}, int) {
+	if c.config.omitKeyIDPredicate {
+		return match(paramOffset), args, paramOffset
+	}
+	cond := fmt.Sprintf("(key_id = %s AND %s)", c.placeholder(paramOffset), match(paramOffset+1))
+	return cond, append(args, keyID), paramOffset + 1
+}
+
 // SearchCondition holds a SQL WHERE clause fragment and its arguments
 // for blind index searches across multiple key versions.
 type SearchCondition struct {
 	SQL  string        // SQL fragment like "(key_id = $1 AND email_idx = $2) OR ..."
-	Args []interface{} // Interleaved key_ids and blind indexes
+	Args []interface{} // Interleaved key_ids and blind indexes (indexes only with WithKeyIDPredicate(false))
 }
 
 // SearchCondition generates a SQL WHERE clause for blind index search
@@ -45,8 +124,13 @@ type SearchCondition struct {
 //
 //	(key_id = $1 AND {column}_idx = $2) OR (key_id = $3 AND {column}_idx = $4)
 //
+// With WithKeyIDPredicate(false), the key_id checks are omitted:
+//
+//	{column}_idx = $1 OR {column}_idx = $2
+//
 // paramOffset specifies the starting parameter number ($1, $2, etc.).
-// Use this when composing with other WHERE conditions.
+// Use this when composing with other WHERE conditions. It is ignored when the
+// Cipher is configured with WithPlaceholderStyle(PlaceholderQuestion).
 //
 // Example:
 //
@@ -54,13 +138,15 @@ type SearchCondition struct {
 //	query := fmt.Sprintf("SELECT * FROM users WHERE %s", cond.SQL)
 //	rows, _ := db.Query(query, cond.Args...)
 func (c *Cipher) SearchCondition(column string, plaintext []byte, paramOffset int) *SearchCondition {
-	if !isValidColumnName(column) {
-		panic("encryptedcol: invalid column name (must start with letter/underscore, contain only alphanumeric/underscore)")
-	}
+	return c.searchCondition(column, plaintext, paramOffset, func(r *keyring, keyID string) []byte {
+		return c.computeHMAC(r, keyID, plaintext)
+	})
+}
 
-	if paramOffset < 1 || paramOffset > maxParamNumber {
-		panic(fmt.Sprintf("encryptedcol: invalid paramOffset (must be 1-%d)", maxParamNumber))
-	}
+// searchCondition builds an equality condition across all active key versions,
+// using indexFn to compute the blind index for each key in a single key snapshot.
+func (c *Cipher) searchCondition(column string, plaintext []byte, paramOffset int, indexFn func(r *keyring, keyID string) []byte) *SearchCondition {
+	c.validateSearchParams(column, paramOffset)
 
 	if plaintext == nil {
 		return &SearchCondition{
@@ -69,28 +155,25 @@ func (c *Cipher) SearchCondition(column string, plaintext []byte, paramOffset in
 		}
 	}
 
-	ids := c.ActiveKeyIDs()
+	r := c.mustAcquire()
+	defer r.release()
 
-	// Check that parameters won't exceed PostgreSQL limit
-	maxParam := paramOffset + (len(ids) * 2) - 1
-	if maxParam > maxParamNumber {
-		panic(fmt.Sprintf("encryptedcol: too many keys (%d) would exceed PostgreSQL parameter limit", len(ids)))
-	}
+	ids := sortedMapKeys(r.keys)
+
+	c.validateParamLimit(paramOffset, len(ids))
 
 	parts := make([]string, 0, len(ids))
 	args := make([]interface{}, 0, len(ids)*2)
 
 	for _, keyID := range ids {
-		idxHash, err := c.BlindIndexWithKey(keyID, plaintext)
-		if err != nil {
-			// This should never happen since keyID comes from ActiveKeyIDs()
-			panic("encryptedcol: internal error: " + err.Error())
-		}
+		idxHash := indexFn(r, keyID)
 
-		part := fmt.Sprintf("(key_id = $%d AND %s_idx = $%d)", paramOffset, column, paramOffset+1)
+		part, keyArgs, n := c.keyCondition(keyID, args, paramOffset, func(n int) string {
+			return fmt.Sprintf("%s_idx = %s", column, c.placeholder(n))
+		})
 		parts = append(parts, part)
-		args = append(args, keyID, idxHash)
-		paramOffset += 2
+		args = append(keyArgs, idxHash)
+		paramOffset = n + 1
 	}
 
 	return &SearchCondition{
@@ -130,3 +213,319 @@ func (c *Cipher) SearchConditionNormalized(column string, plaintext []byte, para
 	normalized := norm(string(plaintext))
 	return c.SearchCondition(column, []byte(normalized), paramOffset)
 }
+
+// SearchConditionMulti generates a search condition that matches rows indexed
+// with any of several normalizers, across all active key versions:
+//
+//	(key_id = $1 AND (email_idx = $2 OR email_idx = $3)) OR (key_id = $4 AND (...))
+//
+// It is a migration aid for columns whose rows were indexed inconsistently
+// (e.g. some with NormalizeEmail, some with NormalizeNone). Duplicate
+// normalized values are queried once; if all normalizers agree, the SQL is
+// the same as SearchConditionString. With no normalizers, plaintext is used as is.
+//
+// Each extra normalizer adds a parameter per key version and broadens the
+// match, so rows that differ only by the normalization (e.g. case) will match.
+// Rotate the column to a single normalizer and return to SearchCondition.
+func (c *Cipher) SearchConditionMulti(column string, plaintext string, paramOffset int, norms ...Normalizer) *SearchCondition {
+	c.validateSearchParams(column, paramOffset)
+
+	values := [][]byte{[]byte(plaintext)}
+	if len(norms) > 0 {
+		values = values[:0]
+		seen := make(map[string]bool, len(norms))
+		for _, norm := range norms {
+			normalized := norm(plaintext)
+			if !seen[normalized] {
+				seen[normalized] = true
+				values = append(values, []byte(normalized))
+			}
+		}
+	}
+
+	r := c.mustAcquire()
+	defer r.release()
+
+	ids := sortedMapKeys(r.keys)
+	c.validateParamLimitN(paramOffset, len(ids), len(values))
+
+	parts := make([]string, 0, len(ids))
+	args := make([]interface{}, 0, len(ids)*(1+len(values)))
+
+	for _, keyID := range ids {
+		part, keyArgs, n := c.keyCondition(keyID, args, paramOffset, func(n int) string {
+			matches := make([]string, len(values))
+			for i := range values {
+				matches[i] = fmt.Sprintf("%s_idx = %s", column, c.placeholder(n+i))
+			}
+			if len(matches) == 1 || c.config.omitKeyIDPredicate {
+				return strings.Join(matches, " OR ")
+			}
+			return "(" + strings.Join(matches, " OR ") + ")"
+		})
+		parts = append(parts, part)
+		args = keyArgs
+		for _, v := range values {
+			args = append(args, c.computeHMAC(r, keyID, v))
+		}
+		paramOffset = n + len(values)
+	}
+
+	return &SearchCondition{
+		SQL:  strings.Join(parts, " OR "),
+		Args: args,
+	}
+}
+
+// SearchConditionIn generates a SQL WHERE clause matching any of several values
+// across all active key versions (the encrypted equivalent of IN).
+//
+// The generated SQL passes each key's blind indexes as a single array parameter:
+//
+//	(key_id = $1 AND {column}_idx = ANY($2)) OR (key_id = $3 AND {column}_idx = ANY($4))
+//
+// Each array argument is a [][]byte; drivers such as pgx bind it as BYTEA[].
+// With lib/pq, wrap it with pq.Array.
+//
+// With PlaceholderQuestion (no array parameters in MySQL/SQLite), each blind
+// index is passed as its own argument instead:
+//
+//	(key_id = ? AND {column}_idx IN (?, ?)) OR (key_id = ? AND {column}_idx IN (?, ?))
+//
+// Nil entries are skipped. If no non-nil values remain, the condition is "FALSE".
+func (c *Cipher) SearchConditionIn(column string, plaintexts [][]byte, paramOffset int) *SearchCondition {
+	c.validateSearchParams(column, paramOffset)
+
+	values := make([][]byte, 0, len(plaintexts))
+	for _, p := range plaintexts {
+		if p != nil {
+			values = append(values, p)
+		}
+	}
+	if len(values) == 0 {
+		return &SearchCondition{
+			SQL:  "FALSE", // NULL values can't match
+			Args: nil,
+		}
+	}
+
+	r := c.mustAcquire()
+	defer r.release()
+
+	ids := sortedMapKeys(r.keys)
+	c.validateParamLimit(paramOffset, len(ids))
+
+	parts := make([]string, 0, len(ids))
+	args := make([]interface{}, 0, len(ids)*2)
+
+	for _, keyID := range ids {
+		indexes := make([][]byte, len(values))
+		for i, v := range values {
+			indexes[i] = c.computeHMAC(r, keyID, v)
+		}
+
+		if c.config.placeholderStyle == PlaceholderQuestion {
+			marks := strings.TrimSuffix(strings.Repeat("?, ", len(indexes)), ", ")
+			part, keyArgs, _ := c.keyCondition(keyID, args, paramOffset, func(int) string {
+				return fmt.Sprintf("%s_idx IN (%s)", column, marks)
+			})
+			parts = append(parts, part)
+			args = keyArgs
+			for _, idx := range indexes {
+				args = append(args, idx)
+			}
+			continue
+		}
+
+		part, keyArgs, n := c.keyCondition(keyID, args, paramOffset, func(n int) string {
+			return fmt.Sprintf("%s_idx = ANY(%s)", column, c.placeholder(n))
+		})
+		parts = append(parts, part)
+		args = append(keyArgs, indexes)
+		paramOffset = n + 1
+	}
+
+	return &SearchCondition{
+		SQL:  strings.Join(parts, " OR "),
+		Args: args,
+	}
+}
+
+// SearchConditionTagged generates a SQL WHERE clause matching indexes from
+// BlindIndexTagged, with one tagged index per active key version and no
+// key_id column:
+//
+//	{column}_idx IN ($1, $2)
+//
+// Each argument is a []byte. The key ID inside each index already ties it to
+// its key version, so WithKeyIDPredicate has no effect here.
+// If plaintext is nil, the condition is "FALSE".
+//
+// Example:
+//
+//	cond := cipher.SearchConditionTagged("email", []byte("alice@example.com"), 1)
+//	rows, _ := db.Query("SELECT * FROM users WHERE "+cond.SQL, cond.Args...)
+func (c *Cipher) SearchConditionTagged(column string, plaintext []byte, paramOffset int) *SearchCondition {
+	c.validateSearchParams(column, paramOffset)
+
+	if plaintext == nil {
+		return &SearchCondition{
+			SQL:  "FALSE", // NULL values can't match
+			Args: nil,
+		}
+	}
+
+	r := c.mustAcquire()
+	defer r.release()
+
+	ids := sortedMapKeys(r.keys)
+	if c.config.placeholderStyle == PlaceholderDollar && paramOffset+len(ids)-1 > maxParamNumber {
+		panic(fmt.Sprintf("encryptedcol: too many keys (%d) would exceed PostgreSQL parameter limit", len(ids)))
+	}
+
+	marks := make([]string, len(ids))
+	args := make([]interface{}, len(ids))
+	for i, keyID := range ids {
+		marks[i] = c.placeholder(paramOffset + i)
+		args[i] = appendTaggedIndex(nil, keyID, c.computeHMAC(r, keyID, plaintext))
+	}
+
+	return &SearchCondition{
+		SQL:  fmt.Sprintf("%s_idx IN (%s)", column, strings.Join(marks, ", ")),
+		Args: args,
+	}
+}
+
+// SearchConditionNotEqual generates a SQL WHERE clause excluding rows that match
+// plaintext across all active key versions:
+//
+//	NOT ((key_id = $1 AND {column}_idx = $2) OR (key_id = $3 AND {column}_idx = $4))
+//
+// A nil plaintext excludes nothing and yields "TRUE".
+//
+// Note: as with SQL's <>, rows whose {column}_idx is NULL evaluate to NULL and
+// are not returned. Add "OR {column}_idx IS NULL" if they should be included.
+func (c *Cipher) SearchConditionNotEqual(column string, plaintext []byte, paramOffset int) *SearchCondition {
+	if plaintext == nil {
+		c.validateSearchParams(column, paramOffset)
+		return &SearchCondition{
+			SQL:  "TRUE", // Excluding NULL excludes nothing
+			Args: nil,
+		}
+	}
+
+	cond := c.SearchCondition(column, plaintext, paramOffset)
+	cond.SQL = "NOT (" + cond.SQL + ")"
+	return cond
+}
+
+// SearchConditionStretched generates a search condition for stretched blind
+// indexes written with BlindIndexStretched. The generated SQL is identical to
+// SearchCondition; each key version costs a full stretched index computation.
+func (c *Cipher) SearchConditionStretched(column string, plaintext []byte, paramOffset int) *SearchCondition {
+	return c.searchCondition(column, plaintext, paramOffset, func(r *keyring, keyID string) []byte {
+		return c.computeStretchedIndex(r, keyID, plaintext)
+	})
+}
+
+// SearchConditionForColumn generates a search condition for column-bound blind
+// indexes written with BlindIndexForColumn or SealStringIndexedForColumn.
+// The generated SQL is identical to SearchCondition; only the index values differ.
+func (c *Cipher) SearchConditionForColumn(column string, plaintext []byte, paramOffset int) *SearchCondition {
+	return c.searchCondition(column, plaintext, paramOffset, func(r *keyring, keyID string) []byte {
+		return c.blindIndexForColumnWithKey(r, keyID, column, plaintext)
+	})
+}
+
+// SearchConditionHex generates the same SQL as SearchCondition for TEXT
+// {column}_idx columns written with BlindIndexHex: the blind index arguments
+// are lowercase hex strings instead of []byte.
+func (c *Cipher) SearchConditionHex(column string, plaintext []byte, paramOffset int) *SearchCondition {
+	cond := c.SearchCondition(column, plaintext, paramOffset)
+	for i, arg := range cond.Args {
+		if index, ok := arg.([]byte); ok {
+			cond.Args[i] = hex.EncodeToString(index)
+		}
+	}
+	return cond
+}
+
+// SearchBuilder composes blind index searches on several columns into one
+// condition joined with AND, numbering parameters automatically:
+//
+//	sqlCond, args := cipher.NewSearchBuilder().
+//		Add("email", []byte(email), NormalizeEmail).
+//		Add("phone", []byte(phone), NormalizePhone).
+//		Build(1)
+//	// ((key_id = $1 AND email_idx = $2) OR ...) AND ((key_id = $5 AND phone_idx = $6) OR ...)
+//
+// A nil plaintext emits FALSE for its clause (NULL never matches), so the whole
+// condition matches nothing; call SkipNulls to drop those clauses instead.
+// A SearchBuilder is not safe for concurrent use.
+type SearchBuilder struct {
+	c         *Cipher
+	terms     []searchTerm
+	skipNulls bool
+}
+
+// searchTerm is one column search added to a SearchBuilder.
+type searchTerm struct {
+	column    string
+	plaintext []byte
+	norm      Normalizer
+}
+
+// NewSearchBuilder returns an empty SearchBuilder using this Cipher's keys and
+// placeholder style.
+func (c *Cipher) NewSearchBuilder() *SearchBuilder {
+	return &SearchBuilder{c: c}
+}
+
+// Add adds an equality search on column. norm is applied to plaintext before
+// computing blind indexes, as in SearchConditionNormalized; pass nil to search
+// the plaintext as is. Panics if column is not a valid identifier.
+func (b *SearchBuilder) Add(column string, plaintext []byte, norm Normalizer) *SearchBuilder {
+	if !isValidColumnName(column) {
+		panic("encryptedcol: invalid column name (must start with letter/underscore, contain only alphanumeric/underscore)")
+	}
+	b.terms = append(b.terms, searchTerm{column: column, plaintext: plaintext, norm: norm})
+	return b
+}
+
+// SkipNulls makes Build omit clauses whose plaintext is nil instead of
+// emitting FALSE, so a nil value means "don't filter on this column".
+func (b *SearchBuilder) SkipNulls() *SearchBuilder {
+	b.skipNulls = true
+	return b
+}
+
+// Build renders the clauses joined with AND, numbering parameters from
+// startOffset. Each clause is wrapped in parentheses when there are several.
+// Returns "TRUE" and no arguments if there are no clauses to emit.
+func (b *SearchBuilder) Build(startOffset int) (sql string, args []interface{}) {
+	paramOffset := startOffset
+	parts := make([]string, 0, len(b.terms))
+
+	for _, term := range b.terms {
+		if term.plaintext == nil && b.skipNulls {
+			continue
+		}
+		var cond *SearchCondition
+		if term.norm != nil {
+			cond = b.c.SearchConditionNormalized(term.column, term.plaintext, paramOffset, term.norm)
+		} else {
+			cond = b.c.SearchCondition(term.column, term.plaintext, paramOffset)
+		}
+		parts = append(parts, cond.SQL)
+		args = append(args, cond.Args...)
+		paramOffset += len(cond.Args)
+	}
+
+	switch len(parts) {
+	case 0:
+		return "TRUE", nil
+	case 1:
+		return parts[0], args
+	}
+	return "(" + strings.Join(parts, ") AND (") + ")", args
+}
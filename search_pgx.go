@@ -0,0 +1,56 @@
+package encryptedcol
+
+import "fmt"
+
+// SearchConditionPgx generates a compact SQL WHERE clause for blind index
+// search across all active key versions, using two array parameters
+// regardless of the number of keys:
+//
+//	key_id = ANY($1) AND {column}_idx = ANY($2)
+//
+// The key IDs are passed as a []string and the blind indexes (one per key, in
+// the same order) as a [][]byte, which pgx binds natively as TEXT[] and
+// BYTEA[]. With lib/pq, wrap each with pq.Array. With WithKeyIDPredicate(false),
+// only the index array is passed. The ANY operator is PostgreSQL-specific, so
+// this panics if the Cipher uses WithPlaceholderStyle(PlaceholderQuestion).
+//
+// Unlike SearchCondition, an index is not paired with its own key_id, so a row
+// under one key whose index equals another key's index would also match.
+// With full-length indexes this is negligible; with short indexes
+// (WithBlindIndexBytes) prefer SearchCondition.
+func (c *Cipher) SearchConditionPgx(column string, plaintext []byte, paramOffset int) *SearchCondition {
+	c.validateSearchParams(column, paramOffset)
+	if c.config.placeholderStyle == PlaceholderQuestion {
+		panic("encryptedcol: SearchConditionPgx requires PlaceholderDollar (ANY arrays are PostgreSQL-only)")
+	}
+
+	if plaintext == nil {
+		return &SearchCondition{
+			SQL:  "FALSE", // NULL values can't match
+			Args: nil,
+		}
+	}
+
+	r := c.mustAcquire()
+	defer r.release()
+
+	// All key versions fit in one pair of parameters
+	c.validateParamLimit(paramOffset, 1)
+
+	ids := sortedMapKeys(r.keys)
+	indexes := make([][]byte, len(ids))
+	for i, keyID := range ids {
+		indexes[i] = c.computeHMAC(r, keyID, plaintext)
+	}
+
+	if c.config.omitKeyIDPredicate {
+		return &SearchCondition{
+			SQL:  fmt.Sprintf("%s_idx = ANY(%s)", column, c.placeholder(paramOffset)),
+			Args: []interface{}{indexes},
+		}
+	}
+	return &SearchCondition{
+		SQL:  fmt.Sprintf("key_id = ANY(%s) AND %s_idx = ANY(%s)", c.placeholder(paramOffset), column, c.placeholder(paramOffset+1)),
+		Args: []interface{}{ids, indexes},
+	}
+}
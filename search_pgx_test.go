@@ -0,0 +1,65 @@
+package encryptedcol
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchConditionPgx(t *testing.T) {
+	cipher, _ := New(
+		WithKey("v1", testKey("v1")),
+		WithKey("v2", testKey("v2")),
+		WithKey("v3", testKey("v3")),
+	)
+
+	cond := cipher.SearchConditionPgx("email", []byte("alice@example.com"), 3)
+	require.Equal(t, "key_id = ANY($3) AND email_idx = ANY($4)", cond.SQL)
+	require.Len(t, cond.Args, 2)
+	require.Equal(t, []string{"v1", "v2", "v3"}, cond.Args[0])
+
+	indexes := cond.Args[1].([][]byte)
+	require.Len(t, indexes, 3)
+	for i, keyID := range []string{"v1", "v2", "v3"} {
+		idx, _ := cipher.BlindIndexWithKey(keyID, []byte("alice@example.com"))
+		require.Equal(t, idx, indexes[i])
+	}
+
+	require.Equal(t, "FALSE", cipher.SearchConditionPgx("email", nil, 1).SQL)
+}
+
+func TestSearchConditionPgx_KeyIDPredicateDisabled(t *testing.T) {
+	cipher, _ := New(
+		WithKey("v1", testKey("v1")),
+		WithKey("v2", testKey("v2")),
+		WithKeyIDPredicate(false),
+	)
+
+	cond := cipher.SearchConditionPgx("email", []byte("alice@example.com"), 1)
+	require.Equal(t, "email_idx = ANY($1)", cond.SQL)
+	require.Len(t, cond.Args, 1)
+	require.Len(t, cond.Args[0], 2)
+}
+
+func TestSearchConditionPgx_ParamLimit(t *testing.T) {
+	cipher, _ := New(
+		WithKey("v1", testKey("v1")),
+		WithKey("v2", testKey("v2")),
+		WithKey("v3", testKey("v3")),
+	)
+
+	// Six parameters overflow here, but two still fit
+	require.Panics(t, func() { cipher.SearchCondition("email", []byte("a"), maxParamNumber-1) })
+	require.NotPanics(t, func() { cipher.SearchConditionPgx("email", []byte("a"), maxParamNumber-1) })
+	require.Panics(t, func() { cipher.SearchConditionPgx("email", []byte("a"), maxParamNumber) })
+}
+
+func TestSearchConditionPgx_PlaceholderQuestion(t *testing.T) {
+	cipher, _ := New(
+		WithKey("v1", testKey("v1")),
+		WithPlaceholderStyle(PlaceholderQuestion),
+	)
+
+	require.Panics(t, func() { cipher.SearchConditionPgx("email", []byte("a"), 1) })
+	require.Panics(t, func() { cipher.SearchConditionPgx("email", nil, 1) })
+}
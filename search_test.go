@@ -2,6 +2,7 @@ package encryptedcol
 
 import (
 	"bytes"
+	"encoding/hex"
 	"strings"
 	"testing"
 
@@ -253,3 +254,389 @@ func TestSearchCondition_MaxParamOverflow(t *testing.T) {
 		cipher.SearchCondition("email", []byte("test"), maxParamNumber-5)
 	})
 }
+
+func TestSearchConditionIn(t *testing.T) {
+	cipher, _ := New(
+		WithKey("v1", testKey("v1")),
+		WithKey("v2", testKey("v2")),
+	)
+
+	values := [][]byte{[]byte("a@example.com"), nil, []byte("b@example.com")}
+	cond := cipher.SearchConditionIn("email", values, 1)
+
+	require.Equal(t, "(key_id = $1 AND email_idx = ANY($2)) OR (key_id = $3 AND email_idx = ANY($4))", cond.SQL)
+	require.Len(t, cond.Args, 4)
+	require.Equal(t, "v1", cond.Args[0])
+	require.Equal(t, "v2", cond.Args[2])
+
+	v1Indexes := cond.Args[1].([][]byte)
+	require.Len(t, v1Indexes, 2)
+	idxA, _ := cipher.BlindIndexWithKey("v1", []byte("a@example.com"))
+	idxB, _ := cipher.BlindIndexWithKey("v1", []byte("b@example.com"))
+	require.Equal(t, [][]byte{idxA, idxB}, v1Indexes)
+
+	v2Indexes := cond.Args[3].([][]byte)
+	idxA2, _ := cipher.BlindIndexWithKey("v2", []byte("a@example.com"))
+	require.Equal(t, idxA2, v2Indexes[0])
+}
+
+func TestSearchConditionIn_Empty(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	tests := []struct {
+		name   string
+		values [][]byte
+	}{
+		{"nil slice", nil},
+		{"empty slice", [][]byte{}},
+		{"all nil", [][]byte{nil, nil}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cond := cipher.SearchConditionIn("email", tt.values, 1)
+			require.Equal(t, "FALSE", cond.SQL)
+			require.Nil(t, cond.Args)
+		})
+	}
+}
+
+func TestSearchConditionIn_InvalidColumn(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	require.Panics(t, func() {
+		cipher.SearchConditionIn("bad-column", [][]byte{[]byte("x")}, 1)
+	})
+}
+
+func TestSearchConditionNotEqual(t *testing.T) {
+	cipher, _ := New(
+		WithKey("v1", testKey("v1")),
+		WithKey("v2", testKey("v2")),
+	)
+
+	cond := cipher.SearchConditionNotEqual("email", []byte("a@example.com"), 2)
+	eq := cipher.SearchCondition("email", []byte("a@example.com"), 2)
+
+	require.Equal(t, "NOT ((key_id = $2 AND email_idx = $3) OR (key_id = $4 AND email_idx = $5))", cond.SQL)
+	require.Equal(t, eq.Args, cond.Args)
+}
+
+func TestSearchConditionNotEqual_Null(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	cond := cipher.SearchConditionNotEqual("email", nil, 1)
+	require.Equal(t, "TRUE", cond.SQL)
+	require.Nil(t, cond.Args)
+
+	require.Panics(t, func() {
+		cipher.SearchConditionNotEqual("bad-column", nil, 1)
+	})
+}
+
+func TestSearchCondition_QuestionPlaceholders(t *testing.T) {
+	cipher, _ := New(
+		WithKey("v1", testKey("v1")),
+		WithKey("v2", testKey("v2")),
+		WithPlaceholderStyle(PlaceholderQuestion),
+	)
+
+	cond := cipher.SearchCondition("email", []byte("a@example.com"), 1)
+	require.Equal(t, "(key_id = ? AND email_idx = ?) OR (key_id = ? AND email_idx = ?)", cond.SQL)
+	require.Len(t, cond.Args, 4)
+
+	// paramOffset numbering and the PostgreSQL limit do not apply
+	cond = cipher.SearchCondition("email", []byte("a@example.com"), maxParamNumber+10)
+	require.Equal(t, "(key_id = ? AND email_idx = ?) OR (key_id = ? AND email_idx = ?)", cond.SQL)
+
+	cond = cipher.SearchConditionNotEqual("email", []byte("a@example.com"), 0)
+	require.Equal(t, "NOT ((key_id = ? AND email_idx = ?) OR (key_id = ? AND email_idx = ?))", cond.SQL)
+}
+
+func TestSearchConditionIn_QuestionPlaceholders(t *testing.T) {
+	cipher, _ := New(
+		WithKey("v1", testKey("v1")),
+		WithKey("v2", testKey("v2")),
+		WithPlaceholderStyle(PlaceholderQuestion),
+	)
+
+	cond := cipher.SearchConditionIn("email", [][]byte{[]byte("a"), []byte("b")}, 1)
+	require.Equal(t, "(key_id = ? AND email_idx IN (?, ?)) OR (key_id = ? AND email_idx IN (?, ?))", cond.SQL)
+	require.Len(t, cond.Args, 6)
+	require.Equal(t, "v1", cond.Args[0])
+	require.Equal(t, "v2", cond.Args[3])
+
+	idx, _ := cipher.BlindIndexWithKey("v1", []byte("b"))
+	require.Equal(t, idx, cond.Args[2])
+}
+
+func TestWithPlaceholderStyle_Invalid(t *testing.T) {
+	_, err := New(WithKey("v1", testKey("v1")), WithPlaceholderStyle(PlaceholderStyle(99)))
+	require.ErrorIs(t, err, ErrUnsupportedPlaceholderStyle)
+}
+
+func TestSearchConditionForColumn(t *testing.T) {
+	cipher, _ := New(
+		WithKey("v1", testKey("v1")),
+		WithKey("v2", testKey("v2")),
+	)
+
+	cond := cipher.SearchConditionForColumn("email", []byte("alice@example.com"), 1)
+	require.Equal(t, "(key_id = $1 AND email_idx = $2) OR (key_id = $3 AND email_idx = $4)", cond.SQL)
+
+	sealed := cipher.SealStringIndexedForColumn("email", "alice@example.com")
+	require.Equal(t, sealed.BlindIndex, cond.Args[1])
+
+	other := cipher.SearchCondition("email", []byte("alice@example.com"), 1)
+	require.NotEqual(t, other.Args[1], cond.Args[1])
+
+	require.Equal(t, "FALSE", cipher.SearchConditionForColumn("email", nil, 1).SQL)
+}
+
+func TestWithKeyIDPredicate_Disabled(t *testing.T) {
+	cipher, _ := New(
+		WithKey("v1", testKey("v1")),
+		WithKey("v2", testKey("v2")),
+		WithKeyIDPredicate(false),
+	)
+
+	cond := cipher.SearchCondition("email", []byte("alice@example.com"), 3)
+	require.Equal(t, "email_idx = $3 OR email_idx = $4", cond.SQL)
+	require.Len(t, cond.Args, 2)
+
+	// One distinct index per key, so rows under either key still match
+	idx1, _ := cipher.BlindIndexWithKey("v1", []byte("alice@example.com"))
+	idx2, _ := cipher.BlindIndexWithKey("v2", []byte("alice@example.com"))
+	require.Equal(t, []interface{}{idx1, idx2}, cond.Args)
+	require.NotEqual(t, idx1, idx2)
+
+	cond = cipher.SearchConditionNotEqual("email", []byte("alice@example.com"), 1)
+	require.Equal(t, "NOT (email_idx = $1 OR email_idx = $2)", cond.SQL)
+
+	cond = cipher.SearchConditionIn("email", [][]byte{[]byte("a"), []byte("b")}, 1)
+	require.Equal(t, "email_idx = ANY($1) OR email_idx = ANY($2)", cond.SQL)
+	require.Len(t, cond.Args, 2)
+
+	cond = cipher.SearchConditionTokens("email", []byte("alice"), 1, TokenizePrefix)
+	require.Equal(t, "email_idx_tokens @> $1 OR email_idx_tokens @> $2", cond.SQL)
+
+	require.Equal(t, "FALSE", cipher.SearchCondition("email", nil, 1).SQL)
+}
+
+func TestWithKeyIDPredicate_QuestionPlaceholders(t *testing.T) {
+	cipher, _ := New(
+		WithKey("v1", testKey("v1")),
+		WithKey("v2", testKey("v2")),
+		WithKeyIDPredicate(false),
+		WithPlaceholderStyle(PlaceholderQuestion),
+	)
+
+	cond := cipher.SearchCondition("email", []byte("a@example.com"), 1)
+	require.Equal(t, "email_idx = ? OR email_idx = ?", cond.SQL)
+
+	cond = cipher.SearchConditionIn("email", [][]byte{[]byte("a"), []byte("b")}, 1)
+	require.Equal(t, "email_idx IN (?, ?) OR email_idx IN (?, ?)", cond.SQL)
+	require.Len(t, cond.Args, 4)
+}
+
+func TestWithKeyIDPredicate_Default(t *testing.T) {
+	explicit, _ := New(WithKey("v1", testKey("v1")), WithKeyIDPredicate(true))
+	def, _ := New(WithKey("v1", testKey("v1")))
+
+	cond := explicit.SearchCondition("email", []byte("a"), 1)
+	require.Equal(t, "(key_id = $1 AND email_idx = $2)", cond.SQL)
+	require.Equal(t, def.SearchCondition("email", []byte("a"), 1).SQL, cond.SQL)
+}
+
+func TestWithKeyIDPredicate_MaxParamOverflow(t *testing.T) {
+	cipher, _ := New(
+		WithKey("v1", testKey("v1")),
+		WithKey("v2", testKey("v2")),
+		WithKey("v3", testKey("v3")),
+		WithKeyIDPredicate(false),
+	)
+
+	// One parameter per key: 65533 + 2 = 65535 (exactly at limit)
+	require.NotPanics(t, func() {
+		cipher.SearchCondition("email", []byte("test"), maxParamNumber-2)
+	})
+	require.Panics(t, func() {
+		cipher.SearchCondition("email", []byte("test"), maxParamNumber-1)
+	})
+}
+
+func TestSearchConditionMulti(t *testing.T) {
+	cipher, _ := New(
+		WithKey("v1", testKey("v1")),
+		WithKey("v2", testKey("v2")),
+	)
+
+	cond := cipher.SearchConditionMulti("email", " Alice@Example.com", 1, NormalizeEmail, NormalizeNone)
+	require.Equal(t, "(key_id = $1 AND (email_idx = $2 OR email_idx = $3)) OR (key_id = $4 AND (email_idx = $5 OR email_idx = $6))", cond.SQL)
+	require.Len(t, cond.Args, 6)
+
+	// Both populations match under every key
+	for i, keyID := range []string{"v1", "v2"} {
+		normalized, _ := cipher.BlindIndexWithKey(keyID, []byte("alice@example.com"))
+		raw, _ := cipher.BlindIndexWithKey(keyID, []byte(" Alice@Example.com"))
+		require.Equal(t, keyID, cond.Args[i*3])
+		require.Equal(t, normalized, cond.Args[i*3+1])
+		require.Equal(t, raw, cond.Args[i*3+2])
+	}
+}
+
+func TestSearchConditionMulti_Dedup(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	// Normalizers that agree collapse to a plain SearchCondition
+	cond := cipher.SearchConditionMulti("email", "alice@example.com", 1, NormalizeEmail, NormalizeNone)
+	require.Equal(t, cipher.SearchConditionString("email", "alice@example.com", 1), cond)
+
+	// No normalizers: raw plaintext
+	cond = cipher.SearchConditionMulti("email", "Alice", 1)
+	require.Equal(t, cipher.SearchConditionString("email", "Alice", 1), cond)
+}
+
+func TestSearchConditionMulti_KeyIDPredicateDisabled(t *testing.T) {
+	cipher, _ := New(
+		WithKey("v1", testKey("v1")),
+		WithKey("v2", testKey("v2")),
+		WithKeyIDPredicate(false),
+	)
+
+	cond := cipher.SearchConditionMulti("email", "Alice", 1, NormalizeLower, NormalizeNone)
+	require.Equal(t, "email_idx = $1 OR email_idx = $2 OR email_idx = $3 OR email_idx = $4", cond.SQL)
+	require.Len(t, cond.Args, 4)
+}
+
+func TestSearchConditionMulti_ParamLimit(t *testing.T) {
+	cipher, _ := New(
+		WithKey("v1", testKey("v1")),
+		WithKey("v2", testKey("v2")),
+	)
+
+	// 2 keys * (key_id + 2 indexes) = 6 parameters
+	require.NotPanics(t, func() {
+		cipher.SearchConditionMulti("email", "Alice", maxParamNumber-5, NormalizeLower, NormalizeNone)
+	})
+	require.Panics(t, func() {
+		cipher.SearchConditionMulti("email", "Alice", maxParamNumber-4, NormalizeLower, NormalizeNone)
+	})
+	require.Panics(t, func() {
+		cipher.SearchConditionMulti("bad-column", "Alice", 1, NormalizeLower)
+	})
+}
+
+func TestSearchBuilder(t *testing.T) {
+	cipher, _ := New(
+		WithKey("v1", testKey("v1")),
+		WithKey("v2", testKey("v2")),
+	)
+
+	sql, args := cipher.NewSearchBuilder().
+		Add("email", []byte("Alice@Example.com"), NormalizeEmail).
+		Add("ssn", []byte("123-45-6789"), nil).
+		Build(3)
+
+	email := cipher.SearchConditionNormalized("email", []byte("Alice@Example.com"), 3, NormalizeEmail)
+	ssn := cipher.SearchCondition("ssn", []byte("123-45-6789"), 7)
+	require.Equal(t, "("+email.SQL+") AND ("+ssn.SQL+")", sql)
+	require.Equal(t, append(email.Args, ssn.Args...), args)
+	require.Contains(t, sql, "ssn_idx = $10")
+}
+
+func TestSearchBuilder_Nulls(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	tests := []struct {
+		name      string
+		skipNulls bool
+		wantSQL   string
+		wantArgs  int
+	}{
+		{"false clause", false, "(FALSE) AND ((key_id = $1 AND phone_idx = $2))", 2},
+		{"skip", true, "(key_id = $1 AND phone_idx = $2)", 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := cipher.NewSearchBuilder().
+				Add("email", nil, NormalizeEmail).
+				Add("phone", []byte("555"), nil)
+			if tt.skipNulls {
+				b.SkipNulls()
+			}
+			sql, args := b.Build(1)
+			require.Equal(t, tt.wantSQL, sql)
+			require.Len(t, args, tt.wantArgs)
+		})
+	}
+
+	// Nothing to emit
+	sql, args := cipher.NewSearchBuilder().SkipNulls().Add("email", nil, nil).Build(1)
+	require.Equal(t, "TRUE", sql)
+	require.Nil(t, args)
+}
+
+func TestSearchBuilder_QuestionPlaceholders(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")), WithPlaceholderStyle(PlaceholderQuestion))
+
+	sql, args := cipher.NewSearchBuilder().
+		Add("email", []byte("a@b.c"), nil).
+		Add("phone", []byte("555"), nil).
+		Build(1)
+	require.Equal(t, "((key_id = ? AND email_idx = ?)) AND ((key_id = ? AND phone_idx = ?))", sql)
+	require.Len(t, args, 4)
+}
+
+func TestSearchBuilder_InvalidColumn(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	require.Panics(t, func() {
+		cipher.NewSearchBuilder().Add("email; DROP TABLE users", []byte("a"), nil)
+	})
+}
+
+func TestSearchConditionTagged(t *testing.T) {
+	cipher, _ := New(
+		WithKey("v1", testKey("v1")),
+		WithKey("v2", testKey("v2")),
+	)
+
+	cond := cipher.SearchConditionTagged("email", []byte("alice@example.com"), 3)
+	require.Equal(t, "email_idx IN ($3, $4)", cond.SQL)
+	require.Len(t, cond.Args, 2)
+
+	// Matches the tagged index written under either key
+	v1Tagged := cipher.BlindIndexTagged([]byte("alice@example.com"))
+	require.Equal(t, v1Tagged, cond.Args[0])
+	keyID, _, err := ParseTaggedIndex(cond.Args[1].([]byte))
+	require.NoError(t, err)
+	require.Equal(t, "v2", keyID)
+
+	require.Equal(t, "FALSE", cipher.SearchConditionTagged("email", nil, 1).SQL)
+	require.Panics(t, func() { cipher.SearchConditionTagged("email; DROP", []byte("x"), 1) })
+
+	mysql, _ := New(WithKey("v1", testKey("v1")), WithKey("v2", testKey("v2")), WithPlaceholderStyle(PlaceholderQuestion))
+	require.Equal(t, "email_idx IN (?, ?)", mysql.SearchConditionTagged("email", []byte("x"), 1).SQL)
+}
+
+func TestSearchConditionHex(t *testing.T) {
+	cipher, _ := New(
+		WithKey("v1", testKey("v1")),
+		WithKey("v2", testKey("v2")),
+		WithDefaultKeyID("v2"),
+	)
+
+	cond := cipher.SearchConditionHex("email", []byte("alice@example.com"), 1)
+	require.Equal(t, cipher.SearchCondition("email", []byte("alice@example.com"), 1).SQL, cond.SQL)
+	v1Index, _ := cipher.BlindIndexWithKey("v1", []byte("alice@example.com"))
+	require.Equal(t, []interface{}{"v1", hex.EncodeToString(v1Index), "v2", cipher.BlindIndexHex([]byte("alice@example.com"))}, cond.Args)
+
+	// Without the key_id predicate, every argument is a hex index
+	noKeyID, _ := New(WithKey("v1", testKey("v1")), WithKeyIDPredicate(false))
+	cond = noKeyID.SearchConditionHex("email", []byte("alice@example.com"), 1)
+	require.Equal(t, []interface{}{noKeyID.BlindIndexHex([]byte("alice@example.com"))}, cond.Args)
+
+	require.Equal(t, "FALSE", cipher.SearchConditionHex("email", nil, 1).SQL)
+}
@@ -0,0 +1,52 @@
+package encryptedcol
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelfTest(t *testing.T) {
+	for _, aead := range []AEAD{AEADSecretbox, AEADAESGCM, AEADGCMSIV, AEADChaCha20Poly1305} {
+		cipher, err := New(
+			WithKey("v1", testKey("v1")),
+			WithKey("v2", testKey("v2")),
+			WithAEAD(aead),
+			WithBlindIndexPepper([]byte("0123456789abcdef")),
+		)
+		require.NoError(t, err)
+		require.NoError(t, cipher.SelfTest())
+	}
+
+	// Read-only Ciphers can self-test; nothing is stored
+	reader, _ := New(WithKey("v1", testKey("v1")), WithReadOnly())
+	require.NoError(t, reader.SelfTest())
+}
+
+func TestSelfTest_NotObserved(t *testing.T) {
+	obs := &recordingObserver{}
+	cipher, _ := New(WithKey("v1", testKey("v1")), WithObserver(obs))
+
+	require.NoError(t, cipher.SelfTest())
+	require.Empty(t, obs.seals)
+	require.Empty(t, obs.opens)
+}
+
+func TestSelfTest_Failures(t *testing.T) {
+	// A zeroed blind index key
+	cipher, _ := New(WithKey("v1", testKey("v1")), WithKey("v2", testKey("v2")))
+	zeroKey(&cipher.ring.Load().keys["v2"].hmac)
+	err := cipher.SelfTest()
+	require.ErrorIs(t, err, ErrSelfTestFailed)
+	require.Contains(t, err.Error(), `key_id="v2"`)
+
+	// Missing AEAD state panics inside the probe and is reported instead
+	cipher, _ = New(WithKey("v1", testKey("v1")), WithAEAD(AEADAESGCM))
+	cipher.ring.Load().keys["v1"].gcm = nil
+	err = cipher.SelfTest()
+	require.ErrorIs(t, err, ErrSelfTestFailed)
+	require.Contains(t, err.Error(), "panicked")
+
+	cipher.Close()
+	require.ErrorIs(t, cipher.SelfTest(), ErrCipherClosed)
+}
@@ -0,0 +1,159 @@
+package encryptedcol
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+)
+
+// Compile-time interface checks
+var (
+	_ driver.Valuer = (*EncryptedStringValue)(nil)
+	_ sql.Scanner   = (*EncryptedStringValue)(nil)
+	_ driver.Valuer = (*EncryptedInt64Value)(nil)
+	_ sql.Scanner   = (*EncryptedInt64Value)(nil)
+	_ driver.Valuer = (*EncryptedBytesValue)(nil)
+	_ sql.Scanner   = (*EncryptedBytesValue)(nil)
+)
+
+// EncryptedStringValue binds a nullable string field to an encrypted column.
+// It implements driver.Valuer (seals on write) and sql.Scanner (opens on read).
+type EncryptedStringValue struct {
+	c   *Cipher
+	dst **string
+}
+
+// EncryptedString wraps a nullable string field for use with database/sql.
+// A nil *string is written as NULL, and a NULL column scans into a nil *string.
+//
+// Example:
+//
+//	db.Exec("INSERT INTO users (email_encrypted) VALUES ($1)", cipher.EncryptedString(&user.Email))
+//	row.Scan(cipher.EncryptedString(&user.Email))
+func (c *Cipher) EncryptedString(dst **string) *EncryptedStringValue {
+	return &EncryptedStringValue{c: c, dst: dst}
+}
+
+// Value implements driver.Valuer.
+func (v *EncryptedStringValue) Value() (driver.Value, error) {
+	if v.dst == nil || *v.dst == nil {
+		return nil, nil
+	}
+	if err := v.c.checkWritable(); err != nil {
+		return nil, err
+	}
+	ciphertext := v.c.SealString(**v.dst)
+	if ciphertext == nil {
+		return nil, nil // WithEmptyStringAsNull
+	}
+	return ciphertext, nil
+}
+
+// Scan implements sql.Scanner.
+func (v *EncryptedStringValue) Scan(src any) error {
+	ciphertext, err := scanCiphertext(src)
+	if err != nil {
+		return err
+	}
+	s, err := v.c.OpenStringPtr(ciphertext)
+	if err != nil {
+		return err
+	}
+	*v.dst = s
+	return nil
+}
+
+// EncryptedInt64Value binds a nullable int64 field to an encrypted column.
+// It implements driver.Valuer (seals on write) and sql.Scanner (opens on read).
+type EncryptedInt64Value struct {
+	c   *Cipher
+	dst **int64
+}
+
+// EncryptedInt64 wraps a nullable int64 field for use with database/sql.
+// A nil *int64 is written as NULL, and a NULL column scans into a nil *int64.
+func (c *Cipher) EncryptedInt64(dst **int64) *EncryptedInt64Value {
+	return &EncryptedInt64Value{c: c, dst: dst}
+}
+
+// Value implements driver.Valuer.
+func (v *EncryptedInt64Value) Value() (driver.Value, error) {
+	if v.dst == nil || *v.dst == nil {
+		return nil, nil
+	}
+	if err := v.c.checkWritable(); err != nil {
+		return nil, err
+	}
+	return v.c.SealInt64(**v.dst), nil
+}
+
+// Scan implements sql.Scanner.
+func (v *EncryptedInt64Value) Scan(src any) error {
+	ciphertext, err := scanCiphertext(src)
+	if err != nil {
+		return err
+	}
+	if ciphertext == nil {
+		*v.dst = nil
+		return nil
+	}
+	n, err := v.c.OpenInt64(ciphertext)
+	if err != nil {
+		return err
+	}
+	*v.dst = &n
+	return nil
+}
+
+// EncryptedBytesValue binds a byte slice field to an encrypted column.
+// It implements driver.Valuer (seals on write) and sql.Scanner (opens on read).
+type EncryptedBytesValue struct {
+	c   *Cipher
+	dst *[]byte
+}
+
+// EncryptedBytes wraps a byte slice field for use with database/sql.
+// A nil slice is written as NULL, and a NULL column scans into a nil slice.
+func (c *Cipher) EncryptedBytes(dst *[]byte) *EncryptedBytesValue {
+	return &EncryptedBytesValue{c: c, dst: dst}
+}
+
+// Value implements driver.Valuer.
+func (v *EncryptedBytesValue) Value() (driver.Value, error) {
+	if v.dst == nil || *v.dst == nil {
+		return nil, nil
+	}
+	if err := v.c.checkWritable(); err != nil {
+		return nil, err
+	}
+	return v.c.Seal(*v.dst), nil
+}
+
+// Scan implements sql.Scanner.
+func (v *EncryptedBytesValue) Scan(src any) error {
+	ciphertext, err := scanCiphertext(src)
+	if err != nil {
+		return err
+	}
+	plaintext, err := v.c.Open(ciphertext)
+	if err != nil {
+		return err
+	}
+	*v.dst = plaintext
+	return nil
+}
+
+// scanCiphertext converts a database/sql source value to ciphertext bytes.
+// Returns nil for NULL.
+func scanCiphertext(src any) ([]byte, error) {
+	switch v := src.(type) {
+	case nil:
+		return nil, nil
+	case []byte:
+		return v, nil
+	case string:
+		return []byte(v), nil
+	default:
+		return nil, fmt.Errorf("%w: %T", ErrUnsupportedScanType, src)
+	}
+}
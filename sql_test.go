@@ -0,0 +1,122 @@
+package encryptedcol
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptedString_RoundTrip(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	email := "alice@example.com"
+	src := &email
+	value, err := cipher.EncryptedString(&src).Value()
+	require.NoError(t, err)
+	require.IsType(t, []byte{}, value)
+
+	var dst *string
+	require.NoError(t, cipher.EncryptedString(&dst).Scan(value))
+	require.NotNil(t, dst)
+	require.Equal(t, email, *dst)
+}
+
+func TestEncryptedString_Null(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	var src *string
+	value, err := cipher.EncryptedString(&src).Value()
+	require.NoError(t, err)
+	require.Nil(t, value)
+
+	existing := "stale"
+	dst := &existing
+	require.NoError(t, cipher.EncryptedString(&dst).Scan(nil))
+	require.Nil(t, dst)
+}
+
+func TestEncryptedString_EmptyStringAsNull(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")), WithEmptyStringAsNull())
+
+	empty := ""
+	src := &empty
+	value, err := cipher.EncryptedString(&src).Value()
+	require.NoError(t, err)
+	require.Nil(t, value)
+}
+
+func TestEncryptedString_ScanString(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	var dst *string
+	require.NoError(t, cipher.EncryptedString(&dst).Scan(string(cipher.SealString("hi"))))
+	require.Equal(t, "hi", *dst)
+}
+
+func TestEncryptedInt64_RoundTrip(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	n := int64(-42)
+	src := &n
+	value, err := cipher.EncryptedInt64(&src).Value()
+	require.NoError(t, err)
+
+	var dst *int64
+	require.NoError(t, cipher.EncryptedInt64(&dst).Scan(value))
+	require.NotNil(t, dst)
+	require.Equal(t, n, *dst)
+}
+
+func TestEncryptedInt64_Null(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	var src *int64
+	value, err := cipher.EncryptedInt64(&src).Value()
+	require.NoError(t, err)
+	require.Nil(t, value)
+
+	n := int64(1)
+	dst := &n
+	require.NoError(t, cipher.EncryptedInt64(&dst).Scan(nil))
+	require.Nil(t, dst)
+}
+
+func TestEncryptedBytes_RoundTrip(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	src := []byte{0x00, 0x01, 0xff}
+	value, err := cipher.EncryptedBytes(&src).Value()
+	require.NoError(t, err)
+
+	var dst []byte
+	require.NoError(t, cipher.EncryptedBytes(&dst).Scan(value))
+	require.Equal(t, src, dst)
+}
+
+func TestEncryptedBytes_Null(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	var src []byte
+	value, err := cipher.EncryptedBytes(&src).Value()
+	require.NoError(t, err)
+	require.Nil(t, value)
+
+	dst := []byte("stale")
+	require.NoError(t, cipher.EncryptedBytes(&dst).Scan(nil))
+	require.Nil(t, dst)
+}
+
+func TestEncryptedValue_ScanErrors(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	var s *string
+	err := cipher.EncryptedString(&s).Scan(int64(5))
+	require.ErrorIs(t, err, ErrUnsupportedScanType)
+
+	err = cipher.EncryptedString(&s).Scan([]byte{0x00})
+	require.ErrorIs(t, err, ErrInvalidFormat)
+
+	var n *int64
+	err = cipher.EncryptedInt64(&n).Scan(cipher.SealString("not an int"))
+	require.ErrorIs(t, err, ErrInvalidFormat)
+}
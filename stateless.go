@@ -0,0 +1,96 @@
+package encryptedcol
+
+import (
+	"crypto/sha256"
+	"sync"
+)
+
+// maxStatelessKeys bounds the derivation cache behind SealWith and OpenWith.
+const maxStatelessKeys = 1024
+
+// statelessCipher supplies the default configuration (secretbox, zstd
+// compression) for SealWith and OpenWith. Its own keyring is never used.
+var statelessCipher = &Cipher{config: defaultConfig()}
+
+// statelessKeys caches derived keys by SHA-256 of the master key, so repeated
+// calls with the same key skip HKDF. When full, it is emptied rather than
+// evicting one entry; dropped keys are not zeroed, since another goroutine may
+// still be using them.
+var statelessKeys = struct {
+	mu   sync.Mutex
+	keys map[[sha256.Size]byte]*derivedKeys
+}{keys: make(map[[sha256.Size]byte]*derivedKeys)}
+
+// statelessDerive returns the derived keys for masterKey, from the cache if possible.
+func statelessDerive(masterKey []byte) (*derivedKeys, error) {
+	if len(masterKey) != masterKeySize {
+		return nil, ErrInvalidKeySize
+	}
+	sum := sha256.Sum256(masterKey)
+
+	statelessKeys.mu.Lock()
+	defer statelessKeys.mu.Unlock()
+	if dk, ok := statelessKeys.keys[sum]; ok {
+		return dk, nil
+	}
+	dk, err := deriveKeys(masterKey, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(statelessKeys.keys) >= maxStatelessKeys {
+		clear(statelessKeys.keys)
+	}
+	statelessKeys.keys[sum] = dk
+	return dk, nil
+}
+
+// SealWith encrypts plaintext under masterKey without a Cipher, embedding
+// keyID in the ciphertext exactly as Seal does. It suits per-request or
+// per-tenant keys where building a long-lived Cipher is not worthwhile.
+//
+// Ciphertext uses the default configuration (secretbox, zstd above 1KB) and
+// can be opened by OpenWith or by any Cipher holding masterKey under keyID.
+// Derived keys are cached internally, keyed by a SHA-256 hash of masterKey.
+//
+// Returns nil, nil if plaintext is nil (NULL preservation), ErrInvalidKeySize
+// if masterKey is not 32 bytes, or ErrInvalidKeyID if keyID is empty or
+// longer than 255 bytes.
+func SealWith(masterKey []byte, keyID string, plaintext []byte) ([]byte, error) {
+	c := statelessCipher
+	if err := c.config.validateKeyID(keyID); err != nil {
+		return nil, err
+	}
+	dk, err := statelessDerive(masterKey)
+	if err != nil {
+		return nil, err
+	}
+	if plaintext == nil {
+		return nil, nil // NULL preservation
+	}
+	r := &keyring{keys: map[string]*derivedKeys{keyID: dk}, defaultID: keyID}
+	return c.sealWithKeyID(r, nil, keyID, plaintext, nil), nil
+}
+
+// OpenWith decrypts ciphertext from SealWith, or from Seal on any Cipher, using
+// masterKey for whatever key ID the ciphertext names. The AEAD is read from
+// the ciphertext; the decompression limit is the default 64MB. A wrong key
+// fails with ErrDecryptionFailed.
+//
+// Returns nil, nil if ciphertext is nil (NULL preservation) and
+// ErrInvalidKeySize if masterKey is not 32 bytes.
+func OpenWith(masterKey []byte, ciphertext []byte) ([]byte, error) {
+	c := statelessCipher
+	dk, err := statelessDerive(masterKey)
+	if err != nil {
+		return nil, err
+	}
+	if ciphertext == nil {
+		return nil, nil // NULL preservation
+	}
+	_, keyID, _, _, err := parseFormatBytes(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	r := &keyring{keys: map[string]*derivedKeys{string(keyID): dk}, defaultID: string(keyID)}
+	return c.openWithRing(r, nil, ciphertext, nil)
+}
@@ -0,0 +1,92 @@
+package encryptedcol
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSealWith_OpenWith_RoundTrip(t *testing.T) {
+	key := testKey("tenant-42")
+
+	ct, err := SealWith(key, "tenant-42", []byte("hello"))
+	require.NoError(t, err)
+
+	_, keyID, _, _, err := parseFormatBytes(ct)
+	require.NoError(t, err)
+	require.Equal(t, "tenant-42", string(keyID))
+
+	pt, err := OpenWith(key, ct)
+	require.NoError(t, err)
+	require.Equal(t, []byte("hello"), pt)
+}
+
+func TestSealWith_InteropWithCipher(t *testing.T) {
+	cipher, err := New(WithKey("v1", testKey("v1")), WithAEAD(AEADAESGCM))
+	require.NoError(t, err)
+
+	// Cipher -> OpenWith (AEAD is read from the ciphertext)
+	pt, err := OpenWith(testKey("v1"), cipher.Seal([]byte("from cipher")))
+	require.NoError(t, err)
+	require.Equal(t, []byte("from cipher"), pt)
+
+	// SealWith -> Cipher
+	ct, err := SealWith(testKey("v1"), "v1", []byte("from SealWith"))
+	require.NoError(t, err)
+	pt, err = cipher.Open(ct)
+	require.NoError(t, err)
+	require.Equal(t, []byte("from SealWith"), pt)
+}
+
+func TestOpenWith_WrongKey(t *testing.T) {
+	ct, err := SealWith(testKey("a"), "k", []byte("secret"))
+	require.NoError(t, err)
+
+	_, err = OpenWith(testKey("b"), ct)
+	require.ErrorIs(t, err, ErrDecryptionFailed)
+}
+
+func TestSealWith_OpenWith_Errors(t *testing.T) {
+	_, err := SealWith(make([]byte, 16), "v1", []byte("x"))
+	require.ErrorIs(t, err, ErrInvalidKeySize)
+
+	_, err = SealWith(testKey("v1"), "", []byte("x"))
+	require.ErrorIs(t, err, ErrInvalidKeyID)
+
+	_, err = OpenWith(make([]byte, 16), []byte("x"))
+	require.ErrorIs(t, err, ErrInvalidKeySize)
+
+	_, err = OpenWith(testKey("v1"), []byte{0x00})
+	require.ErrorIs(t, err, ErrInvalidFormat)
+}
+
+func TestSealWith_OpenWith_Null(t *testing.T) {
+	ct, err := SealWith(testKey("v1"), "v1", nil)
+	require.NoError(t, err)
+	require.Nil(t, ct)
+
+	pt, err := OpenWith(testKey("v1"), nil)
+	require.NoError(t, err)
+	require.Nil(t, pt)
+}
+
+func TestStatelessDerive_Cache(t *testing.T) {
+	dk1, err := statelessDerive(testKey("cache"))
+	require.NoError(t, err)
+	dk2, err := statelessDerive(testKey("cache"))
+	require.NoError(t, err)
+	require.Same(t, dk1, dk2)
+
+	// Filling the cache empties it instead of growing without bound
+	for i := 0; i < maxStatelessKeys; i++ {
+		key := testKey("fill")
+		key[31] = byte(i)
+		key[30] = byte(i >> 8)
+		_, err := statelessDerive(key)
+		require.NoError(t, err)
+	}
+	statelessKeys.mu.Lock()
+	n := len(statelessKeys.keys)
+	statelessKeys.mu.Unlock()
+	require.LessOrEqual(t, n, maxStatelessKeys)
+}
@@ -0,0 +1,210 @@
+package encryptedcol
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// Stream format:
+// [flag:1][keyIDLen:1][keyID:n][baseNonce:N][frame]...[finalFrame]
+//
+// The header matches the outer ciphertext header (see format.go) and is
+// written once. Each frame is aead(chunk) with:
+//   - nonce: baseNonce with the big-endian frame counter XORed into its last 8 bytes
+//   - AAD: header || finalMarker (0x00 for intermediate frames, 0x01 for the last)
+//
+// Intermediate frames always hold exactly streamFrameSize plaintext bytes.
+// The final frame holds fewer (possibly zero), so a reader can tell it apart
+// by length alone. The counter prevents reordering and the final marker
+// prevents truncation at a frame boundary. Frames are never compressed.
+
+// streamFrameSize is the plaintext size of each intermediate frame.
+const streamFrameSize = 64 * 1024
+
+// Final frame markers (last byte of each frame's AAD)
+const (
+	streamFrameMore  byte = 0x00
+	streamFrameFinal byte = 0x01
+)
+
+// SealStream encrypts src to dst in 64KB frames using the default key.
+// Memory use is bounded by the frame size regardless of input length, and
+// the 64MB decompression limit does not apply since frames are not compressed.
+//
+// Streams are not interchangeable with Seal output; use OpenStream.
+// The key snapshot is held for the duration of the call, so ReloadKeys and
+// Close wait for in-flight streams to finish.
+func (c *Cipher) SealStream(dst io.Writer, src io.Reader) error {
+	r, err := c.acquireWritable()
+	if err != nil {
+		return err
+	}
+	defer r.release()
+
+	keyID := r.defaultID
+	plaintextLen, ciphertextLen, err := c.sealStream(r, keyID, dst, src)
+	if err == nil && c.config.observer != nil {
+		c.observeSeal(keyID, plaintextLen, ciphertextLen)
+	}
+	return err
+}
+
+// sealStream is the body of SealStream.
+// Returns the total plaintext and stream bytes written.
+func (c *Cipher) sealStream(r *keyring, keyID string, dst io.Writer, src io.Reader) (plaintextLen, ciphertextLen int, err error) {
+	keys := r.keys[keyID]
+	aead := c.config.aead
+	baseNonce := c.newNonces(1)
+
+	header := appendCiphertextHeader(nil, flagFor(aead, flagNoCompression), keyID, baseNonce)
+	if _, err := dst.Write(header); err != nil {
+		return 0, 0, err
+	}
+	ciphertextLen = len(header)
+
+	// AAD is the header plus a trailing final marker
+	aad := append(header[:len(header):len(header)], streamFrameMore)
+	nonce := make([]byte, len(baseNonce))
+	chunk := make([]byte, streamFrameSize)
+	frame := make([]byte, 0, streamFrameSize+aeadOverhead)
+	defer clear(chunk)
+
+	for counter := uint64(0); ; counter++ {
+		n, err := io.ReadFull(src, chunk)
+		final := false
+		switch err {
+		case nil:
+		case io.EOF, io.ErrUnexpectedEOF:
+			final = true
+		default:
+			return 0, 0, err
+		}
+
+		streamNonce(nonce, baseNonce, counter)
+		if final {
+			aad[len(aad)-1] = streamFrameFinal
+		}
+		frame = keys.seal(frame[:0], aead, nonce, chunk[:n], aad)
+		if _, err := dst.Write(frame); err != nil {
+			return 0, 0, err
+		}
+		plaintextLen += n
+		ciphertextLen += len(frame)
+		if final {
+			return plaintextLen, ciphertextLen, nil
+		}
+	}
+}
+
+// OpenStream decrypts a stream produced by SealStream from src to dst,
+// auto-detecting the key from the embedded key_id.
+//
+// Each frame is authenticated before it is written, but a stream is only
+// known to be complete once OpenStream returns nil. On error, dst may already
+// hold a prefix of the plaintext, which the caller must discard.
+// Returns ErrStreamTruncated if src ends before the final frame.
+func (c *Cipher) OpenStream(dst io.Writer, src io.Reader) error {
+	r, err := c.acquire()
+	if err != nil {
+		return err
+	}
+	defer r.release()
+
+	keyID, err := c.openStream(r, dst, src)
+	if obs := c.config.observer; obs != nil {
+		obs.OnOpen(keyID, err)
+	}
+	return err
+}
+
+// openStream is the body of OpenStream.
+// Returns the stream's key ID (empty if the header could not be read).
+func (c *Cipher) openStream(r *keyring, dst io.Writer, src io.Reader) (string, error) {
+	header, keyID, baseNonce, err := readStreamHeader(src)
+	if err != nil {
+		return "", err
+	}
+	keys, ok := r.keys[keyID]
+	if !ok {
+		return keyID, keyNotFoundError(keyID)
+	}
+	aead := aeadFromFlag(header[0])
+
+	aad := append(header[:len(header):len(header)], streamFrameMore)
+	nonce := make([]byte, len(baseNonce))
+	frame := make([]byte, streamFrameSize+aeadOverhead)
+	chunk := make([]byte, 0, streamFrameSize)
+	defer func() { clear(chunk[:cap(chunk)]) }()
+
+	for counter := uint64(0); ; counter++ {
+		n, err := io.ReadFull(src, frame)
+		final := false
+		switch err {
+		case nil:
+		case io.ErrUnexpectedEOF:
+			// Only the final frame is shorter than a full frame
+			final = true
+		case io.EOF:
+			return keyID, ErrStreamTruncated
+		default:
+			return keyID, err
+		}
+		if n < aeadOverhead {
+			return keyID, ErrStreamTruncated
+		}
+
+		streamNonce(nonce, baseNonce, counter)
+		if final {
+			aad[len(aad)-1] = streamFrameFinal
+		}
+		chunk, ok = keys.open(chunk[:0], aead, nonce, frame[:n], aad)
+		if !ok {
+			return keyID, ErrDecryptionFailed
+		}
+		if _, err := dst.Write(chunk); err != nil {
+			return keyID, err
+		}
+		if final {
+			return keyID, nil
+		}
+	}
+}
+
+// readStreamHeader reads and validates [flag:1][keyIDLen:1][keyID:n][baseNonce:N].
+// The returned keyID and baseNonce alias header.
+func readStreamHeader(src io.Reader) (header []byte, keyID string, baseNonce []byte, err error) {
+	var prefix [2]byte
+	if _, err = io.ReadFull(src, prefix[:]); err != nil {
+		return nil, "", nil, streamReadError(err)
+	}
+
+	flag, keyIDLen := prefix[0], int(prefix[1])
+	aead := aeadFromFlag(flag)
+	if !aead.valid() || hasAAD(flag) || compressionFromFlag(flag) != flagNoCompression || keyIDLen == 0 {
+		return nil, "", nil, ErrInvalidFormat
+	}
+
+	header = make([]byte, 2+keyIDLen+aead.nonceSize())
+	copy(header, prefix[:])
+	if _, err = io.ReadFull(src, header[2:]); err != nil {
+		return nil, "", nil, streamReadError(err)
+	}
+	return header, string(header[2 : 2+keyIDLen]), header[2+keyIDLen:], nil
+}
+
+// streamReadError maps a short header read to ErrStreamTruncated.
+func streamReadError(err error) error {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return ErrStreamTruncated
+	}
+	return err
+}
+
+// streamNonce writes the nonce for frame counter into dst:
+// baseNonce with the big-endian counter XORed into its last 8 bytes.
+func streamNonce(dst, baseNonce []byte, counter uint64) {
+	copy(dst, baseNonce)
+	tail := dst[len(dst)-8:]
+	binary.BigEndian.PutUint64(tail, binary.BigEndian.Uint64(tail)^counter)
+}
@@ -0,0 +1,211 @@
+package encryptedcol
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func sealStreamBytes(t *testing.T, cipher *Cipher, plaintext []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, cipher.SealStream(&buf, bytes.NewReader(plaintext)))
+	return buf.Bytes()
+}
+
+func TestSealStream_RoundTrip(t *testing.T) {
+	sizes := []int{0, 1, streamFrameSize - 1, streamFrameSize, streamFrameSize + 1, 3*streamFrameSize + 5}
+
+	for _, aead := range []AEAD{AEADSecretbox, AEADAESGCM, AEADGCMSIV, AEADChaCha20Poly1305} {
+		cipher, err := New(WithKey("v1", testKey("v1")), WithAEAD(aead))
+		require.NoError(t, err)
+
+		for _, size := range sizes {
+			plaintext := make([]byte, size)
+			_, _ = rand.Read(plaintext)
+
+			stream := sealStreamBytes(t, cipher, plaintext)
+
+			var out bytes.Buffer
+			require.NoError(t, cipher.OpenStream(&out, bytes.NewReader(stream)), "aead=%d size=%d", aead, size)
+			require.True(t, bytes.Equal(plaintext, out.Bytes()), "aead=%d size=%d", aead, size)
+		}
+	}
+}
+
+func TestSealStream_FrameLayout(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	// Exact multiple of the frame size ends with an empty final frame
+	stream := sealStreamBytes(t, cipher, make([]byte, 2*streamFrameSize))
+	header := headerSize("v1", secretboxNonceSize)
+	require.Equal(t, header+2*(streamFrameSize+aeadOverhead)+aeadOverhead, len(stream))
+
+	keyID, err := cipher.ExtractKeyID(stream[:header+aeadOverhead])
+	require.NoError(t, err)
+	require.Equal(t, "v1", keyID)
+}
+
+func TestSealStream_RandomizedNonce(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	s1 := sealStreamBytes(t, cipher, []byte("same"))
+	s2 := sealStreamBytes(t, cipher, []byte("same"))
+	require.NotEqual(t, s1, s2)
+}
+
+func TestOpenStream_Tampering(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	plaintext := make([]byte, 3*streamFrameSize+100)
+	stream := sealStreamBytes(t, cipher, plaintext)
+	header := headerSize("v1", secretboxNonceSize)
+	frameLen := streamFrameSize + aeadOverhead
+
+	tests := []struct {
+		name   string
+		stream []byte
+		err    error
+	}{
+		{
+			name:   "truncated at frame boundary",
+			stream: stream[:header+2*frameLen],
+			err:    ErrStreamTruncated,
+		},
+		{
+			name:   "truncated mid frame",
+			stream: stream[:header+frameLen+100],
+			err:    ErrDecryptionFailed,
+		},
+		{
+			name:   "header only",
+			stream: stream[:header],
+			err:    ErrStreamTruncated,
+		},
+		{
+			name:   "truncated header",
+			stream: stream[:header-1],
+			err:    ErrStreamTruncated,
+		},
+		{
+			name: "frames reordered",
+			stream: func() []byte {
+				s := bytes.Clone(stream)
+				f0 := bytes.Clone(s[header : header+frameLen])
+				copy(s[header:], s[header+frameLen:header+2*frameLen])
+				copy(s[header+frameLen:], f0)
+				return s
+			}(),
+			err: ErrDecryptionFailed,
+		},
+		{
+			name: "frame dropped",
+			stream: append(bytes.Clone(stream[:header+frameLen]),
+				stream[header+2*frameLen:]...),
+			err: ErrDecryptionFailed,
+		},
+		{
+			name: "payload bit flipped",
+			stream: func() []byte {
+				s := bytes.Clone(stream)
+				s[len(s)-1] ^= 0x01
+				return s
+			}(),
+			err: ErrDecryptionFailed,
+		},
+		{
+			name: "nonce bit flipped",
+			stream: func() []byte {
+				s := bytes.Clone(stream)
+				s[header-1] ^= 0x01
+				return s
+			}(),
+			err: ErrDecryptionFailed,
+		},
+		{
+			name:   "trailing data",
+			stream: append(bytes.Clone(stream), 0x00),
+			err:    ErrDecryptionFailed,
+		},
+		{
+			name: "compression flag set",
+			stream: func() []byte {
+				s := bytes.Clone(stream)
+				s[0] |= flagZstd
+				return s
+			}(),
+			err: ErrInvalidFormat,
+		},
+		{
+			name:   "empty",
+			stream: []byte{},
+			err:    ErrStreamTruncated,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := cipher.OpenStream(&bytes.Buffer{}, bytes.NewReader(tt.stream))
+			require.ErrorIs(t, err, tt.err)
+		})
+	}
+}
+
+func TestOpenStream_KeyRotation(t *testing.T) {
+	oldCipher, _ := New(WithKey("v1", testKey("v1")))
+	stream := sealStreamBytes(t, oldCipher, []byte("legacy attachment"))
+
+	cipher, _ := New(
+		WithKey("v1", testKey("v1")),
+		WithKey("v2", testKey("v2")),
+		WithDefaultKeyID("v2"),
+	)
+	var out bytes.Buffer
+	require.NoError(t, cipher.OpenStream(&out, bytes.NewReader(stream)))
+	require.Equal(t, "legacy attachment", out.String())
+
+	other, _ := New(WithKey("v2", testKey("v2")))
+	err := other.OpenStream(&bytes.Buffer{}, bytes.NewReader(stream))
+	require.ErrorIs(t, err, ErrKeyNotFound)
+}
+
+func TestOpenStream_NotInterchangeableWithSeal(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	_, err := cipher.Open(sealStreamBytes(t, cipher, []byte("hello")))
+	require.Error(t, err)
+
+	err = cipher.OpenStream(&bytes.Buffer{}, bytes.NewReader(cipher.Seal([]byte("hello"))))
+	require.Error(t, err)
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("write failed") }
+
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) { return 0, errors.New("read failed") }
+
+func TestSealStream_IOErrors(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	require.EqualError(t, cipher.SealStream(failingWriter{}, bytes.NewReader([]byte("x"))), "write failed")
+	require.EqualError(t, cipher.SealStream(&bytes.Buffer{}, failingReader{}), "read failed")
+	require.EqualError(t, cipher.OpenStream(&bytes.Buffer{}, failingReader{}), "read failed")
+
+	stream := sealStreamBytes(t, cipher, []byte("x"))
+	require.EqualError(t, cipher.OpenStream(failingWriter{}, bytes.NewReader(stream)), "write failed")
+}
+
+func TestSealStream_Closed(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+	stream := sealStreamBytes(t, cipher, []byte("x"))
+	cipher.Close()
+
+	require.ErrorIs(t, cipher.SealStream(&bytes.Buffer{}, bytes.NewReader([]byte("x"))), ErrCipherClosed)
+	require.ErrorIs(t, cipher.OpenStream(&bytes.Buffer{}, bytes.NewReader(stream)), ErrCipherClosed)
+}
@@ -0,0 +1,188 @@
+package encryptedcol
+
+import (
+	"encoding/binary"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// structTagName is the struct tag read by SealStruct.
+const structTagName = "encryptedcol"
+
+var (
+	timeType = reflect.TypeOf(time.Time{})
+	uuidType = reflect.TypeOf([uuidSize]byte{})
+)
+
+// SealStruct encrypts the fields of a struct tagged `encryptedcol:"seal"` or
+// `encryptedcol:"seal,index"` and returns the struct as a row keyed by column name:
+//
+//	type User struct {
+//		ID    int64
+//		Email string `db:"email" encryptedcol:"seal,index"`
+//		Notes string `db:"notes" encryptedcol:"seal"`
+//	}
+//
+//	row, err := cipher.SealStruct(user)
+//	// row["ID"], row["email_encrypted"], row["email_idx"], row["notes_encrypted"], row["key_id"]
+//
+// The column name is the field's `db` tag, or the field name if there is none;
+// fields tagged `db:"-"` are skipped. A sealed field becomes {column}_encrypted,
+// and an indexed one also gets {column}_idx, matching the layout from ColumnDDL.
+// If any field is sealed, row["key_id"] holds the key version used; every field
+// is sealed under the same key snapshot. Untagged fields pass through unchanged.
+// Unexported fields are ignored (reflection cannot read them), so tagging one
+// returns ErrInvalidStructTag.
+//
+// Sealed fields are encoded like the typed helpers, so they decrypt with the
+// matching Open function and index like the matching Seal*Indexed function:
+// string (OpenString; WithEmptyStringAsNull applies), []byte (Open), signed
+// integers (OpenInt64), bool (OpenBool), time.Time (OpenTime), [16]byte
+// (OpenUUID), and anything else as canonical JSON (OpenJSON). A nil pointer,
+// slice, map or interface seals as NULL: nil ciphertext and nil index.
+//
+// Index-tagged fields are indexed without normalization. Normalize the value
+// before calling SealStruct if searches should be normalized.
+func (c *Cipher) SealStruct(v any) (map[string]any, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil, ErrInvalidStruct
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, ErrInvalidStruct
+	}
+
+	r, err := c.acquireWritable()
+	if err != nil {
+		return nil, err
+	}
+	defer r.release()
+
+	rt := rv.Type()
+	row := make(map[string]any, rt.NumField()+1)
+	sealed := false
+
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		tag, tagged := field.Tag.Lookup(structTagName)
+		if !field.IsExported() {
+			if tagged {
+				return nil, fmt.Errorf("%w: unexported field %s", ErrInvalidStructTag, field.Name)
+			}
+			continue
+		}
+
+		column := structColumnName(field)
+		if column == "" {
+			continue
+		}
+		if !tagged {
+			row[column] = rv.Field(i).Interface()
+			continue
+		}
+
+		index, err := parseStructTag(tag)
+		if err != nil {
+			return nil, fmt.Errorf("%w: field %s", err, field.Name)
+		}
+		plaintext, err := c.structPlaintext(rv.Field(i))
+		if err != nil {
+			return nil, fmt.Errorf("encryptedcol: field %s: %w", field.Name, err)
+		}
+		sealed = true
+
+		if plaintext == nil {
+			row[column+"_encrypted"] = []byte(nil) // NULL preservation
+			if index {
+				row[column+"_idx"] = []byte(nil)
+			}
+			continue
+		}
+		row[column+"_encrypted"] = c.sealWithKeyID(r, nil, r.defaultID, plaintext, nil)
+		if index {
+			row[column+"_idx"] = c.computeHMAC(r, r.defaultID, plaintext)
+		}
+	}
+
+	if sealed {
+		row["key_id"] = r.defaultID
+	}
+	return row, nil
+}
+
+// structColumnName returns the column name for a field: its `db` tag name,
+// or the field name. Returns "" for fields tagged `db:"-"`.
+func structColumnName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("db"), ",")
+	switch name {
+	case "-":
+		return ""
+	case "":
+		return field.Name
+	default:
+		return name
+	}
+}
+
+// parseStructTag parses an encryptedcol tag ("seal" or "seal,index") and
+// reports whether the field is indexed.
+func parseStructTag(tag string) (index bool, err error) {
+	switch tag {
+	case "seal":
+		return false, nil
+	case "seal,index":
+		return true, nil
+	default:
+		return false, ErrInvalidStructTag
+	}
+}
+
+// structPlaintext encodes a sealed field's value as the typed helpers do.
+// Returns nil for NULL (a nil pointer, slice, map or interface, or "" with
+// WithEmptyStringAsNull).
+func (c *Cipher) structPlaintext(v reflect.Value) ([]byte, error) {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice:
+		if v.IsNil() {
+			return nil, nil
+		}
+	}
+	if v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		return c.structPlaintext(v.Elem())
+	}
+
+	switch {
+	case v.Type() == timeType:
+		return encodeTime(v.Interface().(time.Time)), nil
+	case v.Type() == uuidType:
+		u := v.Interface().([uuidSize]byte)
+		return u[:], nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		if c.config.emptyStringAsNull && v.Len() == 0 {
+			return nil, nil
+		}
+		return []byte(v.String()), nil
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return v.Bytes(), nil
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		buf := make([]byte, 8)
+		binary.BigEndian.PutUint64(buf, uint64(v.Int()))
+		return buf, nil
+	case reflect.Bool:
+		if v.Bool() {
+			return []byte{0x01}, nil
+		}
+		return []byte{0x00}, nil
+	}
+	return CanonicalJSON(v.Interface())
+}
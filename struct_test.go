@@ -0,0 +1,182 @@
+package encryptedcol
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type structTestUser struct {
+	ID        int64
+	Email     string         `db:"email" encryptedcol:"seal,index"`
+	Notes     *string        `db:"notes" encryptedcol:"seal"`
+	Age       int            `db:"age" encryptedcol:"seal"`
+	Verified  bool           `db:"verified" encryptedcol:"seal"`
+	Born      time.Time      `db:"born" encryptedcol:"seal"`
+	Ref       [16]byte       `db:"ref" encryptedcol:"seal,index"`
+	Avatar    []byte         `db:"avatar" encryptedcol:"seal"`
+	Prefs     map[string]int `db:"prefs" encryptedcol:"seal"`
+	CreatedAt time.Time      `db:"created_at"`
+	Skipped   string         `db:"-" encryptedcol:"seal"`
+	internal  string
+}
+
+func TestSealStruct_RoundTrip(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	notes := "likes cats"
+	born := time.Date(1990, 5, 17, 8, 30, 0, 0, time.UTC)
+	created := time.Now()
+	user := structTestUser{
+		ID:        42,
+		Email:     "alice@example.com",
+		Notes:     &notes,
+		Age:       36,
+		Verified:  true,
+		Born:      born,
+		Ref:       [16]byte{1, 2, 3},
+		Avatar:    []byte{0xFF, 0xD8},
+		Prefs:     map[string]int{"b": 2, "a": 1},
+		CreatedAt: created,
+		Skipped:   "not in the row",
+		internal:  "not in the row",
+	}
+
+	row, err := cipher.SealStruct(&user)
+	require.NoError(t, err)
+
+	require.ElementsMatch(t, []string{
+		"ID", "email_encrypted", "email_idx", "notes_encrypted", "age_encrypted",
+		"verified_encrypted", "born_encrypted", "ref_encrypted", "ref_idx",
+		"avatar_encrypted", "prefs_encrypted", "created_at", "key_id",
+	}, mapKeys(row))
+
+	// Untagged fields pass through
+	require.Equal(t, int64(42), row["ID"])
+	require.Equal(t, created, row["created_at"])
+	require.Equal(t, "v1", row["key_id"])
+
+	// Sealed fields open with the matching typed helper
+	email, err := cipher.OpenString(row["email_encrypted"].([]byte))
+	require.NoError(t, err)
+	require.Equal(t, "alice@example.com", email)
+	require.Equal(t, cipher.BlindIndexString("alice@example.com"), row["email_idx"])
+
+	s, err := cipher.OpenString(row["notes_encrypted"].([]byte))
+	require.NoError(t, err)
+	require.Equal(t, notes, s)
+
+	n, err := cipher.OpenInt64(row["age_encrypted"].([]byte))
+	require.NoError(t, err)
+	require.Equal(t, int64(36), n)
+
+	b, err := cipher.OpenBool(row["verified_encrypted"].([]byte))
+	require.NoError(t, err)
+	require.True(t, b)
+
+	tm, err := cipher.OpenTime(row["born_encrypted"].([]byte))
+	require.NoError(t, err)
+	require.True(t, born.Equal(tm))
+
+	u, err := cipher.OpenUUID(row["ref_encrypted"].([]byte))
+	require.NoError(t, err)
+	require.Equal(t, user.Ref, u)
+	require.Equal(t, cipher.SealUUIDIndexed(user.Ref).BlindIndex, row["ref_idx"])
+
+	avatar, err := cipher.Open(row["avatar_encrypted"].([]byte))
+	require.NoError(t, err)
+	require.Equal(t, user.Avatar, avatar)
+
+	prefs, err := OpenJSON[map[string]int](cipher, row["prefs_encrypted"].([]byte))
+	require.NoError(t, err)
+	require.Equal(t, user.Prefs, prefs)
+}
+
+func TestSealStruct_Null(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")), WithEmptyStringAsNull())
+
+	row, err := cipher.SealStruct(structTestUser{})
+	require.NoError(t, err)
+
+	// nil pointer, slice, map and (with WithEmptyStringAsNull) "" are NULL
+	for _, col := range []string{"email_encrypted", "email_idx", "notes_encrypted", "avatar_encrypted", "prefs_encrypted"} {
+		require.Nil(t, row[col], col)
+		require.Contains(t, row, col)
+	}
+	// Zero values of other types are still encrypted
+	require.NotNil(t, row["age_encrypted"])
+	require.Equal(t, "v1", row["key_id"])
+}
+
+func TestSealStruct_ColumnNames(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	type plain struct {
+		Name  string `db:"name,omitempty"`
+		Other string
+	}
+	row, err := cipher.SealStruct(plain{Name: "a", Other: "b"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"name": "a", "Other": "b"}, row)
+
+	type noDBTag struct {
+		SSN string `encryptedcol:"seal,index"`
+	}
+	row, err = cipher.SealStruct(noDBTag{SSN: "123"})
+	require.NoError(t, err)
+	require.Contains(t, row, "SSN_encrypted")
+	require.Contains(t, row, "SSN_idx")
+}
+
+func TestSealStruct_Errors(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	type badTag struct {
+		Email string `encryptedcol:"index"`
+	}
+	type unexported struct {
+		email string `encryptedcol:"seal"`
+	}
+	type badJSON struct {
+		Fn func() `encryptedcol:"seal"`
+	}
+
+	tests := []struct {
+		name string
+		v    any
+		err  error
+	}{
+		{"nil", nil, ErrInvalidStruct},
+		{"nil pointer", (*structTestUser)(nil), ErrInvalidStruct},
+		{"not a struct", "alice", ErrInvalidStruct},
+		{"bad tag", badTag{}, ErrInvalidStructTag},
+		{"unexported tagged field", unexported{email: "a"}, ErrInvalidStructTag},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := cipher.SealStruct(tt.v)
+			require.ErrorIs(t, err, tt.err)
+		})
+	}
+
+	t.Run("unencodable field", func(t *testing.T) {
+		_, err := cipher.SealStruct(badJSON{Fn: func() {}})
+		require.Error(t, err)
+	})
+
+	t.Run("closed", func(t *testing.T) {
+		closed, _ := New(WithKey("v1", testKey("v1")))
+		closed.Close()
+		_, err := closed.SealStruct(structTestUser{})
+		require.ErrorIs(t, err, ErrCipherClosed)
+	})
+}
+
+func mapKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
@@ -0,0 +1,50 @@
+package encryptedcol
+
+// ForTenant returns a Cipher whose keys are derived from c's keys and
+// tenantID, so each tenant's data is encrypted and indexed under its own keys.
+// A leaked tenant Cipher cannot decrypt other tenants' data, and equal values
+// get unrelated blind indexes in different tenants.
+//
+// Derivation contract: for each key, the tenant master key is
+//
+//	HKDF-SHA256(HKDF-SHA256(masterKey, info="encryptedcol-tenant"), info="encryptedcol-tenant\x00"+tenantID)
+//
+// and all subkeys are derived from it as in New. With WithHKDFSalt, every HKDF
+// step uses the salt. tenantID is used byte for byte, so it must be a stable
+// identifier (such as a primary key), never a display name; changing it makes
+// the tenant's data unreadable.
+//
+// Ciphertext keeps the original key IDs and does not record the tenant:
+// opening with the wrong tenant fails with ErrDecryptionFailed. The tenant
+// Cipher shares c's options but takes a snapshot of its keys; it is not
+// affected by later ReloadKeys or Close on c, and should be closed on its own.
+//
+// Panics if tenantID is empty or c is closed.
+func (c *Cipher) ForTenant(tenantID string) *Cipher {
+	if tenantID == "" {
+		panic("encryptedcol: empty tenant ID")
+	}
+	r := c.mustAcquire()
+	defer r.release()
+
+	tenant := &keyring{
+		keys:      make(map[string]*derivedKeys, len(r.keys)),
+		defaultID: r.defaultID,
+	}
+	var masterKey [masterKeySize]byte
+	defer zeroKey(&masterKey)
+	for keyID, dk := range r.keys {
+		err := hkdfDerive(dk.tenant[:], c.config.hkdfSalt, infoTenant+"\x00"+tenantID, masterKey[:])
+		if err == nil {
+			tenant.keys[keyID], err = deriveKeys(masterKey[:], c.config.hkdfSalt)
+		}
+		if err != nil {
+			tenant.retire()
+			panic("encryptedcol: tenant key derivation failed: " + err.Error())
+		}
+	}
+
+	tc := &Cipher{config: c.config}
+	tc.ring.Store(tenant)
+	return tc
+}
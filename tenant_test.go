@@ -0,0 +1,102 @@
+package encryptedcol
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestForTenant_RoundTrip(t *testing.T) {
+	cipher, err := New(WithKey("v1", testKey("v1")))
+	require.NoError(t, err)
+
+	acme := cipher.ForTenant("acme")
+	ct := acme.SealString("hello")
+
+	pt, err := cipher.ForTenant("acme").OpenString(ct)
+	require.NoError(t, err)
+	require.Equal(t, "hello", pt)
+
+	// Key ID is unchanged; the tenant is not recorded
+	info, err := acme.Peek(ct)
+	require.NoError(t, err)
+	require.Equal(t, "v1", info.KeyID)
+}
+
+func TestForTenant_Isolation(t *testing.T) {
+	cipher, err := New(WithKey("v1", testKey("v1")))
+	require.NoError(t, err)
+	acme, globex := cipher.ForTenant("acme"), cipher.ForTenant("globex")
+
+	ct := acme.SealString("hello")
+	_, err = globex.OpenString(ct)
+	require.ErrorIs(t, err, ErrDecryptionFailed)
+	_, err = cipher.OpenString(ct)
+	require.ErrorIs(t, err, ErrDecryptionFailed)
+
+	require.NotEqual(t, acme.BlindIndex([]byte("hello")), globex.BlindIndex([]byte("hello")))
+	require.NotEqual(t, acme.BlindIndex([]byte("hello")), cipher.BlindIndex([]byte("hello")))
+}
+
+func TestForTenant_MultipleKeys(t *testing.T) {
+	old, err := New(WithKey("v1", testKey("v1")))
+	require.NoError(t, err)
+	ct := old.ForTenant("acme").SealString("legacy")
+
+	cipher, err := New(
+		WithKey("v1", testKey("v1")),
+		WithKey("v2", testKey("v2")),
+		WithDefaultKeyID("v2"),
+	)
+	require.NoError(t, err)
+	acme := cipher.ForTenant("acme")
+
+	pt, err := acme.OpenString(ct)
+	require.NoError(t, err)
+	require.Equal(t, "legacy", pt)
+
+	info, err := acme.Peek(acme.SealString("new"))
+	require.NoError(t, err)
+	require.Equal(t, "v2", info.KeyID)
+}
+
+func TestForTenant_IndependentLifetime(t *testing.T) {
+	cipher, err := New(WithKey("v1", testKey("v1")))
+	require.NoError(t, err)
+	acme := cipher.ForTenant("acme")
+	ct := acme.SealString("hello")
+
+	cipher.Close()
+	pt, err := acme.OpenString(ct)
+	require.NoError(t, err)
+	require.Equal(t, "hello", pt)
+
+	acme.Close()
+	_, err = acme.OpenString(ct)
+	require.ErrorIs(t, err, ErrCipherClosed)
+}
+
+func TestForTenant_Panics(t *testing.T) {
+	cipher, err := New(WithKey("v1", testKey("v1")))
+	require.NoError(t, err)
+	require.Panics(t, func() { cipher.ForTenant("") })
+
+	cipher.Close()
+	require.Panics(t, func() { cipher.ForTenant("acme") })
+}
+
+func TestForTenant_DerivationContract(t *testing.T) {
+	cipher, err := New(WithKey("v1", testKey("v1")))
+	require.NoError(t, err)
+
+	// Recompute the documented derivation from the master key
+	var root, tenantMaster [32]byte
+	require.NoError(t, hkdfDerive(testKey("v1"), nil, "encryptedcol-tenant", root[:]))
+	require.NoError(t, hkdfDerive(root[:], nil, "encryptedcol-tenant\x00acme", tenantMaster[:]))
+	want, err := deriveKeys(tenantMaster[:], nil)
+	require.NoError(t, err)
+
+	got := cipher.ForTenant("acme").ring.Load().keys["v1"]
+	require.Equal(t, want.encryption, got.encryption)
+	require.Equal(t, want.hmac, got.hmac)
+}
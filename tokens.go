@@ -0,0 +1,185 @@
+package encryptedcol
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Tokenizer splits an input string into tokens for tokenized blind indexes.
+// Each token is indexed separately, enabling prefix or substring search.
+//
+// PRIVACY TRADEOFF: Token indexes leak far more structure than a single
+// exact-match index. An observer of the token column learns approximate
+// value lengths and which rows share prefixes or substrings, and short
+// tokens are easy to brute-force. Only use tokenized indexes where prefix
+// or substring search is a hard requirement.
+type Tokenizer func(string) []string
+
+// tokenIndexPrefix domain-separates token blind indexes from exact-match ones,
+// so a value's last prefix token is not its BlindIndex and the token column
+// cannot be joined against {column}_idx.
+const tokenIndexPrefix = "encryptedcol-token-index\x00"
+
+// minTokenLength is the shortest token produced by the built-in tokenizers.
+// Single-character tokens would reveal per-character frequency.
+const minTokenLength = 2
+
+// TokenizePrefix generates every prefix of length 2..N (in runes).
+// Use for "starts with" search and autocomplete.
+//
+// Example: "alice" -> ["al", "ali", "alic", "alice"]
+var TokenizePrefix Tokenizer = func(s string) []string {
+	runes := []rune(s)
+	if len(runes) < minTokenLength {
+		return nil
+	}
+	tokens := make([]string, 0, len(runes)-minTokenLength+1)
+	for n := minTokenLength; n <= len(runes); n++ {
+		tokens = append(tokens, string(runes[:n]))
+	}
+	return tokens
+}
+
+// TokenizeTrigram generates the distinct 3-rune substrings of the input.
+// Use for "contains" search. Inputs shorter than 3 runes produce no tokens.
+//
+// Example: "alice" -> ["ali", "lic", "ice"]
+var TokenizeTrigram Tokenizer = func(s string) []string {
+	runes := []rune(s)
+	if len(runes) < 3 {
+		return nil
+	}
+	seen := make(map[string]bool, len(runes)-2)
+	tokens := make([]string, 0, len(runes)-2)
+	for i := 0; i+3 <= len(runes); i++ {
+		tok := string(runes[i : i+3])
+		if seen[tok] {
+			continue
+		}
+		seen[tok] = true
+		tokens = append(tokens, tok)
+	}
+	return tokens
+}
+
+// BlindIndexTokens computes a blind index for each token of plaintext using the default key.
+// Store the result in a {column}_idx_tokens BYTEA[] column.
+// Returns nil if plaintext is nil (NULL preservation) or produces no tokens.
+//
+// See Tokenizer for the privacy tradeoff of token indexes.
+func (c *Cipher) BlindIndexTokens(plaintext []byte, tokenizer Tokenizer) [][]byte {
+	r := c.mustAcquire()
+	defer r.release()
+	if plaintext == nil {
+		return nil
+	}
+	return c.blindIndexTokensWithKey(r, r.defaultID, string(plaintext), tokenizer)
+}
+
+// blindIndexTokensWithKey computes token blind indexes with a key from r.
+func (c *Cipher) blindIndexTokensWithKey(r *keyring, keyID string, s string, tokenizer Tokenizer) [][]byte {
+	tokens := tokenizer(s)
+	if len(tokens) == 0 {
+		return nil
+	}
+	indexes := make([][]byte, len(tokens))
+	var input []byte
+	for i, tok := range tokens {
+		input = append(append(input[:0], tokenIndexPrefix...), tok...)
+		indexes[i] = c.computeHMAC(r, keyID, input)
+	}
+	return indexes
+}
+
+// SearchConditionTokens generates a SQL WHERE clause for tokenized blind index
+// search across all active key versions.
+//
+// The search value is tokenized with the same tokenizer used on write, and a
+// row matches when its token array contains every search token:
+//
+//	(key_id = $1 AND {column}_idx_tokens @> $2) OR (key_id = $3 AND {column}_idx_tokens @> $4)
+//
+// Each token argument is a [][]byte; drivers such as pgx bind it as BYTEA[].
+// With lib/pq, wrap it with pq.Array. The @> array operator is PostgreSQL-specific.
+//
+// Containment (@>) is deliberate; do not change it to overlap (&&). Overlap
+// would match rows sharing any single token: any 2-character prefix or any
+// one trigram, so "alice" would find "alfred" and every value containing "ice".
+//
+// If the search value produces no tokens (e.g. shorter than the minimum
+// token length), the condition is "FALSE".
+//
+// Example:
+//
+//	cond := cipher.SearchConditionTokens("email", []byte("ali"), 1, encryptedcol.TokenizePrefix)
+//	rows, _ := db.Query("SELECT * FROM users WHERE "+cond.SQL, cond.Args...)
+func (c *Cipher) SearchConditionTokens(column string, plaintext []byte, paramOffset int, tokenizer Tokenizer) *SearchCondition {
+	return c.SearchConditionTokensAll(column, [][]byte{plaintext}, paramOffset, tokenizer)
+}
+
+// SearchConditionTokensAll is SearchConditionTokens for several search terms,
+// matching rows whose token array contains the tokens of every term. Each term
+// is tokenized on its own, so no tokens spanning two terms are required:
+// searching "ali" and "example" with TokenizeTrigram finds
+// "alice@example.com", which the single term "ali example" would not.
+//
+// The SQL has the same shape as SearchConditionTokens, with one array argument
+// per key version holding the distinct tokens of all terms. If there are no
+// terms, or any term is nil or produces no tokens, the condition is "FALSE".
+//
+// Example:
+//
+//	cond := cipher.SearchConditionTokensAll("email", [][]byte{[]byte("ali"), []byte("example")}, 1, encryptedcol.TokenizeTrigram)
+//	rows, _ := db.Query("SELECT * FROM users WHERE "+cond.SQL, cond.Args...)
+func (c *Cipher) SearchConditionTokensAll(column string, terms [][]byte, paramOffset int, tokenizer Tokenizer) *SearchCondition {
+	c.validateSearchParams(column, paramOffset)
+
+	r := c.mustAcquire()
+	defer r.release()
+
+	searchable := len(terms) > 0
+	for _, term := range terms {
+		if term == nil || len(tokenizer(string(term))) == 0 {
+			searchable = false
+			break
+		}
+	}
+	if !searchable {
+		return &SearchCondition{
+			SQL:  "FALSE", // Nothing to match on
+			Args: nil,
+		}
+	}
+
+	ids := sortedMapKeys(r.keys)
+
+	c.validateParamLimit(paramOffset, len(ids))
+
+	parts := make([]string, 0, len(ids))
+	args := make([]interface{}, 0, len(ids)*2)
+
+	for _, keyID := range ids {
+		var tokens [][]byte
+		seen := make(map[string]bool)
+		for _, term := range terms {
+			for _, tok := range c.blindIndexTokensWithKey(r, keyID, string(term), tokenizer) {
+				if !seen[string(tok)] {
+					seen[string(tok)] = true
+					tokens = append(tokens, tok)
+				}
+			}
+		}
+
+		part, keyArgs, n := c.keyCondition(keyID, args, paramOffset, func(n int) string {
+			return fmt.Sprintf("%s_idx_tokens @> %s", column, c.placeholder(n))
+		})
+		parts = append(parts, part)
+		args = append(keyArgs, tokens)
+		paramOffset = n + 1
+	}
+
+	return &SearchCondition{
+		SQL:  strings.Join(parts, " OR "),
+		Args: args,
+	}
+}
@@ -0,0 +1,153 @@
+package encryptedcol
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenizePrefix(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{"basic", "alice", []string{"al", "ali", "alic", "alice"}},
+		{"two chars", "ab", []string{"ab"}},
+		{"too short", "a", nil},
+		{"empty", "", nil},
+		{"unicode", "日本語", []string{"日本", "日本語"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, TokenizePrefix(tt.input))
+		})
+	}
+}
+
+func TestTokenizeTrigram(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{"basic", "alice", []string{"ali", "lic", "ice"}},
+		{"exact", "abc", []string{"abc"}},
+		{"duplicates removed", "aaaa", []string{"aaa"}},
+		{"too short", "ab", nil},
+		{"unicode", "日本語です", []string{"日本語", "本語で", "語です"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, TokenizeTrigram(tt.input))
+		})
+	}
+}
+
+func TestBlindIndexTokens(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	tokens := cipher.BlindIndexTokens([]byte("alice"), TokenizePrefix)
+	require.Len(t, tokens, 4)
+	require.Equal(t, cipher.BlindIndexString(tokenIndexPrefix+"al"), tokens[0])
+	require.Equal(t, cipher.BlindIndexString(tokenIndexPrefix+"alice"), tokens[3])
+
+	// Tokens are domain-separated from exact-match indexes
+	for _, s := range []string{"al", "ali", "alice", "alice@example.com"} {
+		for _, tokenizer := range []Tokenizer{TokenizePrefix, TokenizeTrigram} {
+			require.NotContains(t, cipher.BlindIndexTokens([]byte(s), tokenizer), cipher.BlindIndexString(s), s)
+		}
+	}
+
+	require.Nil(t, cipher.BlindIndexTokens(nil, TokenizePrefix))
+	require.Nil(t, cipher.BlindIndexTokens([]byte("a"), TokenizePrefix))
+}
+
+func TestSearchConditionTokens(t *testing.T) {
+	cipher, _ := New(
+		WithKey("v1", testKey("v1")),
+		WithKey("v2", testKey("v2")),
+	)
+
+	cond := cipher.SearchConditionTokens("email", []byte("ali"), 3, TokenizePrefix)
+
+	require.Equal(t, "(key_id = $3 AND email_idx_tokens @> $4) OR (key_id = $5 AND email_idx_tokens @> $6)", cond.SQL)
+	require.Len(t, cond.Args, 4)
+	require.Equal(t, "v1", cond.Args[0])
+	require.Equal(t, "v2", cond.Args[2])
+
+	// Search tokens must be a subset of the stored tokens for a prefix match
+	stored := cipher.BlindIndexTokens([]byte("alice"), TokenizePrefix)
+	search := cond.Args[1].([][]byte)
+	require.Len(t, search, 2)
+	for _, tok := range search {
+		require.Contains(t, stored, tok)
+	}
+}
+
+func TestSearchConditionTokens_Trigram(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	stored := cipher.BlindIndexTokens([]byte("alice@example.com"), TokenizeTrigram)
+	cond := cipher.SearchConditionTokens("email", []byte("example"), 1, TokenizeTrigram)
+
+	for _, tok := range cond.Args[1].([][]byte) {
+		require.Contains(t, stored, tok)
+	}
+}
+
+func TestSearchConditionTokensAll(t *testing.T) {
+	cipher, _ := New(
+		WithKey("v1", testKey("v1")),
+		WithKey("v2", testKey("v2")),
+	)
+	stored := cipher.BlindIndexTokens([]byte("alice@example.com"), TokenizeTrigram)
+
+	cond := cipher.SearchConditionTokensAll("email", [][]byte{[]byte("ali"), []byte("example"), []byte("exam")}, 1, TokenizeTrigram)
+	require.Equal(t, "(key_id = $1 AND email_idx_tokens @> $2) OR (key_id = $3 AND email_idx_tokens @> $4)", cond.SQL)
+
+	// 1 + 5 distinct trigrams ("exam" adds none), all stored
+	search := cond.Args[1].([][]byte)
+	require.Len(t, search, 6)
+	for _, tok := range search {
+		require.Contains(t, stored, tok)
+	}
+
+	// A joined search would need cross-term trigrams the row doesn't have
+	joined := cipher.SearchConditionTokens("email", []byte("ali example"), 1, TokenizeTrigram)
+	require.NotSubset(t, stored, joined.Args[1].([][]byte))
+
+	// One term matches SearchConditionTokens exactly
+	require.Equal(t,
+		cipher.SearchConditionTokens("email", []byte("ali"), 1, TokenizePrefix),
+		cipher.SearchConditionTokensAll("email", [][]byte{[]byte("ali")}, 1, TokenizePrefix))
+
+	// Any unsearchable term, or none at all, matches nothing
+	for _, terms := range [][][]byte{nil, {[]byte("ali"), []byte("a")}, {[]byte("ali"), nil}} {
+		require.Equal(t, "FALSE", cipher.SearchConditionTokensAll("email", terms, 1, TokenizeTrigram).SQL)
+	}
+}
+
+func TestSearchConditionTokens_NoTokens(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	cond := cipher.SearchConditionTokens("email", []byte("a"), 1, TokenizePrefix)
+	require.Equal(t, "FALSE", cond.SQL)
+	require.Nil(t, cond.Args)
+
+	cond = cipher.SearchConditionTokens("email", nil, 1, TokenizePrefix)
+	require.Equal(t, "FALSE", cond.SQL)
+}
+
+func TestSearchConditionTokens_InvalidInput(t *testing.T) {
+	cipher, _ := New(WithKey("v1", testKey("v1")))
+
+	require.Panics(t, func() {
+		cipher.SearchConditionTokens("email; DROP TABLE", []byte("ali"), 1, TokenizePrefix)
+	})
+	require.Panics(t, func() {
+		cipher.SearchConditionTokens("email", []byte("ali"), 0, TokenizePrefix)
+	})
+}